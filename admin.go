@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// AdminHandler returns an http.Handler to inspect and purge the cache, meant to be mounted
+// with http.StripPrefix. auth wraps the handler and must reject unauthorized requests,
+// AdminHandler panics if it is nil. The routes are:
+//
+//	GET    /keys/{key}       the ItemInfo of the item as JSON, 404 if there is none
+//	DELETE /keys/{key}       deletes the item
+//	DELETE /keys?prefix={p}  deletes the items whose keys start with p, and returns how many as JSON
+//	POST   /flush            deletes all items
+//
+// Values are never returned.
+func (g *GenericCache[T]) AdminHandler(auth func(http.Handler) http.Handler) http.Handler {
+	if auth == nil {
+		panic("cache: AdminHandler requires an auth middleware")
+	}
+	return auth(http.HandlerFunc(g.serveAdmin))
+}
+
+func (g *GenericCache[T]) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	path := "/" + strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case path == "/flush":
+		if r.Method != http.MethodPost {
+			notAllowed(w, http.MethodPost)
+			return
+		}
+		g.Flush()
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/keys":
+		if r.Method != http.MethodDelete {
+			notAllowed(w, http.MethodDelete)
+			return
+		}
+		prefix := r.URL.Query().Get("prefix")
+		// an empty prefix would flush the cache, which POST /flush is for.
+		if prefix == "" {
+			http.Error(w, "cache: missing prefix parameter", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, struct{ Deleted int }{g.DeletePrefix(prefix)})
+	case strings.HasPrefix(path, "/keys/") && len(path) > len("/keys/"):
+		key := strings.TrimPrefix(path, "/keys/")
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			info, ok := g.Inspect(key)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			writeJSON(w, info)
+		case http.MethodDelete:
+			g.Delete(key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			notAllowed(w, http.MethodGet, http.MethodHead, http.MethodDelete)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func notAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
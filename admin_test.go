@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	h := c.AdminHandler(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "secret" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	do := func(method, target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	c.Set("user/1", "foo")
+	c.Set("user/2", "bar")
+	c.Set("post/1", "baz")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/flush", nil))
+	if w.Code != http.StatusForbidden || c.ItemCount() != 3 {
+		t.Errorf("expected unauthorized requests to be rejected, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/keys/user/1"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"Created"`) {
+		t.Errorf("expected the metadata of user/1, got %d %s", w.Code, w.Body)
+	} else if strings.Contains(w.Body.String(), "foo") {
+		t.Errorf("expected the value not to be returned, got %s", w.Body)
+	}
+	if w := do(http.MethodGet, "/keys/user/3"); w.Code != http.StatusNotFound {
+		t.Errorf("expected a missing key to be 404, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/keys/post/1"); w.Code != http.StatusNoContent || c.ItemCount() != 2 {
+		t.Errorf("expected post/1 to be deleted, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/keys?prefix=user/"); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"Deleted":2}` {
+		t.Errorf("expected 2 keys to be deleted, got %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, "/keys"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected a missing prefix to be rejected, got %d", w.Code)
+	}
+	c.Set("foo", "bar")
+	if w := do(http.MethodGet, "/flush"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected GET /flush not to be allowed, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/flush"); w.Code != http.StatusNoContent || c.ItemCount() != 0 {
+		t.Errorf("expected the cache to be flushed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Backend selects how a cache created by New or NewKeyed stores its items, see WithBackend.
+type Backend int
+
+const (
+	// MutexBackend stores the items in a single map under a sync.RWMutex. It is the default.
+	MutexBackend Backend = iota
+	// StripedBackend spreads the items over 4 maps per GOMAXPROCS, each under its own lock, like
+	// NewSharded, so that reads and writes on many-core machines do not all wait for the same lock.
+	// The WithMaxEntries and WithMaxCost bounds are divided between the stripes, whose number is
+	// lowered until the bounds divide evenly, so that the cache never holds more than its bounds.
+	// It only stripes string keys, the caches of other key types keep a single map and can be
+	// sharded with NewShardedKeyed instead.
+	StripedBackend
+)
+
+// String implements fmt.Stringer.
+func (b Backend) String() string {
+	switch b {
+	case MutexBackend:
+		return "MutexBackend"
+	case StripedBackend:
+		return "StripedBackend"
+	default:
+		return fmt.Sprintf("Backend(%d)", int(b))
+	}
+}
+
+// WithBackend selects how the items are stored, see Backend. It is ignored by the sharded
+// constructors, whose shards already have their own locks.
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+// stripes returns the number of shards and the hash of a cache created with the given
+// number of shards, hash and bounds for the backend.
+func stripes[K comparable](backend Backend, shards int, hash func(K) uint64, maxEntries int, maxCost int64) (int, func(K) uint64) {
+	switch backend {
+	case MutexBackend:
+		return shards, hash
+	case StripedBackend:
+		if shards > 1 {
+			return shards, hash
+		}
+		stringHash, ok := any(hashString).(func(K) uint64)
+		if !ok {
+			return shards, hash
+		}
+		n := 4 * runtime.GOMAXPROCS(0)
+		for n > 1 && (maxEntries > 0 && maxEntries%n != 0 || maxCost > 0 && maxCost%int64(n) != 0) {
+			n--
+		}
+		return n, stringHash
+	default:
+		panic(fmt.Sprintf("cache: unknown backend %v", backend))
+	}
+}
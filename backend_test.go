@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStripedBackend(t *testing.T) {
+	c := New[int](time.Minute, 0, WithBackend(StripedBackend))
+	defer c.Close()
+	if n, want := len(c.shards), 4*runtime.GOMAXPROCS(0); n != want {
+		t.Errorf("expected %d stripes, got %d", want, n)
+	}
+	for i, key := range []string{"foo", "bar", "baz"} {
+		c.Set(key, i)
+	}
+	if v, ok := c.Get("bar"); !ok || v != 1 {
+		t.Errorf("expected bar to be 1, got %v", v)
+	}
+	if n := c.Len(); n != 3 {
+		t.Errorf("expected 3 items, got %d", n)
+	}
+
+	sharded := NewSharded[int](3, time.Minute, 0, WithBackend(StripedBackend))
+	defer sharded.Close()
+	if n := len(sharded.shards); n != 3 {
+		t.Errorf("expected the shards of NewSharded to be kept, got %d", n)
+	}
+}
+
+func TestStripedBackendKeys(t *testing.T) {
+	c := NewKeyed[int, int](time.Minute, 0, WithBackend(StripedBackend))
+	defer c.Close()
+	if n := len(c.shards); n != 1 {
+		t.Errorf("expected int keys to be kept in a single map, got %d stripes", n)
+	}
+	c.Set(1, 1)
+	if v, ok := c.Get(1); !ok || v != 1 {
+		t.Errorf("expected 1 to be 1, got %v", v)
+	}
+}
+
+func TestStripedBackendBounds(t *testing.T) {
+	for _, maxEntries := range []int{1, 7, 10, 64, 100} {
+		c := New[int](NoExpiration, 0, WithBackend(StripedBackend), WithMaxEntries(maxEntries))
+		for i := 0; i < 10*maxEntries; i++ {
+			c.Set(strconv.Itoa(i), i)
+		}
+		if n := c.ItemCount(); n > maxEntries {
+			t.Errorf("expected at most %d items, got %d", maxEntries, n)
+		}
+		c.Close()
+	}
+	c := New[int](NoExpiration, 0, WithBackend(StripedBackend), WithMaxCost(10))
+	defer c.Close()
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	if n := c.ItemCount(); n > 10 {
+		t.Errorf("expected at most 10 items, got %d", n)
+	}
+}
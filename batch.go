@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// GetMany returns the values of the items associated with the given keys.
+// Keys without an item are left out of the result.
+// Every shard is locked once for all of its keys.
+func (c *cache[K, V]) GetMany(keys []K) map[K]V {
+	normalized := c.normalizeAll(keys)
+	found := c.getMany(normalized)
+	if c.transform == nil {
+		return found
+	}
+	result := make(map[K]V, len(found))
+	for i, key := range keys {
+		if v, ok := found[normalized[i]]; ok {
+			result[key] = v
+		}
+	}
+	return result
+}
+
+// getMany is GetMany without transforming the keys.
+func (c *cache[K, V]) getMany(keys []K) map[K]V {
+	result := make(map[K]V, len(keys))
+	now := c.now()
+	if c.hot != nil {
+		for _, key := range keys {
+			c.hot.read(key, now)
+		}
+	}
+	for i, part := range c.partition(keys) {
+		if len(part) == 0 {
+			continue
+		}
+		s := c.shards[i]
+		s.rlock()
+		for _, key := range part {
+			e, ok := s.get(key, now)
+			c.stats.hit(ok)
+			if ok {
+				result[key] = e.value
+			}
+		}
+		s.runlock()
+	}
+	if c.copier != nil {
+		for key, v := range result {
+			result[key] = c.copier(v)
+		}
+	}
+	return result
+}
+
+// SetMany adds the items to the cache with the given expiration, replacing any existing items.
+// Every shard is locked once for all of its items.
+func (c *cache[K, V]) SetMany(items map[K]V, expireIn time.Duration) {
+	if c.transform != nil {
+		normalized := make(map[K]V, len(items))
+		for key, v := range items {
+			normalized[c.transform(key)] = v
+		}
+		items = normalized
+	}
+	c.setMany(items, expireIn)
+	c.changedMany(items)
+}
+
+// changedMany records that the keys of items were written, see changed.
+func (c *cache[K, V]) changedMany(items map[K]V) {
+	if c.sub != nil || c.wal != nil {
+		c.changed(mapKeys(items)...)
+	}
+}
+
+// setMany is SetMany without recording the change.
+func (c *cache[K, V]) setMany(items map[K]V, expireIn time.Duration) {
+	var evicted []eviction[K, V]
+	for i, keys := range c.partition(mapKeys(items)) {
+		if len(keys) == 0 {
+			continue
+		}
+		s := c.shards[i]
+		s.mu.Lock()
+		for _, key := range keys {
+			// every item gets its own expiration, so that WithTTLJitter spreads the batch.
+			value := items[key]
+			evicted = s.set(key, value, c.expirationOf(value, expireIn), evicted)
+		}
+		s.mu.Unlock()
+	}
+	atomic.AddUint64(&c.stats.sets, uint64(len(items)))
+	c.report(evicted)
+}
+
+// GetOrLoadMany returns the values of the items associated with the given keys, like GetMany,
+// calling loader once with the keys without an item and storing the values it returns with
+// the default expiration. Keys left out by loader are left out of the result as well.
+// If loader fails, the values found in the cache are returned along with its error.
+// Unlike GetOrLoad, concurrent calls missing the same keys each call their loader.
+func (c *cache[K, V]) GetOrLoadMany(keys []K, loader func(missing []K) (map[K]V, error)) (map[K]V, error) {
+	if err := c.isClosed(); err != nil {
+		return nil, err
+	}
+	result := c.GetMany(keys)
+	var missing []K
+	seen := make(map[K]struct{}, len(keys)-len(result))
+	for _, key := range keys {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	loaded, err := loader(missing)
+	if err != nil {
+		return result, err
+	}
+	items := make(map[K]V, len(loaded))
+	for _, key := range missing {
+		if v, ok := loaded[key]; ok {
+			items[c.normalize(key)] = v
+			result[key] = c.clone(v)
+		}
+	}
+	c.setMany(items, DefaultExpiration)
+	c.changedMany(items)
+	return result, nil
+}
+
+// DeleteMany removes the provided keys from the cache.
+// Every shard is locked once for all of its keys.
+func (c *cache[K, V]) DeleteMany(keys []K) {
+	keys = c.normalizeAll(keys)
+	var evicted []eviction[K, V]
+	for i, part := range c.partition(keys) {
+		if len(part) == 0 {
+			continue
+		}
+		s := c.shards[i]
+		s.mu.Lock()
+		for _, key := range part {
+			if e, ok := s.items[key]; ok {
+				evicted = s.evict(key, e, EvictionReasonDeleted, evicted)
+			} else {
+				s.unspilled(key)
+			}
+		}
+		s.mu.Unlock()
+	}
+	c.report(evicted)
+	c.changed(keys...)
+}
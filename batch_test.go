@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestBatch(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.SetMany(map[string]int{"a": 1, "b": 2, "c": 3}, DefaultExpiration)
+	got := c.GetMany([]string{"a", "b", "d"})
+	if len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+		t.Errorf("expected a and b, got %v", got)
+	}
+	c.DeleteMany([]string{"a", "c"})
+	got = c.GetMany([]string{"a", "b", "c"})
+	if len(got) != 1 || got["b"] != 2 {
+		t.Errorf("expected only b, got %v", got)
+	}
+}
+
+func TestGetOrLoadMany(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	events := c.Watch(context.Background(), "")
+	c.Set("a", 1)
+	<-events
+	var calls [][]string
+	loader := func(missing []string) (map[string]int, error) {
+		calls = append(calls, missing)
+		// c is left out, as if it did not exist.
+		return map[string]int{"b": 2, "d": 4}, nil
+	}
+	v, err := c.GetOrLoadMany([]string{"a", "b", "b", "c"}, loader)
+	if err != nil || len(v) != 2 || v["a"] != 1 || v["b"] != 2 {
+		t.Errorf("expected a and b, got %v, %v", v, err)
+	}
+	if len(calls) != 1 || len(calls[0]) != 2 || calls[0][0] != "b" || calls[0][1] != "c" {
+		t.Errorf("expected a single load of b and c, got %v", calls)
+	}
+	if _, ok := c.Get("d"); ok {
+		t.Error("expected the keys which were not requested not to be stored")
+	}
+	if e := <-events; e.Type != EventSet || e.Key != "b" {
+		t.Errorf("expected the loaded values to be watched, got %v", e)
+	}
+	errLoad := errors.New("load failed")
+	v, err = c.GetOrLoadMany([]string{"a", "b", "c"}, func([]string) (map[string]int, error) {
+		return nil, errLoad
+	})
+	if err != errLoad || len(v) != 2 {
+		t.Errorf("expected the cached values with the error, got %v, %v", v, err)
+	}
+	c.SetMany(map[string]int{"e": 5}, DefaultExpiration)
+	if e := <-events; e.Key != "e" {
+		t.Errorf("expected SetMany to be watched, got %v", e)
+	}
+}
+
+func TestSetManyJitter(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[int, int](time.Minute, 0, WithClock(clock), WithTTLJitter(0.5))
+	items := make(map[int]int)
+	for i := 0; i < 16; i++ {
+		items[i] = i
+	}
+	c.SetMany(items, DefaultExpiration)
+	ttls := make(map[time.Duration]struct{})
+	for key := range items {
+		ttl, _ := c.TTL(key)
+		ttls[ttl] = struct{}{}
+	}
+	if len(ttls) < 2 {
+		t.Errorf("expected the items of a batch to expire at different times, got %v", ttls)
+	}
+}
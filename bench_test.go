@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The benchmarks run on caches of benchKeys items. Compare two revisions with benchstat:
+//
+//	go test -run '^$' -bench . -benchmem -count 10 > old.txt
+//	go test -run '^$' -bench . -benchmem -count 10 > new.txt
+//	benchstat old.txt new.txt
+const benchKeys = 1 << 16
+
+// benchKeyset returns the keys the benchmarks use, built once so that they do not measure strconv.
+func benchKeyset() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// benchCaches are the configurations every benchmark runs with.
+var benchCaches = []struct {
+	name string
+	new  func() *GenericCache[[]byte]
+}{
+	{"Single", func() *GenericCache[[]byte] { return New[[]byte](time.Hour, 0) }},
+	{"Sharded", func() *GenericCache[[]byte] { return NewSharded[[]byte](16, time.Hour, 0) }},
+	{"Striped", func() *GenericCache[[]byte] { return New[[]byte](time.Hour, 0, WithBackend(StripedBackend)) }},
+	{"Bounded", func() *GenericCache[[]byte] { return NewSharded[[]byte](16, time.Hour, 0, WithMaxEntries(benchKeys)) }},
+}
+
+// benchFilled returns a cache storing every key with a value of size bytes.
+func benchFilled(newCache func() *GenericCache[[]byte], keys []string, size int) *GenericCache[[]byte] {
+	c := newCache()
+	value := make([]byte, size)
+	for _, key := range keys {
+		c.Set(key, value)
+	}
+	return c
+}
+
+func BenchmarkGetHit(b *testing.B) {
+	keys := benchKeyset()
+	for _, bc := range benchCaches {
+		b.Run(bc.name, func(b *testing.B) {
+			c := benchFilled(bc.new, keys, 64)
+			defer c.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Get(keys[i%benchKeys])
+			}
+		})
+	}
+}
+
+func BenchmarkGetMiss(b *testing.B) {
+	keys := benchKeyset()
+	for _, bc := range benchCaches {
+		b.Run(bc.name, func(b *testing.B) {
+			c := bc.new()
+			defer c.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Get(keys[i%benchKeys])
+			}
+		})
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	keys := benchKeyset()
+	value := make([]byte, 64)
+	for _, bc := range benchCaches {
+		b.Run(bc.name, func(b *testing.B) {
+			c := bc.new()
+			defer c.Close()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Set(keys[i%benchKeys], value)
+			}
+		})
+	}
+}
+
+// BenchmarkSetEvicting stores new keys in a full bounded cache, so that every Set evicts an item,
+// like the caches of many short-lived items.
+func BenchmarkSetEvicting(b *testing.B) {
+	keys := benchKeyset()
+	value := make([]byte, 64)
+	c := NewSharded[[]byte](16, time.Hour, 0, WithMaxEntries(benchKeys/4))
+	defer c.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(keys[i%benchKeys], value)
+	}
+}
+
+// BenchmarkSetLargeValues measures how the size of the values affects Set and Get,
+// which store and return them without copies by default.
+func BenchmarkSetLargeValues(b *testing.B) {
+	keys := benchKeyset()
+	for _, size := range []int{1 << 10, 64 << 10} {
+		value := make([]byte, size)
+		b.Run(fmt.Sprintf("%dKB", size>>10), func(b *testing.B) {
+			c := NewSharded[[]byte](16, time.Hour, 0, WithMaxEntries(1024))
+			defer c.Close()
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%benchKeys]
+				c.Set(key, value)
+				c.Get(key)
+			}
+		})
+	}
+}
+
+// BenchmarkMixed runs 90% of Get and 10% of Set on a filled cache, from one goroutine.
+func BenchmarkMixed(b *testing.B) {
+	keys := benchKeyset()
+	value := make([]byte, 64)
+	for _, bc := range benchCaches {
+		b.Run(bc.name, func(b *testing.B) {
+			c := benchFilled(bc.new, keys, 64)
+			defer c.Close()
+			r := rand.New(rand.NewSource(1))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[r.Intn(benchKeys)]
+				if i%10 == 0 {
+					c.Set(key, value)
+				} else {
+					c.Get(key)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkParallel runs the 90/10 mix of BenchmarkMixed from a growing number of goroutines
+// per GOMAXPROCS, to show how the cache scales with contention.
+func BenchmarkParallel(b *testing.B) {
+	keys := benchKeyset()
+	value := make([]byte, 64)
+	for _, bc := range benchCaches {
+		for _, parallelism := range []int{1, 4, 16, 64} {
+			b.Run(fmt.Sprintf("%s/%d", bc.name, parallelism), func(b *testing.B) {
+				c := benchFilled(bc.new, keys, 64)
+				defer c.Close()
+				var seed int64
+				b.SetParallelism(parallelism)
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(atomic.AddInt64(&seed, 1)))
+					for i := 0; pb.Next(); i++ {
+						key := keys[r.Intn(benchKeys)]
+						if i%10 == 0 {
+							c.Set(key, value)
+						} else {
+							c.Get(key)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package boltstore implements cache.Overflow with bbolt, an embedded key/value store.
+package boltstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/eatmoreapple/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Option configures a Store.
+type Option func(*options)
+
+type options struct {
+	// keyCodec and valueCodec are a cache.Codec[K] and a cache.Codec[V]
+	// matching the key and value types of the store.
+	keyCodec   any
+	valueCodec any
+}
+
+// WithKeyCodec sets the codec of the stored keys, cache.JSONCodec by default.
+// Its type must match the key type of the store, or New panics.
+func WithKeyCodec[K any](codec cache.Codec[K]) Option {
+	return func(o *options) {
+		o.keyCodec = codec
+	}
+}
+
+// WithValueCodec sets the codec of the stored values, cache.JSONCodec by default.
+// Its type must match the value type of the store, or New panics.
+func WithValueCodec[V any](codec cache.Codec[V]) Option {
+	return func(o *options) {
+		o.valueCodec = codec
+	}
+}
+
+// Store is a cache.Overflow storing items in a bbolt bucket.
+// Every value is stored after its expiration time, as 8 big endian bytes.
+type Store[K comparable, V any] struct {
+	db         *bolt.DB
+	bucket     []byte
+	keyCodec   cache.Codec[K]
+	valueCodec cache.Codec[V]
+}
+
+var _ cache.Overflow[string, any] = (*Store[string, any])(nil)
+
+// New returns a Store using the bucket of db, which is created if it does not exist.
+// The bucket should not be shared, Clear deletes it.
+func New[K comparable, V any](db *bolt.DB, bucket string, opts ...Option) (*Store[K, V], error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	s := &Store[K, V]{db: db, bucket: []byte(bucket), keyCodec: cache.JSONCodec[K]{}, valueCodec: cache.JSONCodec[V]{}}
+	if o.keyCodec != nil {
+		codec, ok := o.keyCodec.(cache.Codec[K])
+		if !ok {
+			panic(fmt.Sprintf("boltstore: WithKeyCodec codec %T does not match store of %T keys", o.keyCodec, *new(K)))
+		}
+		s.keyCodec = codec
+	}
+	if o.valueCodec != nil {
+		codec, ok := o.valueCodec.(cache.Codec[V])
+		if !ok {
+			panic(fmt.Sprintf("boltstore: WithValueCodec codec %T does not match store of %T values", o.valueCodec, *new(V)))
+		}
+		s.valueCodec = codec
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put stores the value with its expiration time in unix nanoseconds.
+func (s *Store[K, V]) Put(key K, value V, expiration int64) error {
+	k, err := s.keyCodec.Encode(key)
+	if err != nil {
+		return fmt.Errorf("boltstore: encode key %v: %w", key, err)
+	}
+	data, err := s.valueCodec.Encode(value)
+	if err != nil {
+		return fmt.Errorf("boltstore: encode %v: %w", key, err)
+	}
+	v := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(v, uint64(expiration))
+	v = append(v, data...)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put(k, v)
+	})
+}
+
+// Take removes the value associated with the key and returns it with its expiration time.
+func (s *Store[K, V]) Take(key K) (value V, expiration int64, ok bool, err error) {
+	k, err := s.keyCodec.Encode(key)
+	if err != nil {
+		return value, 0, false, fmt.Errorf("boltstore: encode key %v: %w", key, err)
+	}
+	var data []byte
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		v := b.Get(k)
+		if v == nil {
+			return nil
+		}
+		// v is only valid during the transaction.
+		data = append([]byte(nil), v...)
+		return b.Delete(k)
+	})
+	if err != nil || data == nil {
+		return value, 0, false, err
+	}
+	if len(data) < 8 {
+		return value, 0, false, fmt.Errorf("boltstore: %v: %w", key, errCorrupt)
+	}
+	value, err = s.valueCodec.Decode(data[8:])
+	if err != nil {
+		return value, 0, false, fmt.Errorf("boltstore: decode %v: %w", key, err)
+	}
+	return value, int64(binary.BigEndian.Uint64(data)), true, nil
+}
+
+// Delete removes the value associated with the key, if any.
+func (s *Store[K, V]) Delete(key K) error {
+	k, err := s.keyCodec.Encode(key)
+	if err != nil {
+		return fmt.Errorf("boltstore: encode key %v: %w", key, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Delete(k)
+	})
+}
+
+// Clear removes all values by deleting and recreating the bucket.
+func (s *Store[K, V]) Clear() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(s.bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		_, err := tx.CreateBucket(s.bucket)
+		return err
+	})
+}
+
+var errCorrupt = errors.New("value too short")
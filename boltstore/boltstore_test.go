@@ -0,0 +1,73 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestOverflow(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := New[string, int](db, "overflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cache.New[int](time.Minute, 0, cache.WithMaxEntries(2), cache.WithOverflow[string, int](s))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	if n := c.ItemCount(); n != 2 {
+		t.Errorf("expected 2 items in memory, got %d", n)
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a to be fetched back from disk, got %v, %v", v, ok)
+	}
+
+	// b was evicted to make room for a.
+	c.Set("b", 20)
+	if v, ok := c.Get("b"); !ok || v != 20 {
+		t.Errorf("expected the new value of b, got %v", v)
+	}
+	c.Delete("c")
+	if _, ok := c.Get("c"); ok {
+		t.Error("expected c to be deleted from disk")
+	}
+
+	c.Set("d", 4)
+	c.Flush()
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("expected %s to be flushed", key)
+		}
+	}
+}
+
+func TestStoreExpiration(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := New[string, string](db, "overflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiration := time.Now().UnixNano()
+	if err := s.Put("foo", "bar", expiration); err != nil {
+		t.Fatal(err)
+	}
+	v, exp, ok, err := s.Take("foo")
+	if err != nil || !ok || v != "bar" || exp != expiration {
+		t.Errorf("expected foo to be bar expiring at %d, got %v, %d, %v, %v", expiration, v, exp, ok, err)
+	}
+	if _, _, ok, _ := s.Take("foo"); ok {
+		t.Error("expected foo to be removed once taken")
+	}
+}
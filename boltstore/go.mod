@@ -0,0 +1,12 @@
+module github.com/eatmoreapple/cache/boltstore
+
+go 1.25.0
+
+require (
+	github.com/eatmoreapple/cache v0.0.0
+	go.etcd.io/bbolt v1.5.0
+)
+
+require golang.org/x/sys v0.45.0 // indirect
+
+replace github.com/eatmoreapple/cache => ../
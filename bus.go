@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// Invalidation is a change to a key published on a Bus.
+type Invalidation struct {
+	// Source identifies the cache which changed the key.
+	Source string
+	Key    string
+}
+
+// Bus carries invalidations between the caches of several processes, so that they delete
+// their copy of the keys changed by the others. Implementations must be safe for concurrent use.
+type Bus interface {
+	// Publish sends inv to all subscribers, the publishing cache included.
+	// It is called after every write, outside of the cache locks.
+	Publish(inv Invalidation)
+	// Subscribe calls handler with every published invalidation until unsubscribe is called.
+	Subscribe(handler func(inv Invalidation)) (unsubscribe func())
+}
+
+// WithInvalidationBus publishes the keys written or deleted by the cache on bus, and deletes the
+// keys published by other caches. Values loaded by GetOrLoad and evictions are not published.
+// The cache must have string keys, or the constructor panics.
+func WithInvalidationBus(bus Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
+}
+
+// subscription links a cache to an invalidation bus.
+type subscription struct {
+	bus         Bus
+	source      string
+	unsubscribe func()
+}
+
+// subscribe links c to bus.
+func (c *cache[K, V]) subscribe(bus Bus) {
+	if _, ok := any(*new(K)).(string); !ok {
+		panic(fmt.Sprintf("cache: WithInvalidationBus requires string keys, got %T", *new(K)))
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	sub := &subscription{bus: bus, source: hex.EncodeToString(id)}
+	sub.unsubscribe = bus.Subscribe(func(inv Invalidation) {
+		if inv.Source != sub.source {
+			c.remove(any(inv.Key).(K))
+		}
+	})
+	c.sub = sub
+}
+
+// publish sends the keys changed by a write to the invalidation bus, if any.
+func (c *cache[K, V]) publish(keys ...K) {
+	if c.sub == nil {
+		return
+	}
+	for _, key := range keys {
+		c.sub.bus.Publish(Invalidation{Source: c.sub.source, Key: any(key).(string)})
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// localBus is a Bus delivering invalidations synchronously within the process.
+type localBus struct {
+	mu       sync.Mutex
+	handlers map[int]func(Invalidation)
+	next     int
+}
+
+func (b *localBus) Publish(inv Invalidation) {
+	b.mu.Lock()
+	handlers := make([]func(Invalidation), 0, len(b.handlers))
+	for _, h := range b.handlers {
+		handlers = append(handlers, h)
+	}
+	b.mu.Unlock()
+	for _, h := range handlers {
+		h(inv)
+	}
+}
+
+func (b *localBus) Subscribe(handler func(Invalidation)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]func(Invalidation))
+	}
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
+
+func TestInvalidationBus(t *testing.T) {
+	bus := &localBus{}
+	a := New[string](time.Minute, 0, WithInvalidationBus(bus))
+	b := New[string](time.Minute, 0, WithInvalidationBus(bus))
+
+	a.Set("foo", "bar")
+	b.Set("foo", "baz")
+	if _, ok := a.Get("foo"); ok {
+		t.Error("expected foo to be invalidated in a after b set it")
+	}
+	if v, _ := b.Get("foo"); v != "baz" {
+		t.Errorf("expected b to keep its own write, got %v", v)
+	}
+
+	a.Set("qux", "quux")
+	b.Set("qux", "quux")
+	a.Delete("qux")
+	if _, ok := b.Get("qux"); ok {
+		t.Error("expected qux to be invalidated in b after a deleted it")
+	}
+
+	b.Set("foo", "baz")
+	a.GetOrLoad("foo", func() (string, error) { return "baz", nil })
+	if _, ok := b.Get("foo"); !ok {
+		t.Error("expected loaded values not to be published")
+	}
+}
+
+func TestInvalidationBusKeys(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected non string keys to panic")
+		}
+	}()
+	NewKeyed[int, string](time.Minute, 0, WithInvalidationBus(&localBus{}))
+}
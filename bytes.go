@@ -0,0 +1,68 @@
+package cache
+
+import "time"
+
+// BytesCache is a cache of byte slices which accounts for the bytes it stores.
+// By default, Set stores the slice it is given and Get returns the stored slice without copying,
+// so neither must be modified afterwards. WithCopies makes Set and Get copy instead.
+type BytesCache struct {
+	*GenericCache[[]byte]
+	// copies is set by WithCopies.
+	copies bool
+}
+
+// WithCopies makes a BytesCache copy the slices passed to Set and returned by Get, so that callers
+// cannot modify the cached bytes by mistake. It is ignored by other caches.
+func WithCopies() Option {
+	return func(o *options) {
+		o.copyBytes = true
+	}
+}
+
+// NewBytesCache returns a new BytesCache holding up to maxBytes bytes, counting the keys and
+// the values. When the budget is exceeded, items are evicted according to the policy, see WithPolicy.
+// maxBytes <= 0 means the cache is unbounded, but its size is still accounted for.
+// WithCost and WithMaxCost are overridden.
+func NewBytesCache(maxBytes int64, defaultExpiration, cleanupInterval time.Duration, opts ...Option) *BytesCache {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	opts = append(opts, WithMaxCost(maxBytes), WithCost(func(key string, value []byte) int64 {
+		return int64(len(key) + len(value))
+	}))
+	return &BytesCache{
+		GenericCache: New[[]byte](defaultExpiration, cleanupInterval, opts...),
+		copies:       o.copyBytes,
+	}
+}
+
+// Set adds an item to the cache, replacing any existing item, with the default expiration.
+func (b *BytesCache) Set(key string, value []byte) {
+	b.GenericCache.Set(key, b.copy(value))
+}
+
+// SetWithExpireIn adds an item to the cache, replacing any existing item, with the given expiration.
+func (b *BytesCache) SetWithExpireIn(key string, value []byte, expireIn time.Duration) {
+	b.GenericCache.SetWithExpireIn(key, b.copy(value), expireIn)
+}
+
+// Get returns the value associated with the key, and whether it exists.
+func (b *BytesCache) Get(key string) ([]byte, bool) {
+	value, ok := b.GenericCache.Get(key)
+	return b.copy(value), ok
+}
+
+// Size returns the number of bytes stored, keys included. This may include items that have
+// expired, but have not yet been cleaned up.
+func (b *BytesCache) Size() int64 {
+	return b.totalCost()
+}
+
+// copy returns a copy of value if the cache was created WithCopies, and value otherwise.
+func (b *BytesCache) copy(value []byte) []byte {
+	if !b.copies || value == nil {
+		return value
+	}
+	return append([]byte{}, value...)
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesCache(t *testing.T) {
+	c := NewBytesCache(0, NoExpiration, 0)
+	value := []byte("bar")
+	c.Set("foo", value)
+	if n := c.Size(); n != 6 {
+		t.Errorf("expected 6 bytes, got %d", n)
+	}
+	got, _ := c.Get("foo")
+	if &got[0] != &value[0] {
+		t.Error("expected Get to return the stored slice")
+	}
+	c.Set("foo", []byte("barbaz"))
+	if n := c.Size(); n != 9 {
+		t.Errorf("expected replacing an item to update the size to 9, got %d", n)
+	}
+	c.Delete("foo")
+	if n := c.Size(); n != 0 {
+		t.Errorf("expected deleting an item to update the size to 0, got %d", n)
+	}
+}
+
+func TestBytesCacheBudget(t *testing.T) {
+	c := NewBytesCache(20, NoExpiration, 0)
+	c.Set("a", make([]byte, 9))
+	c.Set("b", make([]byte, 9))
+	c.Set("c", make([]byte, 9))
+	if n := c.Size(); n > 20 {
+		t.Errorf("expected at most 20 bytes, got %d", n)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected the least recently used item to be evicted")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("expected the last item to be kept")
+	}
+}
+
+func TestBytesCacheCopies(t *testing.T) {
+	c := NewBytesCache(0, NoExpiration, 0, WithCopies())
+	value := []byte("bar")
+	c.Set("foo", value)
+	value[0] = 'x'
+	got, _ := c.Get("foo")
+	if !bytes.Equal(got, []byte("bar")) {
+		t.Errorf("expected Set to copy the value, got %s", got)
+	}
+	got[0] = 'x'
+	if got, _ := c.Get("foo"); !bytes.Equal(got, []byte("bar")) {
+		t.Errorf("expected Get to return a copy, got %s", got)
+	}
+}
@@ -1,11 +1,9 @@
 package cache
 
 import (
-	"io"
+	"strings"
 	"sync"
 	"time"
-
-	gocache "github.com/patrickmn/go-cache"
 )
 
 const (
@@ -16,111 +14,32 @@ const (
 )
 
 // GenericCache is a generic cache that can be used with any type.
+// Items are keyed by strings, see KeyedCache for other key types.
 type GenericCache[T any] struct {
-	cache *gocache.Cache
-}
-
-// Set add an item to the cache, replacing any existing item. If the duration is 0
-// (DefaultExpiration), the cache's default expiration time is used. If it is -1
-// (NoExpiration), the item never expires.
-func (g *GenericCache[T]) Set(key string, v T) {
-	g.SetWithExpireIn(key, v, DefaultExpiration)
-}
-
-// SetWithExpireIn add an item to the cache, replacing any existing item. If the duration is 0
-func (g *GenericCache[T]) SetWithExpireIn(key string, value T, expireIn time.Duration) {
-	g.cache.Set(key, value, expireIn)
-}
-
-// Get returns the value of the item associated with the key, or nil if no item
-func (g *GenericCache[T]) Get(key string) (result T, exists bool) {
-	v, ok := g.cache.Get(key)
-	if !ok {
-		return
-	}
-	return v.(T), true
-}
-
-// Delete removes the provided key from the cache.
-func (g *GenericCache[T]) Delete(key string) {
-	g.cache.Delete(key)
-}
-
-// DeleteExpired removes all expired items from the cache.
-func (g *GenericCache[T]) DeleteExpired() {
-	g.cache.DeleteExpired()
-}
-
-// Add adds an item to the cache, only if the key does not already exist.
-// otherwise, it returns false and does nothing.
-func (g *GenericCache[T]) Add(key string, value T) bool {
-	return g.AddWithExpireIn(key, value, DefaultExpiration)
-}
-
-// AddWithExpireIn adds an item to the cache, only if the key does not already exist.
-// otherwise, it returns false and does nothing.
-func (g *GenericCache[T]) AddWithExpireIn(key string, value T, expireIn time.Duration) bool {
-	err := g.cache.Add(key, value, expireIn)
-	return err == nil
-}
-
-// SetIfNotExists sets the value of the item associated with the key, only if the key does not already exist.
-// otherwise, it returns an error.
-func (g *GenericCache[T]) SetIfNotExists(key string, value T) bool {
-	return g.SetIfNotExistsWithExpireIn(key, value, DefaultExpiration)
-}
-
-// SetIfNotExistsWithExpireIn sets the value of the item associated with the key, only if the key does not already exist.
-// otherwise, it returns an error.
-func (g *GenericCache[T]) SetIfNotExistsWithExpireIn(key string, value T, expireIn time.Duration) bool {
-	err := g.cache.Add(key, value, expireIn)
-	return err == nil
-}
-
-// Replace replaces an item in the cache, only if the key already exists.
-// otherwise, does nothing and returns false.
-func (g *GenericCache[T]) Replace(key string, value T) bool {
-	return g.ReplaceWithExpireIn(key, value, DefaultExpiration)
-}
-
-// ReplaceWithExpireIn replaces an item in the cache, only if the key already exists.
-// otherwise, does nothing and returns false.
-func (g *GenericCache[T]) ReplaceWithExpireIn(key string, value T, expireIn time.Duration) bool {
-	err := g.cache.Replace(key, value, expireIn)
-	return err == nil
-}
-
-// SetIfExists sets the value of the item associated with the key, only if the key already exists.
-// otherwise, does nothing and returns false.
-func (g *GenericCache[T]) SetIfExists(key string, value T) bool {
-	return g.SetIfExistsWithExpireIn(key, value, DefaultExpiration)
-}
+	*KeyedCache[string, T]
 
-// SetIfExistsWithExpireIn sets the value of the item associated with the key, only if the key already exists.
-// otherwise, does nothing and returns false.
-func (g *GenericCache[T]) SetIfExistsWithExpireIn(key string, value T, expireIn time.Duration) bool {
-	return g.ReplaceWithExpireIn(key, value, expireIn)
+	// mu protects namespaces.
+	mu         sync.Mutex
+	namespaces map[string]*Namespace[T]
 }
 
-// Flush removes all items from the cache.
-func (g *GenericCache[T]) Flush() {
-	g.cache.Flush()
+// DeletePrefix removes all items whose keys start with prefix and returns how many were removed.
+func (g *GenericCache[T]) DeletePrefix(prefix string) int {
+	return g.deleteFunc(func(key string, _ T) bool {
+		return strings.HasPrefix(key, prefix)
+	}, EvictionReasonDeleted, true)
 }
 
-// DumpTo dumps the cache to the given writer.
-func (g *GenericCache[T]) DumpTo(writer io.Writer) error {
-	return g.cache.Save(writer)
+// New returns a new GenericCache[T] with the given default expiration duration and cleanup interval.
+func New[T any](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *GenericCache[T] {
+	return &GenericCache[T]{KeyedCache: NewKeyed[string, T](defaultExpiration, cleanupInterval, opts...)}
 }
 
-// LoadFrom loads the cache from the given reader.
-func (g *GenericCache[T]) LoadFrom(reader io.Reader) error {
-	return g.cache.Load(reader)
-}
-
-// New returns a new GenericCache[T] with the given default expiration duration and cleanup interval.
-func New[T any](defaultExpiration, cleanupInterval time.Duration) *GenericCache[T] {
-	cache := gocache.New(defaultExpiration, cleanupInterval)
-	return &GenericCache[T]{cache: cache}
+// NewSharded returns a new GenericCache[T] which spreads its items over the given number of shards.
+// Every shard has its own lock and cleanup janitor, which reduces lock contention under
+// concurrent writes. The WithMaxEntries bound is divided evenly between the shards.
+func NewSharded[T any](shards int, defaultExpiration, cleanupInterval time.Duration, opts ...Option) *GenericCache[T] {
+	return &GenericCache[T]{KeyedCache: NewShardedKeyed[string, T](shards, hashString, defaultExpiration, cleanupInterval, opts...)}
 }
 
 // Numeric is a numeric type.
@@ -133,26 +52,98 @@ type Numeric interface {
 }
 
 // NumericCache is a cache that can be used with any numeric type.
+// Its operations are atomic with respect to every other operation on the cache, Set included,
+// since they run under the lock of the shard holding the key.
 type NumericCache[T Numeric] struct {
 	*GenericCache[T]
-	// mu is used to protect the cache from concurrent access.
-	mu sync.RWMutex
+	// keepTTL is set by WithKeepTTL.
+	keepTTL bool
+}
+
+// WithKeepTTL makes the increments of a NumericCache keep the expiration of existing items,
+// instead of resetting it, so that a counter expires a fixed time after it was created,
+// as fixed-window rate limiting requires. It is ignored by other caches.
+func WithKeepTTL() Option {
+	return func(o *options) {
+		o.keepTTL = true
+	}
 }
 
 // Increment increments the value of the item associated with the key by delta.
 // if the key does not exist, it returns false and zero.
 // otherwise, it returns true and the incremented value.
+// The item gets the default expiration, unless the cache was created WithKeepTTL.
 func (n *NumericCache[T]) Increment(key string, delta T) (T, bool) {
-	n.mu.Lock()
-	v, ok := n.Get(key)
-	if !ok {
-		n.mu.Unlock()
-		return v, false
-	}
-	v += delta
-	n.Set(key, v)
-	n.mu.Unlock()
-	return v, true
+	return n.update(key, DefaultExpiration, n.keepTTL, func(v T, exists bool) (T, bool) {
+		return v + delta, exists
+	})
+}
+
+// IncrementOrSet increments the value of the item associated with the key by delta and returns it.
+// If the key does not exist, it stores initial instead and returns it, so that counters can be
+// created and incremented without racing each other. Either way, the item expires in ttl,
+// unless the cache was created WithKeepTTL and the item existed, which then keeps its expiration.
+func (n *NumericCache[T]) IncrementOrSet(key string, delta T, initial T, ttl time.Duration) T {
+	v, _ := n.update(key, ttl, n.keepTTL, func(v T, exists bool) (T, bool) {
+		if !exists {
+			return initial, true
+		}
+		return v + delta, true
+	})
+	return v
+}
+
+// SetMax stores v if the key does not exist or v is greater than its value, for high-water marks.
+// It returns the value in the cache after the call. The item gets the default expiration,
+// unless the cache was created WithKeepTTL and the item existed.
+func (n *NumericCache[T]) SetMax(key string, v T) T {
+	v, _ = n.update(key, DefaultExpiration, n.keepTTL, func(current T, exists bool) (T, bool) {
+		return v, !exists || v > current
+	})
+	return v
+}
+
+// SetMin stores v if the key does not exist or v is less than its value, for low-water marks.
+// It returns the value in the cache after the call, and handles expiration like SetMax.
+func (n *NumericCache[T]) SetMin(key string, v T) T {
+	v, _ = n.update(key, DefaultExpiration, n.keepTTL, func(current T, exists bool) (T, bool) {
+		return v, !exists || v < current
+	})
+	return v
+}
+
+// AddIfLess adds delta to the value of the item associated with the key if it is less than limit,
+// a missing item counting as zero, and reports whether it did. It returns the value after the call.
+// For example, AddIfLess(key, 1, max) acquires one of max slots. It handles expiration like SetMax.
+func (n *NumericCache[T]) AddIfLess(key string, delta, limit T) (T, bool) {
+	return n.addIf(key, delta, func(current T) bool { return current < limit })
+}
+
+// AddIfLessWithExpireIn is AddIfLess storing a new item with expireIn, so that it is created with
+// its expiration in one step. An existing item gets expireIn as well, unless the cache was created
+// WithKeepTTL.
+func (n *NumericCache[T]) AddIfLessWithExpireIn(key string, delta, limit T, expireIn time.Duration) (v T, added bool) {
+	v, _ = n.update(key, expireIn, n.keepTTL, func(current T, _ bool) (T, bool) {
+		added = current < limit
+		return current + delta, added
+	})
+	return v, added
+}
+
+// AddIfGreater adds delta to the value of the item associated with the key if it is greater than
+// limit, a missing item counting as zero, and reports whether it did. It returns the value after
+// the call. For example, AddIfGreater(key, -1, 0) releases a slot. It handles expiration like SetMax.
+func (n *NumericCache[T]) AddIfGreater(key string, delta, limit T) (T, bool) {
+	return n.addIf(key, delta, func(current T) bool { return current > limit })
+}
+
+// addIf adds delta to the value of the item associated with the key if ok returns true for it.
+func (n *NumericCache[T]) addIf(key string, delta T, ok func(current T) bool) (v T, added bool) {
+	v, _ = n.update(key, DefaultExpiration, n.keepTTL, func(current T, _ bool) (T, bool) {
+		added = ok(current)
+		return current + delta, added
+	})
+	return v, added
 }
 
 // Decrement decrements the value of the item associated with the key by delta.
@@ -163,8 +154,13 @@ func (n *NumericCache[T]) Decrement(key string, delta T) (T, bool) {
 }
 
 // NewNumericCache returns a new NumericCache[T] with the given default expiration duration and cleanup interval.
-func NewNumericCache[T Numeric](defaultExpiration, cleanupInterval time.Duration) *NumericCache[T] {
+func NewNumericCache[T Numeric](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *NumericCache[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &NumericCache[T]{
-		GenericCache: New[T](defaultExpiration, cleanupInterval),
+		GenericCache: New[T](defaultExpiration, cleanupInterval, opts...),
+		keepTTL:      o.keepTTL,
 	}
 }
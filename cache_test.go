@@ -1,18 +1,23 @@
 package cache
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
 )
 
 // GenericCache is a generic cache that can be used with any type.
 func TestGenericCache(t *testing.T) {
-	c := New[string](time.Second*3, time.Second)
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Second*3, time.Second, WithClock(clock))
 	c.Set("foo", "bar")
 	if v, ok := c.Get("foo"); !ok || v != "bar" {
 		t.Errorf("expected foo to be bar, got %v", v)
 	}
-	time.Sleep(time.Second * 3)
+	clock.Advance(time.Second * 3)
 	if _, ok := c.Get("foo"); ok {
 		t.Errorf("expected foo to be expired")
 	}
@@ -27,7 +32,8 @@ func TestGenericCache(t *testing.T) {
 }
 
 func TestNewNumericCache(t *testing.T) {
-	c := NewNumericCache[int64](time.Second*3, time.Second)
+	clock := cachetest.NewClock(time.Now())
+	c := NewNumericCache[int64](time.Second*3, time.Second, WithClock(clock))
 	c.Set("foo", 123)
 	if v, ok := c.Get("foo"); !ok || v != 123 {
 		t.Errorf("expected foo to be 123, got %v", v)
@@ -36,8 +42,227 @@ func TestNewNumericCache(t *testing.T) {
 	if v, ok := c.Get("foo"); !ok || v != 124 {
 		t.Errorf("expected foo to be 124, got %v", v)
 	}
-	time.Sleep(time.Second * 3)
+	clock.Advance(time.Second * 3)
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("expected foo to be expired")
+	}
+}
+
+func TestIncrementAtomic(t *testing.T) {
+	c := NewNumericCache[int](NoExpiration, 0)
+	c.Set("n", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Increment("n", 1)
+		}()
+		// the operations of the embedded cache do not bypass the numeric ones.
+		go func() {
+			defer wg.Done()
+			c.Update("n", func(v int, _ bool) (int, bool) { return v + 1, true })
+		}()
+	}
+	wg.Wait()
+	if v, _ := c.Get("n"); v != 200 {
+		t.Errorf("expected no increment to be lost, got %v", v)
+	}
+}
+
+func TestIncrementKeepTTL(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewNumericCache[int](time.Hour, 0, WithClock(clock), WithKeepTTL())
+	c.IncrementOrSet("window", 1, 1, time.Minute)
+	clock.Advance(time.Second * 30)
+	c.IncrementOrSet("window", 1, 1, time.Minute)
+	c.Increment("window", 1)
+	if ttl, _ := c.TTL("window"); ttl != time.Second*30 {
+		t.Errorf("expected the window to keep its expiration, got %v", ttl)
+	}
+	clock.Advance(time.Second * 30)
+	if v := c.IncrementOrSet("window", 1, 1, time.Minute); v != 1 {
+		t.Errorf("expected a new window to start, got %v", v)
+	}
+
+	reset := NewNumericCache[int](time.Hour, 0, WithClock(clock))
+	reset.SetWithExpireIn("n", 1, time.Minute)
+	reset.Increment("n", 1)
+	if ttl, _ := reset.TTL("n"); ttl != time.Hour {
+		t.Errorf("expected the default expiration without WithKeepTTL, got %v", ttl)
+	}
+}
+
+func TestNumericAggregates(t *testing.T) {
+	c := NewNumericCache[int](NoExpiration, 0)
+	for _, v := range []int{3, 7, 5} {
+		c.SetMax("max", v)
+		c.SetMin("min", v)
+	}
+	if v, _ := c.Get("max"); v != 7 {
+		t.Errorf("expected max to be 7, got %v", v)
+	}
+	if v := c.SetMin("min", 4); v != 3 {
+		t.Errorf("expected min to stay 3, got %v", v)
+	}
+
+	for i, want := range []int{1, 2, 2} {
+		if v, ok := c.AddIfLess("slots", 1, 2); ok != (i < 2) || v != want {
+			t.Errorf("expected slot %d to be acquired: %v, got %v, %v", i, i < 2, v, ok)
+		}
+	}
+	for i, want := range []int{1, 0, 0} {
+		if v, ok := c.AddIfGreater("slots", -1, 0); ok != (i < 2) || v != want {
+			t.Errorf("expected slot %d to be released: %v, got %v, %v", i, i < 2, v, ok)
+		}
+	}
+}
+
+func TestAddIfLessWithExpireIn(t *testing.T) {
+	clock := cachetest.NewClock(time.Unix(1e9, 0))
+	c := NewNumericCache[int](NoExpiration, 0, WithClock(clock), WithKeepTTL())
+	if _, ok := c.AddIfLessWithExpireIn("slots", 1, 0, time.Minute); ok {
+		t.Error("expected no slot to be acquired past the limit")
+	}
+	if _, ok := c.Get("slots"); ok {
+		t.Error("expected no item to be created past the limit")
+	}
+	c.AddIfLessWithExpireIn("slots", 1, 2, time.Minute)
+	clock.Advance(time.Second * 30)
+	c.AddIfLessWithExpireIn("slots", 1, 2, time.Minute)
+	clock.Advance(time.Second * 30)
+	if _, ok := c.Get("slots"); ok {
+		t.Error("expected the item to expire a minute after it was created")
+	}
+}
+
+func TestIncrementOrSet(t *testing.T) {
+	c := NewNumericCache[int](NoExpiration, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.IncrementOrSet("hits", 1, 1, time.Minute)
+		}()
+	}
+	wg.Wait()
+	if v, _ := c.Get("hits"); v != 100 {
+		t.Errorf("expected 100 hits, got %v", v)
+	}
+}
+
+func TestGenericCacheMaxEntries(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(2))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a to be 1, got %v", v)
+	}
+	c.Delete("a")
+	c.Set("d", 4)
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("expected c to be 3, got %v", v)
+	}
+}
+
+func TestGenericCacheOnEvicted(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](NoExpiration, 0, WithMaxEntries(2), WithClock(clock))
+	reasons := make(map[string]EvictionReason)
+	c.OnEvicted(func(key string, value int, reason EvictionReason) {
+		reasons[key] = reason
+	})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Delete("b")
+	c.SetWithExpireIn("d", 4, time.Millisecond)
+	clock.Advance(time.Millisecond * 2)
+	c.DeleteExpired()
+	c.Flush()
+	expected := map[string]EvictionReason{
+		"a": EvictionReasonCapacity,
+		"b": EvictionReasonDeleted,
+		"c": EvictionReasonFlushed,
+		"d": EvictionReasonExpired,
+	}
+	for key, reason := range expected {
+		if reasons[key] != reason {
+			t.Errorf("expected %s to be evicted as %v, got %v", key, reason, reasons[key])
+		}
+	}
+}
+
+func TestNewSharded(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewSharded[int](8, NoExpiration, time.Millisecond, WithClock(clock))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Set(fmt.Sprintf("%d:%d", i, j), j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	got := c.GetMany([]string{"0:1", "3:50", "7:99", "8:0"})
+	if len(got) != 3 || got["0:1"] != 1 || got["3:50"] != 50 || got["7:99"] != 99 {
+		t.Errorf("expected 3 items, got %v", got)
+	}
+	c.SetWithExpireIn("foo", 1, time.Millisecond)
+	clock.Advance(time.Millisecond * 10)
 	if _, ok := c.Get("foo"); ok {
 		t.Errorf("expected foo to be expired")
 	}
 }
+
+func TestDeletePrefix(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	c.SetMany(map[string]int{"user:1:name": 1, "user:1:age": 2, "user:2:name": 3}, DefaultExpiration)
+	if n := c.DeletePrefix("user:1:"); n != 2 {
+		t.Errorf("expected 2 items to be deleted, got %d", n)
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "user:2:name" {
+		t.Errorf("expected only user:2:name to be left, got %v", keys)
+	}
+}
+
+func TestGenericCacheMaxCost(t *testing.T) {
+	c := New[string](NoExpiration, 0, WithMaxCost(10), WithCost(func(_ string, v string) int64 {
+		return int64(len(v))
+	}))
+	c.Set("a", "1234")
+	c.Set("b", "1234")
+	c.Get("a")
+	c.Set("c", "123")
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if !c.CompareAndSwap("c", "123", "1234567") {
+		t.Errorf("expected c to be swapped")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+	c.Set("d", "12345678901")
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected an item larger than the budget to evict everything, got %d items", n)
+	}
+}
+
+func TestWithCostMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected New to panic")
+		}
+	}()
+	New[string](NoExpiration, 0, WithCost(func(string, int) int64 { return 1 }))
+}
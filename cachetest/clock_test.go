@@ -0,0 +1,27 @@
+package cachetest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(now)
+	ch := c.After(time.Minute)
+	c.Advance(time.Second * 59)
+	select {
+	case <-ch:
+		t.Errorf("expected timer to not fire yet")
+	default:
+	}
+	c.Advance(time.Second)
+	select {
+	case got := <-ch:
+		if !got.Equal(now.Add(time.Minute)) {
+			t.Errorf("expected %v, got %v", now.Add(time.Minute), got)
+		}
+	default:
+		t.Errorf("expected timer to fire")
+	}
+}
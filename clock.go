@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the time to the cache and schedules its janitor.
+// It allows tests to control time instead of sleeping, see the cachetest package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock of the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithCoarseClock makes the cache read the time from a value a goroutine updates every resolution
+// instead of calling time.Now on every operation, which matters to the caches serving millions of
+// reads per second. Items expire up to resolution late and the statistics of the items, like their
+// last access, are as coarse. It is ignored with WithClock.
+func WithCoarseClock(resolution time.Duration) Option {
+	return func(o *options) {
+		o.clockResolution = resolution
+	}
+}
+
+// coarseClock is the Clock of WithCoarseClock.
+type coarseClock struct {
+	// now is the time in unix nanoseconds, updated every resolution.
+	now        int64
+	resolution time.Duration
+	done       chan struct{}
+	once       sync.Once
+}
+
+func newCoarseClock(resolution time.Duration) *coarseClock {
+	c := &coarseClock{now: time.Now().UnixNano(), resolution: resolution, done: make(chan struct{})}
+	ticker := time.NewTicker(resolution)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case t := <-ticker.C:
+				atomic.StoreInt64(&c.now, t.UnixNano())
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *coarseClock) Now() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.now))
+}
+
+func (c *coarseClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// stop stops updating the time, it is called once the cache is closed.
+func (c *coarseClock) stop() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoarseClock(t *testing.T) {
+	c := New[int](50*time.Millisecond, 0, WithCoarseClock(10*time.Millisecond))
+	defer c.Close()
+	c.Set("foo", 1)
+	if _, ok := c.Get("foo"); !ok {
+		t.Errorf("expected foo to exist")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := c.Get("foo"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected foo to expire once the coarse clock passed its expiration")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	clone := c.Clone()
+	c.Close()
+	clone.Set("bar", 2)
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := clone.Get("bar"); ok {
+		t.Errorf("expected the clock of the clone to keep running once the cache is closed")
+	}
+	clone.Close()
+}
+
+func TestGetAllocs(t *testing.T) {
+	for name, c := range map[string]*GenericCache[int]{
+		"unbounded": New[int](time.Minute, 0),
+		"bounded":   NewSharded[int](4, time.Minute, 0, WithMaxEntries(10)),
+	} {
+		c.Set("foo", 1)
+		if allocs := testing.AllocsPerRun(100, func() { c.Get("foo"); c.Get("bar") }); allocs != 0 {
+			t.Errorf("expected Get on a %s cache not to allocate, got %v allocations", name, allocs)
+		}
+		c.Close()
+	}
+}
+
+func TestSetAllocs(t *testing.T) {
+	// values are stored unboxed, so replacing one allocates nothing.
+	c := New[int](time.Minute, 0)
+	defer c.Close()
+	c.Set("foo", 1)
+	if allocs := testing.AllocsPerRun(100, func() { c.Set("foo", 1000) }); allocs != 0 {
+		t.Errorf("expected Set not to allocate, got %v allocations", allocs)
+	}
+}
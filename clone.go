@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Clone returns an independent copy of the cache holding its live items, with their expiration
+// times, priorities, pins and tags. The items are copied while all the shards are locked, so that the copy is
+// consistent, and their values are copied by the WithCopier function if any.
+//
+// The copy has the same default expiration, clock, shards, bounds and eviction policy, whose
+// history is not copied. It is not attached to the persistence, the invalidation bus, the loaders
+// nor the eviction callbacks of the cache, its statistics start from zero, and it has to be
+// closed separately.
+func (c *cache[K, V]) Clone() *KeyedCache[K, V] {
+	clone := &cache[K, V]{
+		defaultExpiration: c.defaultExpiration,
+		cleanupInterval:   c.cleanupInterval,
+		clock:             c.clock,
+		staleFor:          c.staleFor,
+		beta:              c.beta,
+		jitter:            c.jitter,
+		minTTL:            c.minTTL,
+		maxTTL:            c.maxTTL,
+		sized:             c.sized,
+		copier:            c.copier,
+		expiresAt:         c.expiresAt,
+		transform:         c.transform,
+		shards:            make([]*shard[K, V], len(c.shards)),
+		hash:              c.hash,
+		watchers:          newWatchers[K, V](c.watchers.size),
+		evictions:         newEvictionFeed[V](c.evictions.size),
+	}
+	// the copy has its own epoch, its items are stored in its first one.
+	config := *c.shards[0].shardConfig
+	config.epoch = new(uint64)
+	config.stored = clone.stored
+	if c.coarse != nil {
+		// the clock of c stops once c is closed.
+		clone.coarse = newCoarseClock(c.coarse.resolution)
+		clone.clock, config.clock = clone.coarse, clone.coarse
+	}
+	for i := range c.shards {
+		clone.shards[i] = newShard[K, V](&config)
+	}
+	now := c.now()
+	for _, s := range c.shards {
+		s.mu.RLock()
+	}
+	for i, s := range c.shards {
+		dst := clone.shards[i]
+		for key, e := range s.items {
+			if s.expired(e, now) {
+				continue
+			}
+			// the bounds are the same, so nothing is evicted.
+			dst.setWithPriority(key, c.clone(e.value), e.expiration, s.priority(key), nil)
+			copied := dst.items[key]
+			copied.hits, copied.accessed = atomic.LoadInt64(&e.hits), atomic.LoadInt64(&e.accessed)
+			copied.created, copied.stored, copied.delta = e.created, e.stored, e.delta
+			dst.tag(key, copied, e.tags)
+			if e.pinned {
+				dst.pin(key, copied)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	// see the finalizer set by newKeyed.
+	k := &KeyedCache[K, V]{clone}
+	clone.startJanitors(clone.cleanupInterval)
+	runtime.SetFinalizer(k, stop[K, V])
+	return k
+}
+
+// Clone returns an independent copy of the cache holding its live items, see KeyedCache.Clone.
+// The items of the namespaces of g are copied, but not their statistics.
+func (g *GenericCache[T]) Clone() *GenericCache[T] {
+	return &GenericCache[T]{KeyedCache: g.KeyedCache.Clone()}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestClone(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewSharded[[]int](4, time.Minute, 0, WithClock(clock))
+	c.Set("foo", []int{1})
+	c.SetWithExpireIn("bar", []int{2}, time.Second)
+	c.SetWithExpireIn("forever", []int{3}, NoExpiration)
+	c.SetWithTags("tagged", []int{4}, DefaultExpiration, "t")
+	clock.Advance(time.Second)
+
+	clone := c.Clone()
+	defer clone.Close()
+	if n := clone.ItemCount(); n != 3 {
+		t.Errorf("expected the 3 live items to be copied, got %d", n)
+	}
+	for _, key := range []string{"foo", "forever", "tagged"} {
+		_, want, _ := c.GetWithExpiration(key)
+		if _, got, ok := clone.GetWithExpiration(key); !ok || !got.Equal(want) {
+			t.Errorf("expected %s to expire at %v, got %v", key, want, got)
+		}
+	}
+
+	clone.Set("foo", []int{5})
+	clone.Delete("forever")
+	if v, _ := c.Get("foo"); v[0] != 1 {
+		t.Errorf("expected the writes to the copy not to change the cache, got %v", v)
+	}
+	if _, ok := c.Get("forever"); !ok {
+		t.Error("expected the deletes from the copy not to change the cache")
+	}
+	if n := clone.InvalidateTag("t"); n != 1 {
+		t.Errorf("expected the tags to be copied, got %d items removed", n)
+	}
+	if _, ok := c.Get("tagged"); !ok {
+		t.Error("expected the tagged item to be kept in the cache")
+	}
+}
+
+func TestCloneCopier(t *testing.T) {
+	c := New[[]int](NoExpiration, 0, WithCopier(func(v []int) []int {
+		return append([]int(nil), v...)
+	}), WithMaxEntries(2))
+	v := []int{1}
+	c.Set("foo", v)
+	clone := c.Clone()
+	defer clone.Close()
+	v[0] = 2
+	if got, _ := clone.Get("foo"); got[0] != 1 {
+		t.Errorf("expected the values to be copied, got %v", got)
+	}
+	clone.Set("bar", nil)
+	clone.Set("baz", nil)
+	if n := clone.ItemCount(); n != 2 {
+		t.Errorf("expected the copy to keep the bound of the cache, got %d items", n)
+	}
+}
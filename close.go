@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by the methods of a cache which has been closed.
+var ErrClosed = errors.New("cache: closed")
+
+// Close shuts the cache down: it saves the pending writes of the WriteBehind wrappers of the cache,
+// waits for the loads running in the background (refresh-ahead, stale-while-revalidate),
+// writes a last WithSnapshot snapshot, stops the janitor, the snapshots, the log and the
+// invalidation bus subscription, and removes all items, calling the eviction callbacks with
+// EvictionReasonFlushed. The first error met is returned, but Close goes on regardless.
+//
+// Afterwards, the methods which return an error return ErrClosed, including Close.
+// The other ones keep working on the emptied cache, without background cleanup,
+// persistence nor invalidations.
+func (c *cache[K, V]) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return ErrClosed
+	}
+	var first error
+	keep := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	c.mu.RLock()
+	closers := c.closers
+	c.mu.RUnlock()
+	for _, close := range closers {
+		keep(close())
+	}
+	c.loads.wait()
+	if c.snapshots != nil {
+		keep(c.saveSnapshot())
+	}
+	c.shutdown()
+	if c.negative != nil {
+		keep(c.negative.Close())
+	}
+	report := c.reporting()
+	var evicted []eviction[K, V]
+	for _, s := range c.shards {
+		evicted = s.flush(evicted, report, false)
+	}
+	c.report(evicted)
+	c.evictions.close()
+	return first
+}
+
+// isClosed returns ErrClosed if c has been closed.
+func (c *cache[K, V]) isClosed() error {
+	if atomic.LoadInt32(&c.closed) != 0 {
+		return ErrClosed
+	}
+	return nil
+}
+
+// onClose registers a function called first by Close.
+func (c *cache[K, V]) onClose(close func() error) {
+	c.mu.Lock()
+	c.closers = append(c.closers, close)
+	c.mu.Unlock()
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, time.Minute, WithClock(clock), WithSnapshot(path, time.Hour), WithStaleWhileRevalidate(time.Minute))
+	var flushed int32
+	c.OnEvicted(func(key string, value string, reason EvictionReason) {
+		if reason == EvictionReasonFlushed {
+			atomic.AddInt32(&flushed, 1)
+		}
+	})
+	store := newMapStore()
+	w := NewWriteBehind[string](c, store, WithFlushInterval(time.Hour))
+	if err := w.Set(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a revalidation still running when Close is called.
+	c.SetWithExpireIn("stale", "old", time.Second)
+	clock.Advance(time.Second * 2)
+	var reloaded int32
+	c.GetOrLoad("stale", func() (string, error) {
+		time.Sleep(time.Millisecond * 20)
+		atomic.StoreInt32(&reloaded, 1)
+		return "new", nil
+	})
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&reloaded) != 1 {
+		t.Error("expected Close to wait for the background load")
+	}
+	if v, ok := store.get("foo"); !ok || v != "bar" {
+		t.Errorf("expected the pending write to be saved, got %v", v)
+	}
+	if n := atomic.LoadInt32(&flushed); n != 2 {
+		t.Errorf("expected the eviction callback to be called for the 2 items, got %d", n)
+	}
+	restored := New[string](time.Minute, 0, WithSnapshot(path, 0))
+	if v, _ := restored.Get("stale"); v != "new" {
+		t.Errorf("expected a last snapshot to be written, got %v", v)
+	}
+
+	if err := c.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if _, err := c.GetOrLoad("foo", func() (string, error) { return "bar", nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if err := c.DumpTo(&bytes.Buffer{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if err := w.Set(ctx, "baz", "qux"); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("expected ErrWriterClosed, got %v", err)
+	}
+}
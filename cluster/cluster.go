@@ -0,0 +1,313 @@
+// Package cluster spreads keys over a pool of remote cache nodes, such as the clients of the
+// server package, with consistent hashing.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// ErrNoNodes is returned by the operations of a Cluster without nodes.
+var ErrNoNodes = errors.New("cluster: no nodes")
+
+// Option configures a Cluster.
+type Option func(*options)
+
+type options struct {
+	virtualNodes int
+	replication  int
+	rebalanceTTL time.Duration
+}
+
+// WithVirtualNodes sets the number of points of every node on the hash ring, 128 by default.
+// More points spread the keys more evenly.
+func WithVirtualNodes(n int) Option {
+	return func(o *options) {
+		o.virtualNodes = n
+	}
+}
+
+// WithReplication sets the number of nodes storing every key, 1 by default.
+// Reads fall back to the next replica when a node fails.
+func WithReplication(n int) Option {
+	return func(o *options) {
+		o.replication = n
+	}
+}
+
+// WithRebalanceTTL makes Get copy the values it finds on the nodes which owned the key before
+// the last Add or Remove to its current owners, with the time to live ttl, so that keys move
+// to their new nodes as they are read. Without it, they are only read from their former nodes.
+func WithRebalanceTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.rebalanceTTL = ttl
+	}
+}
+
+// Cluster is a cache.RemoteStore spreading keys over the nodes added to it. Every key is owned by
+// the nodes following its hash on a ring, so adding or removing a node only moves the keys it owns.
+// After a change, keys missing from their new owners are looked up on their former ones.
+// A Cluster can be the L2 of a tiered cache, to keep the hot keys in process.
+type Cluster[T any] struct {
+	virtualNodes int
+	replication  int
+	rebalanceTTL time.Duration
+
+	// mu protects the fields below.
+	mu    sync.RWMutex
+	nodes map[string]cache.RemoteStore[T]
+	ring  *ring
+	// previous is the ring before the last change, nil if there was none.
+	previous *ring
+}
+
+var _ cache.RemoteStore[any] = (*Cluster[any])(nil)
+
+// New returns an empty Cluster, see Add.
+func New[T any](opts ...Option) *Cluster[T] {
+	o := options{virtualNodes: 128, replication: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.virtualNodes < 1 {
+		o.virtualNodes = 1
+	}
+	if o.replication < 1 {
+		o.replication = 1
+	}
+	return &Cluster[T]{
+		virtualNodes: o.virtualNodes,
+		replication:  o.replication,
+		rebalanceTTL: o.rebalanceTTL,
+		nodes:        make(map[string]cache.RemoteStore[T]),
+		ring:         &ring{},
+	}
+}
+
+// Add adds the node under the given name, replacing the node of the same name if any.
+// The name places the node on the ring, so it must be the same in every process.
+func (c *Cluster[T]) Add(name string, node cache.RemoteStore[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, replaced := c.nodes[name]
+	c.nodes[name] = node
+	if !replaced {
+		c.rebuild()
+	}
+}
+
+// Remove removes the node of the given name, if any.
+func (c *Cluster[T]) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.nodes[name]; ok {
+		delete(c.nodes, name)
+		c.rebuild()
+	}
+}
+
+// rebuild builds the ring of the nodes. c.mu must be held.
+func (c *Cluster[T]) rebuild() {
+	names := make([]string, 0, len(c.nodes))
+	for name := range c.nodes {
+		names = append(names, name)
+	}
+	c.previous, c.ring = c.ring, newRing(names, c.virtualNodes)
+}
+
+// Nodes returns the names of the nodes, sorted.
+func (c *Cluster[T]) Nodes() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.nodes))
+	for name := range c.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Owners returns the names of the nodes storing the key, the primary one first.
+func (c *Cluster[T]) Owners(key string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring.owners(key, c.replication)
+}
+
+// member is a node with its name.
+type member[T any] struct {
+	name string
+	node cache.RemoteStore[T]
+}
+
+// lookup returns the current owners of the key, and its former owners which are still nodes
+// but no longer own it.
+func (c *Cluster[T]) lookup(key string) (owners, former []member[T]) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	current := c.ring.owners(key, c.replication)
+	for _, name := range current {
+		owners = append(owners, member[T]{name, c.nodes[name]})
+	}
+	if c.previous == nil {
+		return owners, nil
+	}
+	for _, name := range c.previous.owners(key, c.replication) {
+		node, ok := c.nodes[name]
+		if ok && !contains(current, name) {
+			former = append(former, member[T]{name, node})
+		}
+	}
+	return owners, former
+}
+
+// Get returns the value associated with the key from the first of its owners which has it,
+// falling back to the next ones when a node fails, and then to its former owners.
+// It returns an error only if no node has the value and one of them failed.
+func (c *Cluster[T]) Get(ctx context.Context, key string) (value T, exists bool, err error) {
+	owners, former := c.lookup(key)
+	if len(owners) == 0 {
+		return value, false, ErrNoNodes
+	}
+	var lastErr error
+	for _, m := range owners {
+		v, ok, err := m.node.Get(ctx, key)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ok {
+			return v, true, nil
+		}
+	}
+	for _, m := range former {
+		v, ok, err := m.node.Get(ctx, key)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ok {
+			if c.rebalanceTTL > 0 {
+				// a failed copy is retried on the next read.
+				_ = set(ctx, owners, key, v, c.rebalanceTTL)
+			}
+			return v, true, nil
+		}
+	}
+	return value, false, lastErr
+}
+
+// Set stores the value on all the owners of the key for ttl, or without expiration
+// if ttl is less than one. It returns the first error of the nodes.
+func (c *Cluster[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	owners, _ := c.lookup(key)
+	if len(owners) == 0 {
+		return ErrNoNodes
+	}
+	return set(ctx, owners, key, value, ttl)
+}
+
+func set[T any](ctx context.Context, owners []member[T], key string, value T, ttl time.Duration) error {
+	var first error
+	for _, m := range owners {
+		if err := m.node.Set(ctx, key, value, ttl); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Delete removes the value associated with the key from its owners and its former owners,
+// so that it is not read back from them. It returns the first error of the nodes.
+func (c *Cluster[T]) Delete(ctx context.Context, key string) error {
+	owners, former := c.lookup(key)
+	if len(owners) == 0 {
+		return ErrNoNodes
+	}
+	var first error
+	for _, m := range append(owners, former...) {
+		if err := m.node.Delete(ctx, key); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ring is a consistent hash ring.
+type ring struct {
+	// hashes are the sorted points of the nodes, names[i] is the node of hashes[i].
+	hashes []uint64
+	names  []string
+}
+
+func newRing(names []string, virtualNodes int) *ring {
+	type point struct {
+		hash uint64
+		name string
+	}
+	points := make([]point, 0, len(names)*virtualNodes)
+	for _, name := range names {
+		for i := 0; i < virtualNodes; i++ {
+			points = append(points, point{hash(name + "#" + strconv.Itoa(i)), name})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		// ties are broken by name, so that every process builds the same ring.
+		return points[i].name < points[j].name
+	})
+	r := &ring{hashes: make([]uint64, len(points)), names: make([]string, len(points))}
+	for i, p := range points {
+		r.hashes[i], r.names[i] = p.hash, p.name
+	}
+	return r
+}
+
+// owners returns the n distinct nodes following the hash of the key on the ring.
+func (r *ring) owners(key string, n int) []string {
+	if len(r.hashes) == 0 {
+		return nil
+	}
+	h := hash(key)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	var owners []string
+	for i := 0; i < len(r.hashes) && len(owners) < n; i++ {
+		name := r.names[(start+i)%len(r.names)]
+		if !contains(owners, name) {
+			owners = append(owners, name)
+		}
+	}
+	return owners
+}
+
+// hash returns the fnv-64a hash of s, mixed since the hashes of short strings
+// sharing a prefix, such as the points of a node, are close to each other.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// node is an in-memory cache.RemoteStore.
+type node struct {
+	mu     sync.Mutex
+	values map[string]string
+	err    error
+}
+
+func newNode() *node {
+	return &node{values: make(map[string]string)}
+}
+
+func (n *node) Get(_ context.Context, key string) (string, bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return "", false, n.err
+	}
+	v, ok := n.values[key]
+	return v, ok, nil
+}
+
+func (n *node) Set(_ context.Context, key string, value string, _ time.Duration) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.values[key] = value
+	return nil
+}
+
+func (n *node) Delete(_ context.Context, key string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.values, key)
+	return n.err
+}
+
+func (n *node) len() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.values)
+}
+
+func TestCluster(t *testing.T) {
+	ctx := context.Background()
+	c := New[string]()
+	if _, _, err := c.Get(ctx, "foo"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("expected ErrNoNodes, got %v", err)
+	}
+	nodes := map[string]*node{"a": newNode(), "b": newNode(), "c": newNode()}
+	for name, n := range nodes {
+		c.Add(name, n)
+	}
+	for i := 0; i < 300; i++ {
+		key := strconv.Itoa(i)
+		if err := c.Set(ctx, key, key, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, n := range nodes {
+		// 128 virtual nodes spread the keys roughly evenly.
+		if l := n.len(); l < 50 || l > 150 {
+			t.Errorf("expected about 100 keys on %s, got %d", name, l)
+		}
+	}
+	for i := 0; i < 300; i++ {
+		key := strconv.Itoa(i)
+		owner := nodes[c.Owners(key)[0]]
+		if v, ok, _ := owner.Get(ctx, key); !ok || v != key {
+			t.Fatalf("expected %s to be stored on its owner", key)
+		}
+	}
+	c.Delete(ctx, "1")
+	if _, ok, _ := c.Get(ctx, "1"); ok {
+		t.Error("expected 1 to be deleted")
+	}
+}
+
+func TestClusterRebalance(t *testing.T) {
+	ctx := context.Background()
+	c := New[string](WithRebalanceTTL(time.Minute))
+	a, b := newNode(), newNode()
+	c.Add("a", a)
+	for i := 0; i < 100; i++ {
+		c.Set(ctx, strconv.Itoa(i), "v", 0)
+	}
+	c.Add("b", b)
+	moved := 0
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		if _, ok, err := c.Get(ctx, key); !ok || err != nil {
+			t.Fatalf("expected %s to be read from its former owner, got %v", key, err)
+		}
+		if c.Owners(key)[0] == "b" {
+			moved++
+		}
+	}
+	if moved == 0 || b.len() != moved {
+		t.Errorf("expected the %d keys owned by b to be copied to it, got %d", moved, b.len())
+	}
+}
+
+func TestClusterReplication(t *testing.T) {
+	ctx := context.Background()
+	c := New[string](WithReplication(2))
+	nodes := map[string]*node{"a": newNode(), "b": newNode(), "c": newNode()}
+	for name, n := range nodes {
+		c.Add(name, n)
+	}
+	c.Set(ctx, "foo", "bar", 0)
+	owners := c.Owners("foo")
+	if len(owners) != 2 || owners[0] == owners[1] {
+		t.Fatalf("expected 2 distinct owners, got %v", owners)
+	}
+	nodes[owners[0]].err = errors.New("down")
+	if v, ok, err := c.Get(ctx, "foo"); !ok || v != "bar" || err != nil {
+		t.Errorf("expected the replica to serve foo, got %v %v %v", v, ok, err)
+	}
+	nodes[owners[1]].err = errors.New("down")
+	if _, _, err := c.Get(ctx, "foo"); err == nil {
+		t.Error("expected an error when all owners fail")
+	}
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codec converts values to bytes and back, for storing them outside of the process.
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec[T any] struct{}
+
+// Encode returns the JSON encoding of value.
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode parses the JSON encoded data.
+func (JSONCodec[T]) Decode(data []byte) (value T, err error) {
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// GobCodec is a Codec using encoding/gob. Interface values have to be registered with gob.Register.
+type GobCodec[T any] struct{}
+
+// Encode returns the gob encoding of value.
+func (GobCodec[T]) Encode(value T) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(value)
+	return buf.Bytes(), err
+}
+
+// Decode parses the gob encoded data.
+func (GobCodec[T]) Decode(data []byte) (value T, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+// GzipCodec compresses the encoding of Codec with gzip.
+// Level is a compress/gzip level, 0 means gzip.DefaultCompression.
+type GzipCodec[T any] struct {
+	Codec Codec[T]
+	Level int
+}
+
+// Encode returns the compressed encoding of value.
+func (c GzipCodec[T]) Encode(value T) ([]byte, error) {
+	data, err := c.Codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decompresses and parses data.
+func (c GzipCodec[T]) Decode(data []byte) (value T, err error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return value, err
+	}
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return value, err
+	}
+	return c.Codec.Decode(data)
+}
+
+// errCiphertextTooShort is returned when decrypting data shorter than a nonce.
+var errCiphertextTooShort = errors.New("cache: ciphertext too short")
+
+// aesGCMCodec encrypts the encoding of codec with AES-GCM.
+type aesGCMCodec[T any] struct {
+	codec Codec[T]
+	aead  cipher.AEAD
+}
+
+// NewAESGCMCodec returns a Codec encrypting the encoding of codec with AES-GCM and key,
+// which must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+// Every encoding starts with its random nonce. Decoding fails if the data was not encrypted
+// with the same key or was altered. To compress the data too, wrap a GzipCodec, since
+// encrypted data does not compress.
+func NewAESGCMCodec[T any](codec Codec[T], key []byte) (Codec[T], error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCMCodec[T]{codec: codec, aead: aead}, nil
+}
+
+// Encode returns the nonce followed by the encrypted encoding of value.
+func (c aesGCMCodec[T]) Encode(value T) ([]byte, error) {
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(data)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decode decrypts and parses data.
+func (c aesGCMCodec[T]) Decode(data []byte) (value T, err error) {
+	n := c.aead.NonceSize()
+	if len(data) < n {
+		return value, errCiphertextTooShort
+	}
+	data, err = c.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return value, err
+	}
+	return c.codec.Decode(data)
+}
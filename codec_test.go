@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCodecs(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	value := user{"foo", []string{"bar", "baz"}}
+	encrypted, err := NewAESGCMCodec[user](GzipCodec[user]{Codec: GobCodec[user]{}}, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, codec := range map[string]Codec[user]{
+		"gob":       GobCodec[user]{},
+		"json":      JSONCodec[user]{},
+		"gzip":      GzipCodec[user]{Codec: JSONCodec[user]{}},
+		"encrypted": encrypted,
+	} {
+		data, err := codec.Encode(value)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		decoded, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, value) {
+			t.Errorf("%s: expected %v, got %v", name, value, decoded)
+		}
+	}
+}
+
+func TestEncryptedDump(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	codec, err := NewAESGCMCodec[[]DumpItem[string, string]](GzipCodec[[]DumpItem[string, string]]{Codec: GobCodec[[]DumpItem[string, string]]{}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New[string](NoExpiration, 0)
+	c.Set("foo", "secret")
+	c.SetWithExpireIn("bar", "baz", time.Minute)
+	var buf bytes.Buffer
+	if err := c.DumpWith(&buf, codec); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret")) {
+		t.Error("expected the dump to be encrypted")
+	}
+	data := buf.Bytes()
+
+	loaded := New[string](NoExpiration, 0)
+	if err := loaded.LoadWith(bytes.NewReader(data), codec); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := loaded.Get("foo"); v != "secret" {
+		t.Errorf("expected foo to be loaded, got %v", v)
+	}
+
+	other, err := NewAESGCMCodec[[]DumpItem[string, string]](GobCodec[[]DumpItem[string, string]]{}, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New[string](NoExpiration, 0).LoadWith(bytes.NewReader(data), other); err == nil {
+		t.Error("expected loading with another key to fail")
+	}
+	data[len(data)-1] ^= 1
+	if err := New[string](NoExpiration, 0).LoadWith(bytes.NewReader(data), codec); err == nil {
+		t.Error("expected loading altered data to fail")
+	}
+	if _, err := NewAESGCMCodec[string](JSONCodec[string]{}, []byte("short")); err == nil {
+		t.Error("expected an invalid key size to fail")
+	}
+}
+
+func TestDumpCodec(t *testing.T) {
+	type dump = []DumpItem[string, string]
+	codec, err := NewAESGCMCodec[dump](GzipCodec[dump]{Codec: GobCodec[dump]{}}, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	c := New[string](NoExpiration, 0, WithDumpCodec("gzip+aes", codec), WithSnapshot(path, 0))
+	c.Set("foo", "secret")
+	if err := c.SaveSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Error("expected the snapshot to be encrypted")
+	}
+	restored := New[string](NoExpiration, 0, WithDumpCodec("gzip+aes", codec), WithSnapshot(path, 0))
+	if v, _ := restored.Get("foo"); v != "secret" {
+		t.Errorf("expected foo to be restored from the encrypted snapshot, got %v", v)
+	}
+
+	var incompatible *IncompatibleDumpError
+	err = New[string](NoExpiration, 0).LoadFrom(bytes.NewReader(data))
+	if !errors.As(err, &incompatible) || incompatible.Header.Codec != "gzip+aes" {
+		t.Errorf("expected a dump of another codec to be incompatible, got %v", err)
+	}
+	var buf bytes.Buffer
+	New[string](NoExpiration, 0).DumpTo(&buf)
+	if err := restored.LoadFrom(&buf); !errors.As(err, &incompatible) || incompatible.Want.Codec != "gzip+aes" {
+		t.Errorf("expected a dump without the codec to be incompatible, got %v", err)
+	}
+}
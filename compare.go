@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CompareAndSwap swaps the value of the item associated with the key for new
+// if its current value is equal to old, keeping its expiration.
+// The old value must be of a comparable type, like for sync.Map.CompareAndSwap.
+func (c *cache[K, V]) CompareAndSwap(key K, old, new V) bool {
+	return c.CompareAndSwapFunc(key, old, new, equal[V])
+}
+
+// CompareAndSwapFunc is like CompareAndSwap, but compares values with equal.
+func (c *cache[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) bool {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.Lock()
+	e, ok := s.get(key, c.now())
+	if !ok || !equal(e.value, old) {
+		s.mu.Unlock()
+		return false
+	}
+	evicted := s.replace(key, e, new, nil)
+	s.mu.Unlock()
+	c.report(evicted)
+	c.changed(key)
+	return true
+}
+
+// CompareAndDelete removes the item associated with the key if its current value is equal to old.
+// The old value must be of a comparable type, like for sync.Map.CompareAndDelete.
+func (c *cache[K, V]) CompareAndDelete(key K, old V) bool {
+	return c.CompareAndDeleteFunc(key, old, equal[V])
+}
+
+// CompareAndDeleteFunc is like CompareAndDelete, but compares values with equal.
+func (c *cache[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) bool {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.Lock()
+	e, ok := s.get(key, c.now())
+	if !ok || !equal(e.value, old) {
+		s.mu.Unlock()
+		return false
+	}
+	evicted := s.evict(key, e, EvictionReasonDeleted, nil)
+	s.mu.Unlock()
+	c.report(evicted)
+	c.changed(key)
+	return true
+}
+
+// equal compares a and b with ==, it panics if they are not comparable.
+func equal[V any](a, b V) bool {
+	return any(a) == any(b)
+}
+
+// Update atomically replaces the value of the item associated with the key by the result of fn.
+// fn receives the current value and whether the item exists, and returns the new value and
+// whether to store it. An existing item keeps its expiration, a new one gets the default expiration.
+// Update returns the value in the cache after the call and whether there is one.
+// fn is called with the cache locked and must not use the cache.
+func (c *cache[K, V]) Update(key K, fn func(current V, exists bool) (V, bool)) (V, bool) {
+	return c.update(key, DefaultExpiration, true, fn)
+}
+
+// update is Update storing a new item with expireIn. An existing item keeps its expiration
+// if keep is set, and gets expireIn otherwise.
+func (c *cache[K, V]) update(key K, expireIn time.Duration, keep bool, fn func(current V, exists bool) (V, bool)) (V, bool) {
+	key = c.normalize(key)
+	now := c.now()
+	s := c.shard(key)
+	s.mu.Lock()
+	var current V
+	e, exists := s.get(key, now)
+	if exists {
+		current = e.value
+	}
+	v, ok := fn(current, exists)
+	if !ok {
+		s.mu.Unlock()
+		return current, exists
+	}
+	var evicted []eviction[K, V]
+	if exists && keep {
+		evicted = s.replace(key, e, v, nil)
+	} else {
+		evicted = s.set(key, v, c.expirationOf(v, expireIn), nil)
+	}
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+	return v, true
+}
+
+// Pop removes the item associated with the key and returns its value.
+func (c *cache[K, V]) Pop(key K) (value V, exists bool) {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.Lock()
+	var evicted []eviction[K, V]
+	e, ok := s.get(key, c.now())
+	if ok {
+		value = e.value
+		evicted = s.evict(key, e, EvictionReasonDeleted, nil)
+	}
+	s.mu.Unlock()
+	c.stats.hit(ok)
+	if !ok {
+		return
+	}
+	c.report(evicted)
+	c.changed(key)
+	return value, true
+}
+
+// Swap stores new for the key like Set, and returns the previous value if any.
+func (c *cache[K, V]) Swap(key K, new V) (old V, existed bool) {
+	key = c.normalize(key)
+	expiration := c.expirationOf(new, DefaultExpiration)
+	s := c.shard(key)
+	s.mu.Lock()
+	if e, ok := s.get(key, c.now()); ok {
+		old, existed = e.value, true
+	}
+	evicted := s.set(key, new, expiration, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+	return old, existed
+}
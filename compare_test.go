@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.Set("a", 1)
+	if c.CompareAndSwap("a", 2, 3) {
+		t.Errorf("expected a to not be swapped")
+	}
+	if !c.CompareAndSwap("a", 1, 3) {
+		t.Errorf("expected a to be swapped")
+	}
+	if c.CompareAndSwap("b", 0, 1) {
+		t.Errorf("expected b to not be swapped")
+	}
+	if c.CompareAndDelete("a", 1) {
+		t.Errorf("expected a to not be deleted")
+	}
+	if !c.CompareAndDelete("a", 3) {
+		t.Errorf("expected a to be deleted")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to not exist")
+	}
+}
+
+func TestCompareAndSwapFunc(t *testing.T) {
+	c := New[[]byte](NoExpiration, 0)
+	c.Set("a", []byte("foo"))
+	if !c.CompareAndSwapFunc("a", []byte("foo"), []byte("bar"), bytes.Equal) {
+		t.Errorf("expected a to be swapped")
+	}
+	if !c.CompareAndDeleteFunc("a", []byte("bar"), bytes.Equal) {
+		t.Errorf("expected a to be deleted")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	c := NewSharded[[]int](4, NoExpiration, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Update("a", func(current []int, _ bool) ([]int, bool) {
+				return append(current, i), true
+			})
+		}(i)
+	}
+	wg.Wait()
+	if v, _ := c.Get("a"); len(v) != 10 {
+		t.Errorf("expected 10 values, got %v", v)
+	}
+	if v, ok := c.Update("b", func([]int, bool) ([]int, bool) { return nil, false }); ok || v != nil {
+		t.Errorf("expected b to not be stored, got %v", v)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to not exist")
+	}
+}
+
+func TestPop(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	c.Set("token", "foo")
+	var wg sync.WaitGroup
+	var popped int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := c.Pop("token"); ok {
+				atomic.AddInt32(&popped, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if popped != 1 {
+		t.Errorf("expected token to be popped once, got %d", popped)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	if old, existed := c.Swap("a", 1); existed || old != 0 {
+		t.Errorf("expected a to not exist, got %v", old)
+	}
+	if old, existed := c.Swap("a", 2); !existed || old != 1 {
+		t.Errorf("expected a to be 1, got %v", old)
+	}
+	if v, _ := c.Get("a"); v != 2 {
+		t.Errorf("expected a to be 2, got %v", v)
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetCtx is like Get, but returns the context error without reading if ctx is already done.
+func (c *cache[K, V]) GetCtx(ctx context.Context, key K) (result V, exists bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if err = c.isClosed(); err != nil {
+		return
+	}
+	result, exists = c.Get(key)
+	return result, exists, nil
+}
+
+// SetCtx is like Set, but returns the context error without writing if ctx is already done.
+func (c *cache[K, V]) SetCtx(ctx context.Context, key K, value V) error {
+	return c.SetWithExpireInCtx(ctx, key, value, DefaultExpiration)
+}
+
+// SetWithExpireInCtx is like SetWithExpireIn, but returns the context error without writing if ctx is already done.
+func (c *cache[K, V]) SetWithExpireInCtx(ctx context.Context, key K, value V, expireIn time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	c.SetWithExpireIn(key, value, expireIn)
+	return nil
+}
+
+// GetOrLoadCtx is like GetOrLoad, but passes ctx to loader. Callers waiting on a load started by
+// another caller return the context error as soon as their own ctx is done, the load itself
+// is only cancelled with the context of the caller which started it.
+func (c *cache[K, V]) GetOrLoadCtx(ctx context.Context, key K, loader func(context.Context) (V, error)) (V, error) {
+	return c.GetOrLoadWithExpireInCtx(ctx, key, loader, DefaultExpiration)
+}
+
+// GetOrLoadWithExpireInCtx is like GetOrLoadCtx, but stores the loaded value with the given expiration.
+func (c *cache[K, V]) GetOrLoadWithExpireInCtx(ctx context.Context, key K, loader func(context.Context) (V, error), expireIn time.Duration) (V, error) {
+	return c.getOrLoadCtx(ctx, key, func(ctx context.Context) (V, time.Duration, error) {
+		v, err := loader(ctx)
+		return v, expireIn, err
+	})
+}
+
+// GetOrLoadWithTTLCtx is like GetOrLoadWithTTL, but passes ctx to loader, see GetOrLoadCtx.
+func (c *cache[K, V]) GetOrLoadWithTTLCtx(ctx context.Context, key K, loader func(context.Context) (V, time.Duration, error)) (V, error) {
+	return c.getOrLoadCtx(ctx, key, loader)
+}
+
+// getOrLoadCtx is GetOrLoadCtx with a loader returning the expiration of the value it loaded.
+func (c *cache[K, V]) getOrLoadCtx(ctx context.Context, key K, loader func(context.Context) (V, time.Duration, error)) (V, error) {
+	if err := c.isClosed(); err != nil {
+		var zero V
+		return zero, err
+	}
+	key = c.normalize(key)
+	v, ok, early := c.read(key)
+	c.stats.hit(ok)
+	if ok {
+		if early {
+			return c.clone(c.loadEarly(key, v, c.loadFunc(key, bind(ctx, loader), false))), nil
+		}
+		return c.clone(v), nil
+	}
+	if err := ctx.Err(); err != nil {
+		var zero V
+		return zero, err
+	}
+	if v, ok := c.lookupStale(key); ok {
+		// the refresh outlives the call, it must not be cancelled with it.
+		c.loads.doAsync(key, c.loadFunc(key, bind[V](detachedContext{ctx}, loader), true))
+		return c.clone(v), nil
+	}
+	if err := c.failed(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	v, err := c.loads.doCtx(ctx, key, c.loadFunc(key, bind(ctx, loader), true))
+	if err != nil {
+		return v, err
+	}
+	return c.clone(v), nil
+}
+
+// bind returns a loader calling loader with ctx.
+func bind[V any](ctx context.Context, loader func(context.Context) (V, time.Duration, error)) func() (V, time.Duration, error) {
+	return func() (V, time.Duration, error) {
+		return loader(ctx)
+	}
+}
+
+// detachedContext carries the values of its parent, but is never cancelled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadCtx(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go c.GetOrLoadCtx(context.Background(), "foo", func(context.Context) (string, error) {
+		close(started)
+		<-release
+		return "bar", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := c.GetOrLoadCtx(ctx, "foo", func(context.Context) (string, error) {
+		t.Error("expected loader to be deduplicated")
+		return "", nil
+	}); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	close(release)
+
+	if _, err := c.GetOrLoadCtx(ctx, "baz", func(ctx context.Context) (string, error) {
+		return "", nil
+	}); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if err := c.SetCtx(ctx, "baz", "qux"); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import "fmt"
+
+// Cloner is implemented by values which can copy themselves. The reads of a cache of
+// Cloner values return clones, unless the cache was created WithCopier.
+type Cloner[T any] interface {
+	Clone() T
+}
+
+// WithCopier makes Get, GetWithExpiration, GetMany, GetOrLoad, GetOrLoadCtx, Range, Items and All
+// return copy(value) instead of the cached value, so that callers of caches of pointers, slices
+// or maps cannot modify the cached values by mistake. Values passed to Set are stored as they are.
+// Its value type must match the one of the cache, or the constructor panics.
+func WithCopier[V any](copy func(value V) V) Option {
+	return func(o *options) {
+		o.copier = copy
+	}
+}
+
+// newCopier returns the WithCopier function, the Clone method of V if V implements Cloner[V],
+// and nil otherwise.
+func newCopier[V any](o *options) func(V) V {
+	if o.copier != nil {
+		copier, ok := o.copier.(func(V) V)
+		if !ok {
+			panic(fmt.Sprintf("cache: WithCopier function %T does not match cache of %T values", o.copier, *new(V)))
+		}
+		return copier
+	}
+	if _, ok := any(*new(V)).(Cloner[V]); ok {
+		return func(v V) V {
+			return any(v).(Cloner[V]).Clone()
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of v made by the copier, or v if there is none.
+func (c *cache[K, V]) clone(v V) V {
+	if c.copier == nil {
+		return v
+	}
+	return c.copier(v)
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+type profile struct {
+	Name string
+	Tags []string
+}
+
+func (p *profile) Clone() *profile {
+	c := *p
+	c.Tags = append([]string(nil), p.Tags...)
+	return &c
+}
+
+func TestWithCopier(t *testing.T) {
+	c := New[[]int](NoExpiration, 0, WithCopier(func(v []int) []int {
+		return append([]int(nil), v...)
+	}))
+	c.Set("foo", []int{1, 2})
+	reads := map[string]func() []int{
+		"Get": func() []int { v, _ := c.Get("foo"); return v },
+		"GetWithExpiration": func() []int {
+			v, _, _ := c.GetWithExpiration("foo")
+			return v
+		},
+		"GetMany": func() []int { return c.GetMany([]string{"foo"})["foo"] },
+		"GetOrLoad": func() []int {
+			v, _ := c.GetOrLoad("foo", func() ([]int, error) { return nil, nil })
+			return v
+		},
+		"GetOrLoadCtx": func() []int {
+			v, _ := c.GetOrLoadCtx(context.Background(), "foo", func(context.Context) ([]int, error) { return nil, nil })
+			return v
+		},
+		"Items": func() []int { return c.Items()["foo"].Value },
+		"Range": func() (v []int) {
+			c.Range(func(_ string, value []int) bool { v = value; return false })
+			return v
+		},
+	}
+	for name, read := range reads {
+		read()[0] = 42
+		if v := read(); v[0] != 1 {
+			t.Errorf("expected %s to return a copy, got %v", name, v)
+		}
+	}
+
+	v, _ := c.GetOrLoad("bar", func() ([]int, error) { return []int{1}, nil })
+	v[0] = 42
+	if v, _ := c.Get("bar"); v[0] != 1 {
+		t.Errorf("expected GetOrLoad to return a copy of the loaded value, got %v", v)
+	}
+}
+
+func TestCloner(t *testing.T) {
+	c := New[*profile](NoExpiration, 0)
+	c.Set("foo", &profile{Name: "foo", Tags: []string{"a"}})
+	p, _ := c.Get("foo")
+	p.Name, p.Tags[0] = "bar", "b"
+	if p, _ := c.Get("foo"); p.Name != "foo" || p.Tags[0] != "a" {
+		t.Errorf("expected Get to return a clone, got %+v", p)
+	}
+	if p, ok := c.Get("bar"); ok || p != nil {
+		t.Errorf("expected a miss not to be cloned, got %v", p)
+	}
+}
+
+func TestWithCopierMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a mismatched copier to panic")
+		}
+	}()
+	New[[]int](NoExpiration, 0, WithCopier(func(v []string) []string { return v }))
+}
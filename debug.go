@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"strconv"
+)
+
+// debugHotKeys is the number of hot keys reported by default, see DebugInfo.
+const debugHotKeys = 10
+
+// DebugInfo is the state of a cache published by Publish and served by DebugHandler.
+type DebugInfo struct {
+	Stats    Stats
+	HitRatio float64
+	// Items may include items that have expired, but have not yet been cleaned up.
+	Items int
+	// HotKeys is empty without WithHotKeys.
+	HotKeys    []KeyStat[string]        `json:",omitempty"`
+	Namespaces map[string]NamespaceInfo `json:",omitempty"`
+}
+
+// NamespaceInfo is the state of a namespace in a DebugInfo.
+type NamespaceInfo struct {
+	Stats Stats
+	Items int
+}
+
+// DebugInfo returns the state of the cache, with its n hottest keys.
+// Counting the items of the namespaces reads all the keys of the cache.
+func (g *GenericCache[T]) DebugInfo(n int) DebugInfo {
+	stats := g.Stats()
+	info := DebugInfo{
+		Stats:    stats,
+		HitRatio: stats.HitRatio(),
+		Items:    g.ItemCount(),
+		HotKeys:  g.HotKeys(n),
+	}
+	g.mu.Lock()
+	namespaces := make([]*Namespace[T], 0, len(g.namespaces))
+	for _, ns := range g.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	g.mu.Unlock()
+	if len(namespaces) > 0 {
+		info.Namespaces = make(map[string]NamespaceInfo, len(namespaces))
+	}
+	for _, ns := range namespaces {
+		info.Namespaces[ns.Name()] = NamespaceInfo{Stats: ns.Stats(), Items: len(ns.Keys())}
+	}
+	return info
+}
+
+// Publish publishes the DebugInfo of the cache as the expvar variable name, served by the
+// /debug/vars handler of expvar. Like expvar.Publish, it panics if the name is already in use.
+func (g *GenericCache[T]) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return g.DebugInfo(debugHotKeys)
+	}))
+}
+
+// DebugHandler returns an http.Handler serving the DebugInfo of the cache as JSON,
+// typically mounted at /debug/cache. The hotkeys query parameter sets the number of hot keys.
+func (g *GenericCache[T]) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := debugHotKeys
+		if s := r.URL.Query().Get("hotkeys"); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil || v < 0 {
+				http.Error(w, "cache: invalid hotkeys parameter", http.StatusBadRequest)
+				return
+			}
+			n = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(g.DebugInfo(n))
+	})
+}
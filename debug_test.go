@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	c := New[string](NoExpiration, 0, WithHotKeys(time.Minute, 1))
+	c.Set("foo", "bar")
+	c.Get("foo")
+	c.Get("foo")
+	c.Get("baz")
+	users := c.Namespace("users")
+	users.Set("1", "foo")
+	users.Get("1")
+
+	rec := httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache?hotkeys=1", nil))
+	var info DebugInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Items != 2 || info.Stats.Hits != 3 || info.Stats.Misses != 1 || info.HitRatio != 0.75 {
+		t.Errorf("expected 2 items and 3 hits out of 4 reads, got %+v", info)
+	}
+	if len(info.HotKeys) != 1 || info.HotKeys[0].Key != "foo" {
+		t.Errorf("expected foo to be the hot key, got %v", info.HotKeys)
+	}
+	if ns := info.Namespaces["users"]; ns.Items != 1 || ns.Stats.Hits != 1 {
+		t.Errorf("expected 1 item and 1 hit in users, got %+v", ns)
+	}
+
+	rec = httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache?hotkeys=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid parameter to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	c.Set("foo", "bar")
+	c.Publish("TestPublish")
+	var info DebugInfo
+	if err := json.Unmarshal([]byte(expvar.Get("TestPublish").String()), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Items != 1 {
+		t.Errorf("expected 1 item, got %d", info.Items)
+	}
+}
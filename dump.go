@@ -0,0 +1,287 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+// DumpItem is the serialized form of an item. Expiration is in unix nanoseconds, 0 if the item never expires.
+type DumpItem[K comparable, V any] struct {
+	Key        K     `json:"key"`
+	Value      V     `json:"value"`
+	Expiration int64 `json:"expiration,omitempty"`
+}
+
+// DumpWith writes the items of the cache which have not expired to the given writer,
+// as a slice of DumpItem encoded with codec.
+func (c *cache[K, V]) DumpWith(writer io.Writer, codec Codec[[]DumpItem[K, V]]) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	data, err := codec.Encode(c.dump())
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// LoadWith loads the items written by DumpWith with the same codec from the given reader.
+// Items whose keys already exist in the cache are skipped.
+func (c *cache[K, V]) LoadWith(reader io.Reader, codec Codec[[]DumpItem[K, V]]) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	items, err := codec.Decode(data)
+	if err != nil {
+		return err
+	}
+	c.restore(items)
+	return nil
+}
+
+// dumpVersion is the version of the DumpTo format, written in its header.
+const dumpVersion = 1
+
+// dumpMagic starts the output of DumpTo, dumps without it were written before the header was added.
+var dumpMagic = []byte("cachedump\x00")
+
+// DumpHeader describes the content of a dump written by DumpTo.
+type DumpHeader struct {
+	// Version is the version of the format, 0 for dumps written before headers were added.
+	Version int
+	// Key and Value are the names of the key and value types of the dumped cache.
+	Key, Value string
+	// Count is the number of dumped items.
+	Count int
+	// Codec is the name of the WithDumpCodec codec of the payload, empty for GobCodec.
+	Codec string
+}
+
+// IncompatibleDumpError is returned by LoadFrom when the dump was written by a cache of other
+// key or value types, with another WithDumpCodec codec or another version of the format,
+// see WithMigration.
+type IncompatibleDumpError struct {
+	Header DumpHeader // the header of the dump
+	Want   DumpHeader // the header DumpTo writes, without Count
+}
+
+func (e *IncompatibleDumpError) Error() string {
+	if e.Header.Codec != e.Want.Codec {
+		return fmt.Sprintf("cache: incompatible dump encoded with codec %q, want codec %q", e.Header.Codec, e.Want.Codec)
+	}
+	return fmt.Sprintf("cache: incompatible dump of version %d with %s keys and %s values, want version %d with %s keys and %s values",
+		e.Header.Version, e.Header.Key, e.Header.Value, e.Want.Version, e.Want.Key, e.Want.Value)
+}
+
+// WithMigration sets the function converting the dumps LoadFrom, and so WithSnapshot, cannot load
+// because their header does not match the cache, instead of returning an IncompatibleDumpError.
+// The payload is the encoding of the dumped items with the codec named in the header, GobCodec
+// if it is empty. Dumps without a header are given to migrate with a header of Version 0 and
+// empty type names.
+// Its key and value types must match the ones of the cache, or the constructor panics.
+func WithMigration[K comparable, V any](migrate func(header DumpHeader, payload []byte) ([]DumpItem[K, V], error)) Option {
+	return func(o *options) {
+		o.migrate = migrate
+	}
+}
+
+// WithDumpCodec sets the codec of the dumps written by DumpTo, and so by WithSnapshot, instead of
+// GobCodec, for example to compress or encrypt them. name is written in the header of the dumps,
+// and LoadFrom only decodes the dumps written with a codec of the same name, returning an
+// IncompatibleDumpError for the others, see WithMigration. The dumps written before are still
+// loaded by a cache without WithDumpCodec.
+// Its key and value types must match the ones of the cache, or the constructor panics.
+func WithDumpCodec[K comparable, V any](name string, codec Codec[[]DumpItem[K, V]]) Option {
+	return func(o *options) {
+		o.dumpCodec, o.dumpCodecName = codec, name
+	}
+}
+
+// DumpTo dumps the cache to the given writer with GobCodec, or the WithDumpCodec codec,
+// after a DumpHeader.
+func (c *cache[K, V]) DumpTo(writer io.Writer) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.dumpTo(writer)
+}
+
+// dumpTo is DumpTo, also called by Close to write the last snapshot.
+func (c *cache[K, V]) dumpTo(writer io.Writer) error {
+	items := c.dump()
+	data, err := c.codec().Encode(items)
+	if err != nil {
+		return err
+	}
+	header := c.dumpHeader()
+	header.Count = len(items)
+	var buf bytes.Buffer
+	buf.Write(dumpMagic)
+	if err := gob.NewEncoder(&buf).Encode(header); err != nil {
+		return err
+	}
+	buf.Write(data)
+	_, err = buf.WriteTo(writer)
+	return err
+}
+
+// LoadFrom loads the cache dumped by DumpTo from the given reader.
+// Items whose keys already exist in the cache are skipped.
+// It returns an IncompatibleDumpError if the dump does not match the cache, see WithMigration.
+func (c *cache[K, V]) LoadFrom(reader io.Reader) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var header DumpHeader
+	if bytes.HasPrefix(data, dumpMagic) {
+		// bytes.Reader is an io.ByteReader, so the decoder does not read past the header.
+		r := bytes.NewReader(data[len(dumpMagic):])
+		if err := gob.NewDecoder(r).Decode(&header); err != nil {
+			return fmt.Errorf("cache: decode dump header: %w", err)
+		}
+		data = data[len(data)-r.Len():]
+	}
+	var items []DumpItem[K, V]
+	want := c.dumpHeader()
+	switch {
+	case header.Version == want.Version && header.Key == want.Key && header.Value == want.Value && header.Codec == want.Codec:
+		if items, err = c.codec().Decode(data); err != nil {
+			return err
+		}
+		if len(items) != header.Count {
+			return fmt.Errorf("cache: dump has %d items, its header %d", len(items), header.Count)
+		}
+	case header.Version == 0 && c.migrate == nil:
+		// dumps without a header were encoded with GobCodec, or by go-cache before this package
+		// stored items itself.
+		if items, err = (GobCodec[[]DumpItem[K, V]]{}).Decode(data); err != nil {
+			if items, err = decodeGoCache[K, V](data); err != nil {
+				return err
+			}
+		}
+	case c.migrate != nil:
+		if items, err = c.migrate(header, data); err != nil {
+			return fmt.Errorf("cache: migrate dump of version %d: %w", header.Version, err)
+		}
+	default:
+		return &IncompatibleDumpError{Header: header, Want: want}
+	}
+	c.restore(items)
+	return nil
+}
+
+// dumpHeader returns the header of the dumps of c, without Count.
+func (c *cache[K, V]) dumpHeader() DumpHeader {
+	return DumpHeader{
+		Version: dumpVersion,
+		Key:     reflect.TypeOf((*K)(nil)).Elem().String(),
+		Value:   reflect.TypeOf((*V)(nil)).Elem().String(),
+		Codec:   c.dumpCodecName,
+	}
+}
+
+// goCacheItem is the item of a dump written by go-cache, which maps string keys to items.
+type goCacheItem struct {
+	Object     interface{}
+	Expiration int64
+}
+
+// decodeGoCache decodes a dump written by go-cache. Its values are gob interface values, so the
+// concrete value type is registered the way go-cache registered it when dumping.
+func decodeGoCache[K comparable, V any](data []byte) ([]DumpItem[K, V], error) {
+	var zero V
+	if reflect.TypeOf(zero) != nil {
+		gob.Register(zero)
+	}
+	var dumped map[string]goCacheItem
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dumped); err != nil {
+		return nil, err
+	}
+	items := make([]DumpItem[K, V], 0, len(dumped))
+	for key, item := range dumped {
+		k, ok := any(key).(K)
+		if !ok {
+			return nil, fmt.Errorf("cache: go-cache dump has string keys, not %v", reflect.TypeOf((*K)(nil)).Elem())
+		}
+		v, ok := item.Object.(V)
+		if !ok && item.Object != nil {
+			return nil, fmt.Errorf("cache: go-cache dump has a %T value for key %q", item.Object, key)
+		}
+		items = append(items, DumpItem[K, V]{Key: k, Value: v, Expiration: item.Expiration})
+	}
+	return items, nil
+}
+
+// codec returns the codec of the dumps of c.
+func (c *cache[K, V]) codec() Codec[[]DumpItem[K, V]] {
+	if c.dumpCodec == nil {
+		return GobCodec[[]DumpItem[K, V]]{}
+	}
+	return c.dumpCodec
+}
+
+// DumpJSON dumps the cache to the given writer with JSONCodec, as an array of objects
+// with key, value and expiration fields. Unlike DumpTo, it does not require interface
+// values to be registered with gob.
+func (c *cache[K, V]) DumpJSON(writer io.Writer) error {
+	return c.DumpWith(writer, JSONCodec[[]DumpItem[K, V]]{})
+}
+
+// LoadJSON loads the cache dumped by DumpJSON from the given reader.
+// Items whose keys already exist in the cache are skipped.
+func (c *cache[K, V]) LoadJSON(reader io.Reader) error {
+	return c.LoadWith(reader, JSONCodec[[]DumpItem[K, V]]{})
+}
+
+// dump returns the live items of the cache.
+func (c *cache[K, V]) dump() []DumpItem[K, V] {
+	now := c.now()
+	var items []DumpItem[K, V]
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for key, e := range s.items {
+			if !s.expired(e, now) {
+				items = append(items, DumpItem[K, V]{Key: key, Value: e.value, Expiration: e.expiration})
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return items
+}
+
+// restore adds the dumped items to the cache, skipping the keys which already exist.
+func (c *cache[K, V]) restore(items []DumpItem[K, V]) {
+	byKey := make(map[K]DumpItem[K, V], len(items))
+	for _, item := range items {
+		byKey[item.Key] = item
+	}
+	var evicted []eviction[K, V]
+	now := c.now()
+	for i, keys := range c.partition(mapKeys(byKey)) {
+		s := c.shards[i]
+		s.mu.Lock()
+		for _, key := range keys {
+			if e, ok := s.items[key]; ok && !s.expired(e, now) {
+				continue
+			}
+			item := byKey[key]
+			evicted = s.set(key, item.Value, item.Expiration, evicted)
+			atomic.AddUint64(&c.stats.sets, 1)
+		}
+		s.mu.Unlock()
+	}
+	c.report(evicted)
+}
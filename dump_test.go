@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpJSON(t *testing.T) {
+	type point struct{ X, Y int }
+	type shape struct {
+		Name   string
+		Points []point
+	}
+	c := NewKeyed[point, shape](NoExpiration, 0)
+	c.Set(point{1, 2}, shape{"line", []point{{0, 0}, {1, 1}}})
+	c.SetWithExpireIn(point{3, 4}, shape{Name: "dot"}, time.Minute)
+	var buf bytes.Buffer
+	if err := c.DumpJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewKeyed[point, shape](NoExpiration, 0)
+	loaded.Set(point{3, 4}, shape{Name: "square"})
+	if err := loaded.LoadJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := loaded.Get(point{1, 2}); v.Name != "line" || len(v.Points) != 2 {
+		t.Errorf("expected the line to be loaded, got %v", v)
+	}
+	if v, _ := loaded.Get(point{3, 4}); v.Name != "square" {
+		t.Errorf("expected the existing square to be kept, got %v", v)
+	}
+
+	buf.Reset()
+	c.DumpJSON(&buf)
+	expiring := NewKeyed[point, shape](NoExpiration, 0)
+	expiring.LoadJSON(&buf)
+	if ttl, _ := expiring.TTL(point{3, 4}); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the expiration to be kept, got %v", ttl)
+	}
+
+	if err := loaded.LoadJSON(strings.NewReader(`[{"key":{"X":1,"Y":2},"value":"line"}]`)); err == nil {
+		t.Error("expected a mismatched value type to fail")
+	}
+}
+
+func TestDumpHeader(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.Set("foo", 1)
+	c.Set("bar", 2)
+	var buf bytes.Buffer
+	if err := c.DumpTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	var incompatible *IncompatibleDumpError
+	err := New[string](NoExpiration, 0).LoadFrom(bytes.NewReader(data))
+	if !errors.As(err, &incompatible) {
+		t.Fatalf("expected an IncompatibleDumpError, got %v", err)
+	}
+	if h := incompatible.Header; h.Version != 1 || h.Key != "string" || h.Value != "int" || h.Count != 2 {
+		t.Errorf("expected the header of the dump, got %+v", h)
+	}
+
+	migrated := New[string](NoExpiration, 0, WithMigration(func(header DumpHeader, payload []byte) ([]DumpItem[string, string], error) {
+		items, err := GobCodec[[]DumpItem[string, int]]{}.Decode(payload)
+		converted := make([]DumpItem[string, string], len(items))
+		for i, item := range items {
+			converted[i] = DumpItem[string, string]{Key: item.Key, Value: strconv.Itoa(item.Value), Expiration: item.Expiration}
+		}
+		return converted, err
+	}))
+	if err := migrated.LoadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := migrated.Get("bar"); v != "2" {
+		t.Errorf("expected bar to be migrated to 2, got %v", v)
+	}
+
+	// dumps written before the header was added are still loaded.
+	var legacy bytes.Buffer
+	if err := c.DumpWith(&legacy, GobCodec[[]DumpItem[string, int]]{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New[int](NoExpiration, 0)
+	if err := loaded.LoadFrom(&legacy); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := loaded.Get("foo"); v != 1 {
+		t.Errorf("expected foo to be loaded from the legacy dump, got %v", v)
+	}
+}
+
+func TestLoadGoCacheDump(t *testing.T) {
+	// testdata/gocache.gob was written by DumpTo when the cache wrapped go-cache, holding a and b.
+	f, err := os.Open("testdata/gocache.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c := New[string](NoExpiration, 0)
+	if err := c.LoadFrom(f); err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := c.Get("a"); a != "1" {
+		t.Errorf("expected a to be 1, got %q", a)
+	}
+	if b, _ := c.Get("b"); b != "2" {
+		t.Errorf("expected b to be 2, got %q", b)
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestRecycledEntries(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[[]int](time.Minute, 0, WithClock(clock), WithMaxEntries(1))
+	var evicted [][]int
+	c.OnEvicted(func(_ string, v []int, _ EvictionReason) {
+		evicted = append(evicted, v)
+	})
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		key := string(rune('a' + i))
+		c.SetWithTags(key, []int{i}, time.Duration(i+1)*time.Minute, key)
+		if i%2 == 0 {
+			c.Get(key)
+		}
+		info, ok := c.Inspect(key)
+		if !ok || info.Hits != int64(1-i%2) || len(info.Tags) != 1 || info.Tags[0] != key || !info.Created.Equal(clock.Now()) {
+			t.Errorf("expected %s to be a fresh item, got %+v", key, info)
+		}
+	}
+	for i, v := range evicted {
+		if len(v) != 1 || v[0] != i {
+			t.Errorf("expected evicted value %d, got %v", i, v)
+		}
+	}
+
+	c = New[[]int](time.Minute, 0, WithClock(clock))
+	c.Set("a", []int{1})
+	clock.Advance(time.Minute)
+	c.DeleteExpired()
+	c.SetWithExpireIn("b", []int{2}, NoExpiration)
+	if _, expiration, ok := c.GetWithExpiration("b"); !ok || !expiration.IsZero() {
+		t.Errorf("expected b to never expire, got %v", expiration)
+	}
+}
+
+func TestRecycledEntriesRemovals(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	s := c.shards[0]
+	var allocs int
+	s.entries.New = func() any {
+		allocs++
+		return new(entry[int])
+	}
+	removals := []func(key string){
+		func(key string) { c.Delete(key) },
+		func(key string) { c.Pop(key) },
+		func(key string) { c.CompareAndDelete(key, 1) },
+		func(key string) { c.DeleteMany([]string{key}) },
+		func(key string) { c.InvalidateTag(key) },
+		func(string) { c.Flush() },
+	}
+	const rounds = 100
+	for i := 0; i < rounds; i++ {
+		for _, remove := range removals {
+			c.SetWithTags("foo", 1, DefaultExpiration, "foo")
+			e := s.items["foo"]
+			remove("foo")
+			if e.tags != nil || e.value != 0 {
+				t.Fatalf("expected the entry of the removed item to be released, got %+v", e)
+			}
+		}
+	}
+	// the pool drops some of the entries put back, at random with the race detector.
+	if n := rounds * len(removals); allocs > n/2 {
+		t.Errorf("expected the entries to be reused, got %d allocations for %d items", allocs, n)
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import "sync/atomic"
+
+// NewEpoch removes all items from the cache but the pinned ones in constant time, unlike Flush
+// which goes through all of them: the items stored before the new epoch are treated as expired
+// from then on. They are removed lazily, when their keys are stored again, when they are evicted
+// to make room, or by the next cleanup of their shard, see DeleteExpired, so they still count in
+// ItemCount until then, and the eviction callbacks are called with EvictionReasonExpired at that point.
+func (c *cache[K, V]) NewEpoch() {
+	epoch := c.shards[0].epoch
+	if c.wal != nil {
+		c.wal.logFlush(c, func() {
+			atomic.AddUint64(epoch, 1)
+		})
+		// the log is replayed from the flush, which removes the pinned items as well.
+		c.wal.log(c, c.pinnedKeys())
+	} else {
+		atomic.AddUint64(epoch, 1)
+	}
+	if c.negative != nil {
+		c.negative.NewEpoch()
+	}
+	if c.overflow != nil {
+		c.clearOverflow()
+	}
+}
+
+// expired reports whether e had expired at now, or belongs to a previous epoch. s.mu must be held.
+func (s *shard[K, V]) expired(e *entry[V], now int64) bool {
+	return e.expired(now) || s.outdated(e)
+}
+
+// outdated reports whether e was stored before the current epoch and is not pinned.
+// s.mu must be held.
+func (s *shard[K, V]) outdated(e *entry[V]) bool {
+	return !e.pinned && e.epoch != atomic.LoadUint64(s.epoch)
+}
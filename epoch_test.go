@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestNewEpoch(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	c.Set("foo", 1)
+	c.Set("bar", 2)
+	c.Set("pinned", 3)
+	c.Pin("pinned")
+	var reasons []EvictionReason
+	c.OnEvicted(func(_ string, _ int, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	})
+
+	c.NewEpoch()
+	if _, ok := c.Get("foo"); ok {
+		t.Errorf("expected foo to be invalidated")
+	}
+	if v, ok := c.Get("pinned"); !ok || v != 3 {
+		t.Errorf("expected the pinned item to be kept, got %d, %v", v, ok)
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "pinned" {
+		t.Errorf("expected only the pinned key, got %v", keys)
+	}
+	if len(reasons) != 0 {
+		t.Errorf("expected no eviction callback, got %v", reasons)
+	}
+
+	c.Set("foo", 4)
+	if v, ok := c.Get("foo"); !ok || v != 4 {
+		t.Errorf("expected the new value of foo, got %d, %v", v, ok)
+	}
+	if len(reasons) != 1 || reasons[0] != EvictionReasonExpired {
+		t.Errorf("expected the old foo to be evicted as expired, got %v", reasons)
+	}
+	if !c.Add("bar", 5) {
+		t.Errorf("expected bar to be added over its invalidated item")
+	}
+
+	c.Unpin("pinned")
+	if _, ok := c.Get("pinned"); !ok {
+		t.Errorf("expected the unpinned item to be kept until the next epoch")
+	}
+	c.NewEpoch()
+	if n := c.Len(); n != 0 {
+		t.Errorf("expected no live item, got %d", n)
+	}
+}
+
+func TestNewEpochClone(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.Set("foo", 1)
+	clone := c.Clone()
+	defer clone.Close()
+	c.NewEpoch()
+	if _, ok := clone.Get("foo"); !ok {
+		t.Errorf("expected the clone to keep its items")
+	}
+	clone.NewEpoch()
+	c.Set("bar", 2)
+	if _, ok := c.Get("bar"); !ok {
+		t.Errorf("expected the epoch of the clone not to affect the cache")
+	}
+}
+
+func TestNewEpochSweep(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	c.Pin("0")
+	var evicted int
+	c.OnEvicted(func(string, int, EvictionReason) {
+		evicted++
+	})
+	c.NewEpoch()
+	c.Set("new", 1)
+	c.DeleteExpired()
+	if n := c.ItemCount(); n != 2 {
+		t.Errorf("expected the outdated items to be removed, got %d items", n)
+	}
+	if evicted != 99 {
+		t.Errorf("expected 99 eviction callbacks, got %d", evicted)
+	}
+	c.Unpin("0")
+	c.DeleteExpired()
+	if n := c.ItemCount(); n != 2 {
+		t.Errorf("expected the unpinned item to be kept, got %d items", n)
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import "errors"
+
+var (
+	// ErrAlreadyExists is returned by AddE when the key already exists.
+	ErrAlreadyExists = errors.New("cache: already exists")
+	// ErrExpired is returned by GetE and ReplaceE when the item associated with the key
+	// has expired, but has not been removed yet.
+	ErrExpired = errors.New("cache: expired")
+)
+
+// GetE is like Get, but returns ErrNotFound if there is no item associated with the key,
+// including an item removed by NewEpoch, ErrExpired if it has expired, and ErrClosed if the
+// cache has been closed.
+func (c *cache[K, V]) GetE(key K) (V, error) {
+	if err := c.isClosed(); err != nil {
+		var zero V
+		return zero, err
+	}
+	v, _, err := c.readE(c.normalize(key))
+	c.stats.hit(err == nil)
+	if err != nil {
+		return v, err
+	}
+	return c.clone(v), nil
+}
+
+// AddE is like Add, but returns ErrAlreadyExists if the key already exists,
+// and ErrClosed if the cache has been closed.
+func (c *cache[K, V]) AddE(key K, value V) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.add(c.normalize(key), value, DefaultExpiration)
+}
+
+// ReplaceE is like Replace, but returns ErrNotFound if there is no item associated with the key,
+// ErrExpired if it has expired, and ErrClosed if the cache has been closed.
+func (c *cache[K, V]) ReplaceE(key K, value V) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.replace(c.normalize(key), value, DefaultExpiration)
+}
+
+// missing returns why there is no live item associated with the key at now: ErrExpired if it
+// has expired but was not removed yet, and ErrNotFound otherwise, an item outdated by NewEpoch
+// being gone. s.mu must be held.
+func (s *shard[K, V]) missing(key K, now int64) error {
+	if e, ok := s.items[key]; ok && !s.outdated(e) && e.expired(now) {
+		return ErrExpired
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestErrors(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, 0, WithClock(clock))
+	if _, err := c.GetE("foo"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := c.ReplaceE("foo", "bar"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := c.AddE("foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddE("foo", "baz"); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+	if err := c.ReplaceE("foo", "baz"); err != nil {
+		t.Error(err)
+	}
+	if v, err := c.GetE("foo"); err != nil || v != "baz" {
+		t.Errorf("expected baz, got %v, %v", v, err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.GetE("foo"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	if err := c.ReplaceE("foo", "qux"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	c.Close()
+	if _, err := c.GetE("foo"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if err := c.AddE("bar", "baz"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestErrorsEpoch(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, 0, WithClock(clock))
+	c.Set("foo", "bar")
+	c.Set("baz", "qux")
+	clock.Advance(time.Minute)
+	c.NewEpoch()
+	if _, err := c.GetE("foo"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an item of a previous epoch, got %v", err)
+	}
+	if err := c.ReplaceE("baz", "quux"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an item of a previous epoch, got %v", err)
+	}
+	if stats := c.Stats(); stats.Misses != 1 {
+		t.Errorf("expected a single miss, got %+v", stats)
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import "sync"
+
+// EvictionReason describes why an item was removed from the cache.
+type EvictionReason int
+
+const (
+	// EvictionReasonExpired means the item outlived its expiration time.
+	EvictionReasonExpired EvictionReason = iota + 1
+	// EvictionReasonDeleted means the item was removed by Delete.
+	EvictionReasonDeleted
+	// EvictionReasonCapacity means the item was evicted to make room for another one.
+	EvictionReasonCapacity
+	// EvictionReasonFlushed means the item was removed by Flush.
+	EvictionReasonFlushed
+)
+
+// String implements fmt.Stringer.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionReasonExpired:
+		return "expired"
+	case EvictionReasonDeleted:
+		return "deleted"
+	case EvictionReasonCapacity:
+		return "capacity"
+	case EvictionReasonFlushed:
+		return "flushed"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictedItem is an item removed from the cache, see Evictions.
+type EvictedItem[T any] struct {
+	Key    string
+	Value  T
+	Reason EvictionReason
+}
+
+// WithEvictionBuffer sets the size of the Evictions channel, 64 by default.
+func WithEvictionBuffer(n int) Option {
+	return func(o *options) {
+		o.evictionBuffer = n
+	}
+}
+
+// Evictions returns a channel receiving the items removed from the cache from the first call on,
+// like the OnEvicted callback, but without running the consumer on the goroutine removing them.
+// When the channel is full, the oldest item is dropped to make room, so that a slow consumer
+// never blocks the cache. The channel is closed by Close, once the remaining items are sent.
+// All the calls return the same channel.
+func (g *GenericCache[T]) Evictions() <-chan EvictedItem[T] {
+	f := g.evictions
+	f.once.Do(func() {
+		f.mu.Lock()
+		f.items = make(chan EvictedItem[T], f.size)
+		if g.isClosed() != nil {
+			f.closeLocked()
+		}
+		f.mu.Unlock()
+		g.listen(func(key string, value T, reason EvictionReason) {
+			f.send(EvictedItem[T]{Key: key, Value: value, Reason: reason})
+		})
+	})
+	return f.items
+}
+
+// evictionFeed is the channel returned by Evictions.
+type evictionFeed[V any] struct {
+	size int
+	once sync.Once
+
+	// mu protects items and closed, and serializes the sends.
+	mu     sync.Mutex
+	items  chan EvictedItem[V]
+	closed bool
+}
+
+func newEvictionFeed[V any](size int) *evictionFeed[V] {
+	if size < 1 {
+		size = 64
+	}
+	return &evictionFeed[V]{size: size}
+}
+
+// send sends item, dropping the oldest item if the channel is full.
+func (f *evictionFeed[V]) send(item EvictedItem[V]) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	for {
+		select {
+		case f.items <- item:
+			return
+		default:
+		}
+		select {
+		case <-f.items:
+		default:
+		}
+	}
+}
+
+// close closes the channel, if Evictions was called.
+func (f *evictionFeed[V]) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.items != nil {
+		f.closeLocked()
+	}
+}
+
+// closeLocked closes the channel once. f.mu must be held.
+func (f *evictionFeed[V]) closeLocked() {
+	if !f.closed {
+		f.closed = true
+		close(f.items)
+	}
+}
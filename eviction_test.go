@@ -0,0 +1,29 @@
+package cache
+
+import "testing"
+
+func TestEvictions(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithEvictionBuffer(2))
+	evictions := c.Evictions()
+	if c.Evictions() != evictions {
+		t.Error("expected the calls to return the same channel")
+	}
+	for i, key := range []string{"a", "b", "c"} {
+		c.Set(key, i)
+		c.Delete(key)
+	}
+	// a was dropped to make room for c.
+	for _, key := range []string{"b", "c"} {
+		if item := <-evictions; item.Key != key || item.Reason != EvictionReasonDeleted {
+			t.Errorf("expected %s to be deleted, got %+v", key, item)
+		}
+	}
+	c.Set("d", 3)
+	c.Close()
+	if item := <-evictions; item.Key != "d" || item.Value != 3 || item.Reason != EvictionReasonFlushed {
+		t.Errorf("expected d to be flushed, got %+v", item)
+	}
+	if _, ok := <-evictions; ok {
+		t.Error("expected the channel to be closed by Close")
+	}
+}
@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+)
+
+// ExpirationStrategy selects how a cache finds the items to delete once they expired.
+type ExpirationStrategy int
+
+const (
+	// ExpirationHeap orders expiring items in a min-heap, so that DeleteExpired only visits
+	// the items that are due. Storing an item is O(log n). It is the default.
+	ExpirationHeap ExpirationStrategy = iota
+	// TimingWheel files expiring items in a hierarchical timing wheel whose ticks are the
+	// cleanup interval, or a second without janitor. Storing an item is O(1), and expired
+	// items are deleted at most a tick late. It suits caches with many short-lived items.
+	TimingWheel
+)
+
+// String implements fmt.Stringer.
+func (s ExpirationStrategy) String() string {
+	switch s {
+	case ExpirationHeap:
+		return "ExpirationHeap"
+	case TimingWheel:
+		return "TimingWheel"
+	default:
+		return fmt.Sprintf("ExpirationStrategy(%d)", int(s))
+	}
+}
+
+// WithExpirationStrategy selects how expired items are found, see ExpirationStrategy.
+func WithExpirationStrategy(strategy ExpirationStrategy) Option {
+	return func(o *options) {
+		o.expirationStrategy = strategy
+	}
+}
+
+// newExpiryFunc returns the function creating the expiration index of each shard for the options.
+func newExpiryFunc[K comparable, V any](o *options, cleanupInterval time.Duration) func() expiry[K, V] {
+	switch o.expirationStrategy {
+	case ExpirationHeap:
+		return func() expiry[K, V] { return new(expirations[K, V]) }
+	case TimingWheel:
+		tick := cleanupInterval
+		if tick <= 0 {
+			tick = time.Second
+		}
+		clock := o.clock
+		return func() expiry[K, V] { return newWheel[K, V](int64(tick), clock.Now().UnixNano()) }
+	default:
+		panic(fmt.Sprintf("cache: unknown expiration strategy %v", o.expirationStrategy))
+	}
+}
+
+// expiry indexes the items of a shard which expire, so that deleteExpired only visits the
+// items that are due. It records its position in entry.index. s.mu must be held to call it.
+type expiry[K comparable, V any] interface {
+	// schedule records the item after its expiration time was set.
+	schedule(key K, e *entry[V])
+	// unschedule forgets the item.
+	unschedule(key K, e *entry[V])
+	// expire calls remove with the items which expired at now, which it forgets.
+	expire(now int64, remove func(key K, e *entry[V]))
+	// reset forgets all items.
+	reset()
+}
+
+// expirations is a min-heap of the items of a shard which expire, ordered by expiration time.
+// It implements heap.Interface.
+type expirations[K comparable, V any] []expiring[K, V]
+
+// expiring is an item of the expiration heap.
+type expiring[K comparable, V any] struct {
+	key K
+	e   *entry[V]
+}
+
+func (h expirations[K, V]) Len() int { return len(h) }
+
+func (h expirations[K, V]) Less(i, j int) bool { return h[i].e.expiration < h[j].e.expiration }
+
+func (h expirations[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].e.index = i
+	h[j].e.index = j
+}
+
+func (h *expirations[K, V]) Push(x any) {
+	item := x.(expiring[K, V])
+	item.e.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *expirations[K, V]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	item := old[n]
+	item.e.index = -1
+	// do not keep the entry reachable from the backing array.
+	old[n] = expiring[K, V]{}
+	*h = old[:n]
+	return item
+}
+
+func (h *expirations[K, V]) schedule(key K, e *entry[V]) {
+	switch {
+	case e.index >= 0 && e.expiration == 0:
+		heap.Remove(h, e.index)
+	case e.index >= 0:
+		heap.Fix(h, e.index)
+	case e.expiration > 0:
+		heap.Push(h, expiring[K, V]{key, e})
+	}
+}
+
+func (h *expirations[K, V]) unschedule(_ K, e *entry[V]) {
+	if e.index >= 0 {
+		heap.Remove(h, e.index)
+	}
+}
+
+func (h *expirations[K, V]) expire(now int64, remove func(key K, e *entry[V])) {
+	for len(*h) > 0 && (*h)[0].e.expired(now) {
+		item := heap.Pop(h).(expiring[K, V])
+		remove(item.key, item.e)
+	}
+}
+
+func (h *expirations[K, V]) reset() {
+	*h = nil
+}
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelLevels = 4
+	// wheelSpan is the number of ticks covered by the wheel, items expiring later are
+	// filed in the last slots and filed again when these come due.
+	wheelSpan = 1 << (wheelBits * wheelLevels)
+)
+
+// wheel is a hierarchical timing wheel. Slot i of level l holds the items due in the
+// tick whose bits l*wheelBits to (l+1)*wheelBits are i, among the next wheelSlots^(l+1) ticks.
+// The index of an entry is l*wheelSlots + i.
+type wheel[K comparable, V any] struct {
+	tick  int64 // the duration of a tick in nanoseconds
+	now   int64 // the last tick expire went through
+	count int
+	slots [wheelLevels][wheelSlots]map[K]*entry[V]
+}
+
+func newWheel[K comparable, V any](tick, now int64) *wheel[K, V] {
+	return &wheel[K, V]{tick: tick, now: now / tick}
+}
+
+func (w *wheel[K, V]) schedule(key K, e *entry[V]) {
+	w.unschedule(key, e)
+	if e.expiration == 0 {
+		return
+	}
+	// the tick in which the item expires, rounded up so that it is due once the tick is over.
+	due := (e.expiration + w.tick - 1) / w.tick
+	if due <= w.now {
+		due = w.now + 1
+	}
+	if due-w.now >= wheelSpan {
+		due = w.now + wheelSpan - 1
+	}
+	level := 0
+	for due-w.now >= 1<<(wheelBits*(level+1)) {
+		level++
+	}
+	i := int(due>>(wheelBits*level)) & (wheelSlots - 1)
+	slot := w.slots[level][i]
+	if slot == nil {
+		slot = make(map[K]*entry[V])
+		w.slots[level][i] = slot
+	}
+	slot[key] = e
+	e.index = level*wheelSlots + i
+	w.count++
+}
+
+func (w *wheel[K, V]) unschedule(key K, e *entry[V]) {
+	if e.index < 0 {
+		return
+	}
+	delete(w.slots[e.index/wheelSlots][e.index%wheelSlots], key)
+	e.index = -1
+	w.count--
+}
+
+func (w *wheel[K, V]) expire(now int64, remove func(key K, e *entry[V])) {
+	target := now / w.tick
+	if target-w.now >= wheelSpan {
+		// past a whole turn of the wheel, every slot is due.
+		w.now = target
+		for level := range w.slots {
+			for i := range w.slots[level] {
+				w.refile(level, i, now, remove)
+			}
+		}
+		return
+	}
+	for w.now < target {
+		if w.count == 0 {
+			w.now = target
+			return
+		}
+		w.now++
+		// cascade the higher levels whose slot for the new tick just started.
+		for level := 1; level < wheelLevels && w.now&(1<<(wheelBits*level)-1) == 0; level++ {
+			w.refile(level, int(w.now>>(wheelBits*level))&(wheelSlots-1), now, remove)
+		}
+		w.refile(0, int(w.now)&(wheelSlots-1), now, remove)
+	}
+}
+
+// refile empties a slot, removing its expired items and filing the others again.
+func (w *wheel[K, V]) refile(level, i int, now int64, remove func(key K, e *entry[V])) {
+	slot := w.slots[level][i]
+	if len(slot) == 0 {
+		return
+	}
+	w.slots[level][i] = nil
+	w.count -= len(slot)
+	for key, e := range slot {
+		e.index = -1
+		if e.expired(now) {
+			remove(key, e)
+		} else {
+			w.schedule(key, e)
+		}
+	}
+}
+
+func (w *wheel[K, V]) reset() {
+	w.slots = [wheelLevels][wheelSlots]map[K]*entry[V]{}
+	w.count = 0
+}
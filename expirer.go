@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// Expirer is implemented by values which carry their own expiration, like tokens or responses.
+// The items of Expirer values stored with DefaultExpiration expire at CacheExpiresAt, or after
+// the default expiration of the cache if it returns the zero time. Explicit durations win over it.
+type Expirer interface {
+	CacheExpiresAt() time.Time
+}
+
+// newExpiresAt returns a function calling CacheExpiresAt if V implements Expirer, or is an
+// interface whose dynamic values may implement it, and nil otherwise.
+func newExpiresAt[V any]() func(V) time.Time {
+	if _, ok := any(*new(V)).(Expirer); ok {
+		return func(v V) time.Time {
+			return any(v).(Expirer).CacheExpiresAt()
+		}
+	}
+	if reflect.TypeOf((*V)(nil)).Elem().Kind() == reflect.Interface {
+		return func(v V) time.Time {
+			if expirer, ok := any(v).(Expirer); ok {
+				return expirer.CacheExpiresAt()
+			}
+			return time.Time{}
+		}
+	}
+	return nil
+}
+
+// expirationOf returns the expiration of value stored for expireIn, see Expirer.
+func (c *cache[K, V]) expirationOf(value V, expireIn time.Duration) int64 {
+	if expireIn == DefaultExpiration && c.expiresAt != nil {
+		if deadline := c.expiresAt(value); !deadline.IsZero() {
+			return c.deadline(deadline)
+		}
+	}
+	return c.expiration(expireIn)
+}
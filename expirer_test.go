@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+type token struct {
+	value   string
+	expires time.Time
+}
+
+func (t token) CacheExpiresAt() time.Time { return t.expires }
+
+func TestExpirer(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[token](time.Minute, 0, WithClock(clock))
+	deadline := clock.Now().Add(time.Hour)
+	c.Set("a", token{"a", deadline})
+	c.Set("b", token{value: "b"})
+	c.SetWithExpireIn("c", token{"c", deadline}, time.Second)
+	c.SetMany(map[string]token{"d": {"d", deadline}}, DefaultExpiration)
+	for key, want := range map[string]time.Time{
+		"a": deadline,
+		"b": clock.Now().Add(time.Minute),
+		"c": clock.Now().Add(time.Second),
+		"d": deadline,
+	} {
+		if _, expiration, ok := c.GetWithExpiration(key); !ok || !expiration.Equal(want) {
+			t.Errorf("expected %s to expire at %v, got %v", key, want, expiration)
+		}
+	}
+	c.Set("e", token{"e", clock.Now().Add(-time.Second)})
+	if _, ok := c.Get("e"); ok {
+		t.Errorf("expected e to have expired")
+	}
+}
+
+func TestExpirerInterface(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[any](time.Minute, 0, WithClock(clock))
+	deadline := clock.Now().Add(time.Hour)
+	c.Set("token", token{"a", deadline})
+	c.Set("string", "b")
+	if _, expiration, _ := c.GetWithExpiration("token"); !expiration.Equal(deadline) {
+		t.Errorf("expected the token to expire at %v, got %v", deadline, expiration)
+	}
+	if _, expiration, _ := c.GetWithExpiration("string"); !expiration.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("expected the string to expire with the default expiration, got %v", expiration)
+	}
+}
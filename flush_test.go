@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlushFunc(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.Set("session:1", 1)
+	c.Set("session:2", 2)
+	c.Set("session:3", 3)
+	c.Set("user:1", 4)
+	c.Pin("session:3")
+	var reasons []EvictionReason
+	c.OnEvicted(func(_ string, _ int, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	})
+	n := c.FlushFunc(func(key string, _ int) bool {
+		return strings.HasPrefix(key, "session:")
+	})
+	if n != 2 {
+		t.Errorf("expected 2 items to be flushed, got %d", n)
+	}
+	if len(reasons) != 2 || reasons[0] != EvictionReasonFlushed || reasons[1] != EvictionReasonFlushed {
+		t.Errorf("expected the callbacks to be called with EvictionReasonFlushed, got %v", reasons)
+	}
+	for key, want := range map[string]bool{"session:1": false, "session:3": true, "user:1": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("expected %s to be kept: %v, got %v", key, want, ok)
+		}
+	}
+	if n := c.FlushFunc(func(_ string, v int) bool { return v > 3 }); n != 1 {
+		t.Errorf("expected the values to be matched, got %d items flushed", n)
+	}
+}
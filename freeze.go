@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReadOnly is returned by the writes to a ReadOnlyCache.
+var ErrReadOnly = errors.New("cache: read-only")
+
+// ReadOnlyCache is a view of a cache serving its reads and rejecting its writes, see Freeze.
+type ReadOnlyCache[T any] interface {
+	// Get returns the value associated with the key, and whether it exists.
+	Get(key string) (T, bool)
+	// GetCtx is like Get, but returns the context error without reading if ctx is already done.
+	GetCtx(ctx context.Context, key string) (T, bool, error)
+	// GetE is like Get, but returns ErrNotFound, ErrExpired or ErrClosed instead of false.
+	GetE(key string) (T, error)
+	// GetWithExpiration returns the value associated with the key and its expiration time.
+	GetWithExpiration(key string) (T, time.Time, bool)
+	// TTL returns how long the item associated with the key has left before it expires.
+	TTL(key string) (time.Duration, bool)
+	// Keys returns the keys of all live items.
+	Keys() []string
+	// Items returns a copy of all live items.
+	Items() map[string]Item[T]
+	// Range calls f for each live item until it returns false.
+	Range(f func(key string, value T) bool)
+	// Len returns the number of live items.
+	Len() int
+
+	// Set returns ErrReadOnly.
+	Set(key string, value T) error
+	// SetWithExpireIn returns ErrReadOnly.
+	SetWithExpireIn(key string, value T, expireIn time.Duration) error
+	// Delete returns ErrReadOnly.
+	Delete(key string) error
+	// Flush returns ErrReadOnly.
+	Flush() error
+}
+
+// Freeze returns a read-only view of the cache, to hand it to code which must not change it,
+// such as plugins, or while draining. The view reads the items of the cache as they are,
+// which may still be changed through g, and its writes return ErrReadOnly.
+func (g *GenericCache[T]) Freeze() ReadOnlyCache[T] {
+	return frozen[T]{g}
+}
+
+// frozen is the ReadOnlyCache returned by Freeze. The cache is not embedded, so that its
+// writes cannot be reached through the view.
+type frozen[T any] struct {
+	cache *GenericCache[T]
+}
+
+func (f frozen[T]) Get(key string) (T, bool) {
+	return f.cache.Get(key)
+}
+
+func (f frozen[T]) GetCtx(ctx context.Context, key string) (T, bool, error) {
+	return f.cache.GetCtx(ctx, key)
+}
+
+func (f frozen[T]) GetE(key string) (T, error) {
+	return f.cache.GetE(key)
+}
+
+func (f frozen[T]) GetWithExpiration(key string) (T, time.Time, bool) {
+	return f.cache.GetWithExpiration(key)
+}
+
+func (f frozen[T]) TTL(key string) (time.Duration, bool) {
+	return f.cache.TTL(key)
+}
+
+func (f frozen[T]) Keys() []string {
+	return f.cache.Keys()
+}
+
+func (f frozen[T]) Items() map[string]Item[T] {
+	return f.cache.Items()
+}
+
+func (f frozen[T]) Range(fn func(key string, value T) bool) {
+	f.cache.Range(fn)
+}
+
+func (f frozen[T]) Len() int {
+	return f.cache.Len()
+}
+
+func (f frozen[T]) Set(string, T) error {
+	return ErrReadOnly
+}
+
+func (f frozen[T]) SetWithExpireIn(string, T, time.Duration) error {
+	return ErrReadOnly
+}
+
+func (f frozen[T]) Delete(string) error {
+	return ErrReadOnly
+}
+
+func (f frozen[T]) Flush() error {
+	return ErrReadOnly
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreeze(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	c.Set("foo", "bar")
+	view := c.Freeze()
+	if v, ok := view.Get("foo"); !ok || v != "bar" {
+		t.Errorf("expected foo to be bar, got %q", v)
+	}
+	if err := view.Set("foo", "baz"); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := view.SetWithExpireIn("baz", "qux", time.Minute); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := view.Delete("foo"); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := view.Flush(); err != ErrReadOnly {
+		t.Errorf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if v, _ := c.Get("foo"); v != "bar" || c.Len() != 1 {
+		t.Errorf("expected the writes to the view to be rejected, got foo %q and %d items", v, c.Len())
+	}
+
+	c.Set("baz", "qux")
+	if n := view.Len(); n != 2 {
+		t.Errorf("expected the view to read the writes to the cache, got %d items", n)
+	}
+}
@@ -1,8 +1,3 @@
 module github.com/eatmoreapple/cache
 
 go 1.18
-
-require (
-	github.com/patrickmn/go-cache v2.1.0+incompatible
-	golang.org/x/exp v0.0.0-20230321023759-10a507213a29
-)
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BypassHeader is the request header making Handler pass the request to its handler without
+// reading nor storing a cached response, when it is set to any value.
+const BypassHeader = "Cache-Bypass"
+
+// DefaultMaxBodySize is the size of the largest response body stored by Handler, see WithMaxBodySize.
+const DefaultMaxBodySize = 1 << 20
+
+// WithMaxBodySize makes Handler store the responses whose body is at most n bytes only,
+// DefaultMaxBodySize if it is not given. It is ignored by other caches.
+func WithMaxBodySize(n int) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// Handler returns an http.Handler serving the responses of next from a cache for ttl, keyed by
+// keyFn, or by the host and the URI of the request if keyFn is nil. opts configure the cache,
+// with WithMaxEntries for instance.
+//
+// Only the GET responses with a 200, 203, 204, 301, 404 or 410 status are stored, unless they
+// have a no-store or private Cache-Control directive, set a cookie, or have a body larger than
+// WithMaxBodySize. Only the headers set by next are stored, not the ones set by the handlers
+// wrapping Handler. HEAD requests are served the headers of the cached GET responses. Responses
+// with a Vary header are stored for every combination of the values of the request headers it
+// lists. Requests with the BypassHeader are passed to next.
+func Handler(next http.Handler, keyFn func(*http.Request) string, ttl time.Duration, opts ...Option) http.Handler {
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string {
+			return r.Host + r.URL.RequestURI()
+		}
+	}
+	o := options{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &handler{next: next, keyFn: keyFn, maxBodySize: o.maxBodySize, responses: New[*response](ttl, ttl, opts...)}
+}
+
+// handler is the http.Handler returned by Handler.
+type handler struct {
+	next        http.Handler
+	keyFn       func(*http.Request) string
+	maxBodySize int
+	responses   *GenericCache[*response]
+}
+
+// response is a cached response. If vary is set, it only lists the request headers selecting
+// the response, which is stored under the key returned by variant.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	vary   []string
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.Header.Get(BypassHeader) != "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	key := h.keyFn(r)
+	resp, ok := h.responses.Get(key)
+	if ok && resp.vary != nil {
+		resp, ok = h.responses.Get(variant(key, resp.vary, r))
+	}
+	if ok {
+		resp.write(w, r.Method == http.MethodHead)
+		return
+	}
+	if r.Method == http.MethodHead {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	rec := &responseRecorder{ResponseWriter: w, before: w.Header().Clone(), maxBodySize: h.maxBodySize}
+	h.next.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.record(http.StatusOK)
+	}
+	if rec.tooLarge || !storable(rec.status, rec.header) {
+		return
+	}
+	resp = &response{status: rec.status, header: rec.header, body: rec.body.Bytes()}
+	vary := varyHeaders(resp.header)
+	if vary == nil {
+		h.responses.Set(key, resp)
+		return
+	}
+	if contains(vary, "*") {
+		return
+	}
+	h.responses.Set(key, &response{vary: vary})
+	h.responses.Set(variant(key, vary, r), resp)
+}
+
+// write writes the response to w, without its body if head is set.
+func (resp *response) write(w http.ResponseWriter, head bool) {
+	header := w.Header()
+	for name, values := range resp.header {
+		// copied, so that what is done to the header after the write does not change the cache.
+		header[name] = append([]string(nil), values...)
+	}
+	w.WriteHeader(resp.status)
+	if !head {
+		w.Write(resp.body)
+	}
+}
+
+// responseRecorder records the status, the headers and the body written to a ResponseWriter.
+type responseRecorder struct {
+	http.ResponseWriter
+	// before is the header set before the handler, header the one the handler set, recorded
+	// when the status is written.
+	before, header http.Header
+	status         int
+	body           bytes.Buffer
+	maxBodySize    int
+	// tooLarge is set once the body is larger than maxBodySize, and then no longer recorded.
+	tooLarge bool
+}
+
+// record records the status and the headers set by the handler since the recorder was created.
+func (rec *responseRecorder) record(status int) {
+	rec.status = status
+	rec.header = make(http.Header)
+	for name, values := range rec.ResponseWriter.Header() {
+		if !sameValues(values, rec.before[name]) {
+			rec.header[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.record(status)
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.record(http.StatusOK)
+	}
+	if !rec.tooLarge {
+		if rec.body.Len()+len(p) > rec.maxBodySize {
+			rec.tooLarge = true
+			rec.body = bytes.Buffer{}
+		} else {
+			rec.body.Write(p)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+// Flush flushes the ResponseWriter if it is an http.Flusher, so that the responses of the
+// handlers streaming them are streamed through Handler as well.
+func (rec *responseRecorder) Flush() {
+	if rec.status == 0 {
+		rec.record(http.StatusOK)
+	}
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the ResponseWriter, for http.ResponseController.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// storable reports whether a response with the status and header can be stored by Handler.
+func storable(status int, header http.Header) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-store", "private":
+			return false
+		}
+	}
+	return true
+}
+
+// varyHeaders returns the canonical names of the headers listed by the Vary header,
+// nil if there is none.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// variant returns the key of the response to r varying with the headers.
+func variant(key string, headers []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range headers {
+		b.WriteByte(0)
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+// sameValues reports whether the header values are the same.
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
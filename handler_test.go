@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/lang":
+			w.Header().Set("Vary", "Accept-Language")
+			fmt.Fprint(w, r.Header.Get("Accept-Language"))
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+			fmt.Fprint(w, "secret")
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("X-Page", "home")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "home")
+		}
+	}), nil, time.Minute)
+
+	serve := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	serve("GET", "/")
+	w := serve("GET", "/")
+	if calls != 1 || w.Body.String() != "home" || w.Header().Get("X-Page") != "home" || w.Code != http.StatusOK {
+		t.Errorf("expected the cached response, got %d calls, %d %v %q", calls, w.Code, w.Header(), w.Body)
+	}
+	if w := serve("HEAD", "/"); calls != 1 || w.Body.Len() != 0 || w.Header().Get("X-Page") != "home" {
+		t.Errorf("expected HEAD to be served the cached headers, got %d calls", calls)
+	}
+	serve("GET", "/", BypassHeader, "1")
+	if calls != 2 {
+		t.Errorf("expected the bypass header to reach the handler, got %d calls", calls)
+	}
+
+	calls = 0
+	serve("GET", "/lang", "Accept-Language", "fr")
+	serve("GET", "/lang", "Accept-Language", "en")
+	if w := serve("GET", "/lang", "Accept-Language", "fr"); calls != 2 || w.Body.String() != "fr" {
+		t.Errorf("expected a response per language, got %d calls and %q", calls, w.Body)
+	}
+
+	calls = 0
+	serve("GET", "/private")
+	serve("GET", "/private")
+	serve("GET", "/missing")
+	serve("GET", "/missing")
+	if calls != 3 {
+		t.Errorf("expected private responses not to be stored, got %d calls", calls)
+	}
+}
+
+func TestHandlerHeaderCopy(t *testing.T) {
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Page", "home")
+		fmt.Fprint(w, "home")
+	}), nil, time.Minute)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		w.Header()["X-Page"][0] = "changed"
+		w.Header().Add("X-Page", "added")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if values := w.Header()["X-Page"]; len(values) != 1 || values[0] != "home" {
+		t.Errorf("expected the cached header to be left alone, got %v", values)
+	}
+}
+
+func TestHandlerOuterHeaders(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Page", "home")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "home")
+	}), nil, time.Minute)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		// set by a middleware wrapping the cached handler.
+		w.Header().Set("X-Request-Id", fmt.Sprint(i))
+		w.Header().Set("Set-Cookie", "session=1")
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if i == 0 && !w.Flushed {
+			t.Error("expected the handler to flush the response")
+		}
+		if id := w.Header().Get("X-Request-Id"); id != fmt.Sprint(i) || w.Header().Get("X-Page") != "home" {
+			t.Errorf("expected the request id %d and the cached header, got %v", i, w.Header())
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the response to be stored despite the cookie of the middleware, got %d calls", calls)
+	}
+}
+
+func TestHandlerMaxBodySize(t *testing.T) {
+	calls := 0
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, r.URL.Path)
+	}), nil, time.Minute, WithMaxBodySize(4))
+	for _, path := range []string{"/a", "/a", "/large", "/large"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != path {
+			t.Errorf("expected the body %q, got %q", path, w.Body)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected the large response not to be stored, got %d calls", calls)
+	}
+}
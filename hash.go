@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// MapCache is a cache of maps of fields keyed by strings, like Redis hashes.
+// Fields are changed in place: writes run under the lock of the shard holding the key and the
+// lock of the map, reads only under the lock of the map, so they are atomic per key.
+// Since fields are not stored with Set, a MapCache cannot be dumped or logged with WithLog.
+type MapCache[K comparable, V any] struct {
+	*GenericCache[*hash[K, V]]
+}
+
+// hash is a map stored in a MapCache.
+type hash[K comparable, V any] struct {
+	mu     sync.RWMutex
+	fields map[K]V
+}
+
+// NewMapCache returns a new MapCache[K, V] with the given default expiration duration and cleanup interval.
+// A new map gets the default expiration, use Touch to change the expiration of a key.
+func NewMapCache[K comparable, V any](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *MapCache[K, V] {
+	return &MapCache[K, V]{GenericCache: New[*hash[K, V]](defaultExpiration, cleanupInterval, opts...)}
+}
+
+// HSet sets the field of the map associated with the key, creating it if needed,
+// and reports whether the field is new. An existing map keeps its expiration.
+func (m *MapCache[K, V]) HSet(key string, field K, value V) bool {
+	var added bool
+	m.Update(key, func(current *hash[K, V], exists bool) (*hash[K, V], bool) {
+		if !exists {
+			current = &hash[K, V]{fields: make(map[K]V)}
+		}
+		current.mu.Lock()
+		_, ok := current.fields[field]
+		current.fields[field] = value
+		current.mu.Unlock()
+		added = !ok
+		return current, !exists
+	})
+	return added
+}
+
+// HGet returns the value of the field of the map associated with the key.
+func (m *MapCache[K, V]) HGet(key string, field K) (value V, exists bool) {
+	h, ok := m.Get(key)
+	if !ok {
+		return
+	}
+	h.mu.RLock()
+	value, exists = h.fields[field]
+	h.mu.RUnlock()
+	return value, exists
+}
+
+// HDel deletes the fields of the map associated with the key and returns how many were deleted.
+// The key is deleted once its map is empty.
+func (m *MapCache[K, V]) HDel(key string, fields ...K) int {
+	var deleted int
+	m.Update(key, func(current *hash[K, V], exists bool) (*hash[K, V], bool) {
+		if !exists {
+			return current, false
+		}
+		current.mu.Lock()
+		for _, field := range fields {
+			if _, ok := current.fields[field]; ok {
+				delete(current.fields, field)
+				deleted++
+			}
+		}
+		current.mu.Unlock()
+		return current, false
+	})
+	if deleted > 0 {
+		m.CompareAndDeleteFunc(key, nil, func(current, _ *hash[K, V]) bool {
+			current.mu.RLock()
+			defer current.mu.RUnlock()
+			return len(current.fields) == 0
+		})
+	}
+	return deleted
+}
+
+// HGetAll returns a copy of the map associated with the key, nil if there is none.
+func (m *MapCache[K, V]) HGetAll(key string) map[K]V {
+	h, ok := m.Get(key)
+	if !ok {
+		return nil
+	}
+	h.mu.RLock()
+	fields := make(map[K]V, len(h.fields))
+	for field, value := range h.fields {
+		fields[field] = value
+	}
+	h.mu.RUnlock()
+	return fields
+}
+
+// HLen returns the number of fields of the map associated with the key.
+func (m *MapCache[K, V]) HLen(key string) int {
+	h, ok := m.Get(key)
+	if !ok {
+		return 0
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.fields)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestMapCache(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewMapCache[string, int](time.Minute, 0, WithClock(clock))
+	if !c.HSet("foo", "a", 1) || c.HSet("foo", "a", 2) {
+		t.Error("expected HSet to report new fields only")
+	}
+	c.HSet("foo", "b", 3)
+	if v, ok := c.HGet("foo", "a"); !ok || v != 2 {
+		t.Errorf("expected a to be 2, got %v", v)
+	}
+	if _, ok := c.HGet("foo", "c"); ok {
+		t.Error("expected c not to exist")
+	}
+	all := c.HGetAll("foo")
+	if len(all) != 2 || all["b"] != 3 {
+		t.Errorf("expected map[a:2 b:3], got %v", all)
+	}
+	all["c"] = 4
+	if c.HLen("foo") != 2 {
+		t.Error("expected HGetAll to return a copy")
+	}
+	if n := c.HDel("foo", "a", "c"); n != 1 {
+		t.Errorf("expected 1 field to be deleted, got %d", n)
+	}
+	c.HDel("foo", "b")
+	if _, ok := c.Get("foo"); ok {
+		t.Error("expected an empty map to be deleted")
+	}
+	if c.HGetAll("foo") != nil || c.HLen("foo") != 0 {
+		t.Error("expected a missing map to be empty")
+	}
+
+	c.HSet("foo", "a", 1)
+	clock.Advance(time.Second * 30)
+	c.HSet("foo", "b", 2)
+	clock.Advance(time.Second * 30)
+	if c.HLen("foo") != 0 {
+		t.Error("expected setting fields to keep the expiration of the map")
+	}
+}
+
+func TestMapCacheConcurrent(t *testing.T) {
+	c := NewMapCache[string, int](NoExpiration, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			field := strconv.Itoa(i)
+			c.HSet("foo", field, i)
+			c.HGet("foo", field)
+			c.HGetAll("foo")
+			if i%2 == 0 {
+				c.HDel("foo", field)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := c.HLen("foo"); n != 50 {
+		t.Errorf("expected 50 fields, got %d", n)
+	}
+}
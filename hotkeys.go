@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxHotKeys bounds the number of keys counted per window, reads of other keys are not
+// counted once it is reached. Hot keys are read often enough to be counted early on.
+const maxHotKeys = 10000
+
+// KeyStat is a key and its estimated number of reads over the WithHotKeys window.
+type KeyStat[K comparable] struct {
+	Key  K
+	Hits uint64
+}
+
+// WithHotKeys tracks the reads of every key, found or not, over a sliding window, see HotKeys.
+// Only a sampleRate share of the reads is counted to keep the overhead low, 0.01 counts one
+// read in a hundred. Counts are scaled back, so they are estimates. sampleRate must be in (0, 1].
+func WithHotKeys(window time.Duration, sampleRate float64) Option {
+	return func(o *options) {
+		o.hotWindow = window
+		o.hotSampleRate = sampleRate
+	}
+}
+
+// hotKeys counts the reads of the keys of a cache over the current and the previous window.
+type hotKeys[K comparable] struct {
+	window int64
+	every  uint64 // one read in every is counted
+	reads  uint64 // updated atomically
+
+	mu       sync.Mutex
+	start    int64 // when the current window started, in unix nanoseconds
+	current  map[K]uint64
+	previous map[K]uint64
+}
+
+func newHotKeys[K comparable](window time.Duration, sampleRate float64, now int64) *hotKeys[K] {
+	if window <= 0 || sampleRate <= 0 || sampleRate > 1 {
+		panic(fmt.Sprintf("cache: WithHotKeys window %v is not positive or sample rate %v is not in (0, 1]", window, sampleRate))
+	}
+	return &hotKeys[K]{
+		window:  int64(window),
+		every:   uint64(1/sampleRate + 0.5),
+		start:   now,
+		current: make(map[K]uint64),
+	}
+}
+
+// read records a read of the key at now. h may be nil.
+func (h *hotKeys[K]) read(key K, now int64) {
+	if h == nil || atomic.AddUint64(&h.reads, 1)%h.every != 0 {
+		return
+	}
+	h.mu.Lock()
+	h.rotate(now)
+	if n, ok := h.current[key]; ok || len(h.current) < maxHotKeys {
+		h.current[key] = n + 1
+	}
+	h.mu.Unlock()
+}
+
+// rotate starts a new window if the current one is over. h.mu must be held.
+func (h *hotKeys[K]) rotate(now int64) {
+	switch elapsed := now - h.start; {
+	case elapsed < h.window:
+	case elapsed < 2*h.window:
+		h.previous, h.current = h.current, make(map[K]uint64)
+		h.start += h.window
+	default:
+		// no read for a whole window.
+		h.previous, h.current = nil, make(map[K]uint64)
+		h.start = now
+	}
+}
+
+// top returns the n keys read the most over the last window, the previous window counting
+// for the part of it which is still within the last window.
+func (h *hotKeys[K]) top(n int, now int64) []KeyStat[K] {
+	h.mu.Lock()
+	h.rotate(now)
+	weight := 1 - float64(now-h.start)/float64(h.window)
+	counts := make(map[K]float64, len(h.current)+len(h.previous))
+	for key, n := range h.previous {
+		counts[key] = float64(n) * weight
+	}
+	for key, n := range h.current {
+		counts[key] += float64(n)
+	}
+	h.mu.Unlock()
+
+	stats := make([]KeyStat[K], 0, len(counts))
+	for key, count := range counts {
+		if hits := uint64(count*float64(h.every) + 0.5); hits > 0 {
+			stats = append(stats, KeyStat[K]{key, hits})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Hits > stats[j].Hits })
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// HotKeys returns the n keys read the most over the WithHotKeys window, the most read first,
+// with their estimated number of reads. It returns nil without WithHotKeys.
+func (c *cache[K, V]) HotKeys(n int) []KeyStat[K] {
+	if c.hot == nil || n <= 0 {
+		return nil
+	}
+	return c.hot.top(n, c.now())
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestHotKeys(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](NoExpiration, 0, WithClock(clock), WithHotKeys(time.Minute, 1))
+	c.Set("a", 1)
+	for i := 0; i < 30; i++ {
+		c.Get("a")
+	}
+	for i := 0; i < 20; i++ {
+		c.Get("missing")
+	}
+	c.Get("b")
+	stats := c.HotKeys(2)
+	if len(stats) != 2 || stats[0] != (KeyStat[string]{"a", 30}) || stats[1] != (KeyStat[string]{"missing", 20}) {
+		t.Errorf("expected a and missing to be the hottest keys, got %v", stats)
+	}
+
+	// half of the previous window is still within the last minute.
+	clock.Advance(time.Minute + time.Second*30)
+	for i := 0; i < 20; i++ {
+		c.Get("b")
+	}
+	stats = c.HotKeys(10)
+	if len(stats) != 3 || stats[0] != (KeyStat[string]{"b", 21}) || stats[1] != (KeyStat[string]{"a", 15}) {
+		t.Errorf("expected the previous window to count for half, got %v", stats)
+	}
+
+	clock.Advance(time.Minute * 3)
+	if stats := c.HotKeys(10); len(stats) != 0 {
+		t.Errorf("expected old reads to be forgotten, got %v", stats)
+	}
+	if stats := New[int](NoExpiration, 0).HotKeys(10); stats != nil {
+		t.Errorf("expected no hot keys without WithHotKeys, got %v", stats)
+	}
+}
+
+func TestHotKeysSampling(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithHotKeys(time.Minute, 0.1))
+	for i := 0; i < 1000; i++ {
+		c.Get("a")
+	}
+	if stats := c.HotKeys(1); len(stats) != 1 || stats[0].Hits != 1000 {
+		t.Errorf("expected the sampled reads to be scaled back, got %v", stats)
+	}
+}
+
+func TestHotKeysGetMany(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithHotKeys(time.Minute, 1))
+	c.Set("a", 1)
+	for i := 0; i < 3; i++ {
+		c.GetMany([]string{"a", "missing"})
+	}
+	if stats := c.HotKeys(2); len(stats) != 2 || stats[0].Hits != 3 || stats[1].Hits != 3 {
+		t.Errorf("expected the keys read by GetMany to be counted, got %v", stats)
+	}
+}
@@ -0,0 +1,257 @@
+// Package httpcache provides an http.RoundTripper caching GET responses in a cache.GenericCache,
+// following their Cache-Control, Expires, ETag and Last-Modified headers.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// XFromCache is the header set on the responses served from the cache, to "1".
+const XFromCache = "X-From-Cache"
+
+// errCorrupt is returned by decode for entries too short to have been stored by a Transport.
+var errCorrupt = errors.New("httpcache: corrupt entry")
+
+// Option configures a Transport.
+type Option func(*options)
+
+type options struct {
+	transport http.RoundTripper
+	clock     cache.Clock
+	staleTTL  time.Duration
+}
+
+// WithTransport sets the transport sending the requests, http.DefaultTransport by default.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithClock sets the clock telling the age of the responses, it is meant to be replaced in tests
+// along with the clock of the cache.
+func WithClock(clock cache.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithStaleTTL sets how long responses with an ETag or a Last-Modified header are kept once stale,
+// to be revalidated with a conditional request, 24 hours by default.
+func WithStaleTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.staleTTL = ttl
+	}
+}
+
+// Transport is an http.RoundTripper serving GET responses from a cache while they are fresh,
+// and revalidating them with a conditional request once they are stale.
+//
+// It acts as a private cache: responses marked private are cached, those marked no-store and
+// those with a Vary header are not. Requests with a no-store directive bypass the cache and
+// those with no-cache are revalidated. Successful requests with other methods than GET and
+// HEAD delete the cached response of their URL.
+type Transport struct {
+	cache     *cache.GenericCache[[]byte]
+	transport http.RoundTripper
+	now       func() time.Time
+	staleTTL  time.Duration
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// New returns a Transport caching responses in c, keyed by URL.
+// c can be a cache.BytesCache to bound the memory they take.
+func New(c *cache.GenericCache[[]byte], opts ...Option) *Transport {
+	o := options{transport: http.DefaultTransport, staleTTL: 24 * time.Hour}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := &Transport{cache: c, transport: o.transport, now: time.Now, staleTTL: o.staleTTL}
+	if o.clock != nil {
+		t.now = o.clock.Now
+	}
+	return t
+}
+
+// Client returns an http.Client sending its requests through t.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.URL.String()
+	if req.Method != http.MethodGet {
+		resp, err := t.transport.RoundTrip(req)
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode < 400 {
+			t.cache.Delete(key)
+		}
+		return resp, err
+	}
+	reqControl := parseCacheControl(req.Header)
+	if _, ok := reqControl["no-store"]; ok {
+		return t.transport.RoundTrip(req)
+	}
+	data, ok := t.cache.Get(key)
+	if !ok {
+		return t.fetch(req, key)
+	}
+	cached, stored, err := decode(data, req)
+	if err != nil {
+		t.cache.Delete(key)
+		return t.fetch(req, key)
+	}
+	_, noCache := reqControl["no-cache"]
+	if !noCache && t.now().Sub(stored)+age(cached.Header) < freshness(cached.Header, stored) {
+		cached.Header.Set(XFromCache, "1")
+		return cached, nil
+	}
+	etag, modified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if etag == "" && modified == "" {
+		cached.Body.Close()
+		return t.fetch(req, key)
+	}
+	conditional := req.Clone(req.Context())
+	if etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		conditional.Header.Set("If-Modified-Since", modified)
+	}
+	resp, err := t.transport.RoundTrip(conditional)
+	if err != nil {
+		cached.Body.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		cached.Body.Close()
+		return t.store(req, key, resp)
+	}
+	resp.Body.Close()
+	// the headers of the 304 response replace the cached ones.
+	for name, values := range resp.Header {
+		cached.Header[name] = values
+	}
+	cached.Header.Del("Age")
+	if _, err := t.store(req, key, cached); err != nil {
+		return nil, err
+	}
+	cached.Header.Set(XFromCache, "1")
+	return cached, nil
+}
+
+// fetch sends req and caches its response if possible.
+func (t *Transport) fetch(req *http.Request, key string) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.store(req, key, resp)
+}
+
+// store caches resp if it can be, and returns it with a body which can still be read.
+func (t *Transport) store(req *http.Request, key string, resp *http.Response) (*http.Response, error) {
+	control := parseCacheControl(resp.Header)
+	_, noStore := control["no-store"]
+	if noStore || !cacheable(resp.StatusCode) || resp.Header.Get("Vary") != "" {
+		t.cache.Delete(key)
+		return resp, nil
+	}
+	now := t.now()
+	ttl := freshness(resp.Header, now) - age(resp.Header)
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+		ttl += t.staleTTL
+	}
+	if ttl <= 0 {
+		t.cache.Delete(key)
+		return resp, nil
+	}
+	// DumpResponse reads the body and replaces it with a copy.
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 8, 8+len(dump))
+	binary.BigEndian.PutUint64(data, uint64(now.UnixNano()))
+	t.cache.SetWithExpireIn(key, append(data, dump...), ttl)
+	return resp, nil
+}
+
+// decode returns the cached response of req and when it was stored.
+func decode(data []byte, req *http.Request) (*http.Response, time.Time, error) {
+	if len(data) < 8 {
+		return nil, time.Time{}, errCorrupt
+	}
+	stored := time.Unix(0, int64(binary.BigEndian.Uint64(data)))
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data[8:])), req)
+	return resp, stored, err
+}
+
+// cacheable reports whether responses with the status code can be cached.
+func cacheable(code int) bool {
+	switch code {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+		return true
+	default:
+		return false
+	}
+}
+
+// freshness returns how long a response received at date is fresh, from its max-age directive
+// or its Expires header, 0 if it has neither or has a no-cache directive.
+func freshness(header http.Header, date time.Time) time.Duration {
+	control := parseCacheControl(header)
+	if _, ok := control["no-cache"]; ok {
+		return 0
+	}
+	if maxAge, ok := control["max-age"]; ok {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	if d, err := http.ParseTime(header.Get("Date")); err == nil {
+		date = d
+	}
+	return expires.Sub(date)
+}
+
+// age returns the Age header of a response, the time it spent in shared caches.
+func age(header http.Header) time.Duration {
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// parseCacheControl returns the directives of the Cache-Control header, with their value if any.
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return directives
+}
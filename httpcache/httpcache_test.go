@@ -0,0 +1,83 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestTransport(t *testing.T) {
+	var requests, revalidations int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidations++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		io.WriteString(w, "hello "+r.URL.Path)
+	}))
+	defer srv.Close()
+	clock := cachetest.NewClock(time.Now())
+	c := cache.New[[]byte](cache.NoExpiration, 0, cache.WithClock(clock))
+	client := New(c, WithClock(clock)).Client()
+
+	get := func(path string, fromCache bool) {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != "hello "+path {
+			t.Errorf("expected the body of %s, got %q", path, body)
+		}
+		if got := resp.Header.Get(XFromCache) == "1"; got != fromCache {
+			t.Errorf("expected %s to be served from the cache: %v, got %v", path, fromCache, got)
+		}
+	}
+	get("/fresh", false)
+	get("/fresh", true)
+	if requests != 1 {
+		t.Errorf("expected the fresh response to be served from the cache, got %d requests", requests)
+	}
+	clock.Advance(time.Minute)
+	get("/fresh", true)
+	if requests != 2 || revalidations != 1 {
+		t.Errorf("expected the stale response to be revalidated, got %d requests", requests)
+	}
+	get("/fresh", true)
+	if requests != 2 {
+		t.Errorf("expected the revalidated response to be fresh, got %d requests", requests)
+	}
+
+	get("/no-store", false)
+	get("/no-store", false)
+	get("/none", false)
+	if requests != 5 {
+		t.Errorf("expected the responses without freshness to be fetched again, got %d requests", requests)
+	}
+
+	resp, err := client.Post(srv.URL+"/fresh", "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, ok := c.Get(srv.URL + "/fresh"); ok {
+		t.Error("expected a POST to delete the cached response")
+	}
+}
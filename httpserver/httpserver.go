@@ -0,0 +1,156 @@
+// Package httpserver serves a cache over HTTP, so that services written in other languages
+// can share it:
+//
+//	GET    /keys/{key}  the value, 404 if there is none
+//	PUT    /keys/{key}  stores the request body as the value
+//	DELETE /keys/{key}  deletes the item
+//
+// Values are encoded with a cache.Codec, JSON by default. The TTLHeader of PUT requests sets
+// the expiration of the item, and the one of GET responses is the time it has left.
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// TTLHeader is the header carrying the time to live of an item, either in seconds or as
+// a time.ParseDuration string. A negative time to live means the item never expires, and
+// without the header the item gets the default expiration of the cache.
+const TTLHeader = "Cache-TTL"
+
+// Option configures a Handler.
+type Option func(*options)
+
+type options struct {
+	contentType  string
+	maxValueSize int64
+}
+
+// WithContentType sets the content type of the values, application/json by default.
+func WithContentType(contentType string) Option {
+	return func(o *options) {
+		o.contentType = contentType
+	}
+}
+
+// WithMaxValueSize sets the maximum size of the encoded values in bytes, 1 MiB by default.
+func WithMaxValueSize(n int64) Option {
+	return func(o *options) {
+		o.maxValueSize = n
+	}
+}
+
+// Handler serves a cache over HTTP.
+type Handler[T any] struct {
+	cache *cache.GenericCache[T]
+	codec cache.Codec[T]
+
+	contentType  string
+	maxValueSize int64
+}
+
+// New returns a Handler serving c, encoding its values with codec, or as JSON if codec is nil.
+func New[T any](c *cache.GenericCache[T], codec cache.Codec[T], opts ...Option) *Handler[T] {
+	o := options{contentType: "application/json", maxValueSize: 1 << 20}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if codec == nil {
+		codec = cache.JSONCodec[T]{}
+	}
+	return &Handler[T]{cache: c, codec: codec, contentType: o.contentType, maxValueSize: o.maxValueSize}
+}
+
+// ListenAndServe serves c on addr, see New.
+func ListenAndServe[T any](addr string, c *cache.GenericCache[T], codec cache.Codec[T], opts ...Option) error {
+	mux := http.NewServeMux()
+	mux.Handle("/keys/", New(c, codec, opts...))
+	return http.ListenAndServe(addr, mux)
+}
+
+// ServeHTTP serves the requests for /keys/{key}.
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/keys/")
+	if key == "" || key == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.get(w, key)
+	case http.MethodPut:
+		h.put(w, r, key)
+	case http.MethodDelete:
+		h.cache.Delete(key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler[T]) get(w http.ResponseWriter, key string) {
+	v, expiration, ok := h.cache.GetWithExpiration(key)
+	if !ok {
+		http.Error(w, "cache: key not found", http.StatusNotFound)
+		return
+	}
+	data, err := h.codec.Encode(v)
+	if err != nil {
+		http.Error(w, "cache: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !expiration.IsZero() {
+		// rounded up, so that a live item never reports 0.
+		ttl := time.Until(expiration)
+		w.Header().Set(TTLHeader, strconv.FormatInt(int64((ttl+time.Second-1)/time.Second), 10))
+	}
+	w.Header().Set("Content-Type", h.contentType)
+	w.Write(data)
+}
+
+func (h *Handler[T]) put(w http.ResponseWriter, r *http.Request, key string) {
+	ttl := cache.DefaultExpiration
+	if s := r.Header.Get(TTLHeader); s != "" {
+		var err error
+		if ttl, err = parseTTL(s); err != nil {
+			http.Error(w, "cache: invalid "+TTLHeader+" header", http.StatusBadRequest)
+			return
+		}
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxValueSize))
+	if err != nil {
+		http.Error(w, "cache: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	v, err := h.codec.Decode(data)
+	if err != nil {
+		http.Error(w, "cache: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.cache.SetWithExpireIn(key, v, ttl)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTTL parses a TTLHeader value.
+func parseTTL(s string) (time.Duration, error) {
+	var ttl time.Duration
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		ttl = time.Duration(seconds) * time.Second
+	} else if ttl, err = time.ParseDuration(s); err != nil {
+		return 0, err
+	}
+	switch {
+	case ttl < 0:
+		return cache.NoExpiration, nil
+	case ttl == 0:
+		return cache.DefaultExpiration, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestHandler(t *testing.T) {
+	c := cache.New[user](time.Hour, 0)
+	h := New[user](c, nil)
+	do := func(method, target, body string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range header {
+			r.Header.Set(k, v[0])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(http.MethodPut, "/keys/user:1", `{"name":"foo"}`, http.Header{TTLHeader: {"1m"}}); w.Code != http.StatusNoContent {
+		t.Fatalf("expected PUT to succeed, got %d %s", w.Code, w.Body)
+	}
+	w := do(http.MethodGet, "/keys/user:1", "", nil)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"name":"foo"}` {
+		t.Errorf("expected the value, got %d %s", w.Code, w.Body)
+	}
+	if ttl := w.Header().Get(TTLHeader); ttl != "60" {
+		t.Errorf("expected 60 seconds left, got %q", ttl)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON content type, got %q", ct)
+	}
+
+	do(http.MethodPut, "/keys/user:2", `{"name":"bar"}`, http.Header{TTLHeader: {"-1"}})
+	if w := do(http.MethodGet, "/keys/user:2", "", nil); w.Header().Get(TTLHeader) != "" {
+		t.Errorf("expected no ttl for an item which never expires, got %q", w.Header().Get(TTLHeader))
+	}
+	if w := do(http.MethodPut, "/keys/user:3", `{"name":"baz"}`, http.Header{TTLHeader: {"soon"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid ttl to be rejected, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/keys/user:3", `{`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid value to be rejected, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, "/keys/user:1", "", nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected DELETE to succeed, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/keys/user:1", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected a deleted key to be 404, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/keys/user:1", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected POST not to be allowed, got %d", w.Code)
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	h := New[string](cache.New[string](cache.NoExpiration, 0), nil, WithMaxValueSize(8))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/keys/foo", strings.NewReader(`"too long value"`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected a large value to be rejected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ItemInfo describes an item of the cache, see Inspect.
+type ItemInfo struct {
+	// Created is when the key was stored, overwriting its value keeps it.
+	Created time.Time
+	// Updated is when the value or the expiration of the item was last set.
+	Updated time.Time
+	// LastAccess is when the item was last read, the zero time if it was never read.
+	LastAccess time.Time
+	// Hits is how many times the item was read.
+	Hits int64
+	// Expiration is the zero time if the item never expires.
+	Expiration time.Time
+	// Size is the cost of the item as measured by WithCost, 1 with WithMaxCost alone,
+	// and 0 if costs are not tracked.
+	Size int64
+	// Priority is the SetWithPriority priority of the item in a bounded cache, 0 otherwise.
+	Priority int
+	// Pinned reports whether the item is pinned, see Pin.
+	Pinned bool
+	Tags   []string
+}
+
+// Inspect returns the metadata of the item associated with the key, without counting it as a read.
+func (c *cache[K, V]) Inspect(key K) (ItemInfo, bool) {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.items[key]
+	if !ok || s.expired(e, c.now()) {
+		return ItemInfo{}, false
+	}
+	info := ItemInfo{
+		Created:  time.Unix(0, e.created),
+		Updated:  time.Unix(0, e.stored),
+		Hits:     atomic.LoadInt64(&e.hits),
+		Size:     e.cost,
+		Priority: s.priority(key),
+		Pinned:   e.pinned,
+	}
+	if accessed := atomic.LoadInt64(&e.accessed); accessed > 0 {
+		info.LastAccess = time.Unix(0, accessed)
+	}
+	if e.expiration > 0 {
+		info.Expiration = time.Unix(0, e.expiration)
+	}
+	if len(e.tags) > 0 {
+		info.Tags = append([]string(nil), e.tags...)
+	}
+	return info, true
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestInspect(t *testing.T) {
+	start := time.Unix(1e9, 0)
+	clock := cachetest.NewClock(start)
+	c := New[string](NoExpiration, 0, WithClock(clock), WithCost(func(key string, value string) int64 { return int64(len(value)) }))
+	c.SetWithTags("foo", "bar", time.Minute, "a")
+	if info, ok := c.Inspect("foo"); !ok || info.Hits != 0 || !info.LastAccess.IsZero() || info.Size != 3 || len(info.Tags) != 1 {
+		t.Errorf("expected a new item, got %+v", info)
+	}
+
+	clock.Advance(time.Second)
+	c.Get("foo")
+	c.Get("foo")
+	clock.Advance(time.Second)
+	c.Set("foo", "quux")
+	info, ok := c.Inspect("foo")
+	if !ok {
+		t.Fatal("expected foo to exist")
+	}
+	if !info.Created.Equal(start) {
+		t.Errorf("expected foo to be created at %v, got %v", start, info.Created)
+	}
+	if !info.Updated.Equal(start.Add(time.Second * 2)) {
+		t.Errorf("expected foo to be updated at %v, got %v", start.Add(time.Second*2), info.Updated)
+	}
+	if info.Hits != 2 || !info.LastAccess.Equal(start.Add(time.Second)) {
+		t.Errorf("expected 2 hits at %v, got %d at %v", start.Add(time.Second), info.Hits, info.LastAccess)
+	}
+	if !info.Expiration.IsZero() || info.Size != 4 || info.Tags != nil {
+		t.Errorf("expected the overwrite to be reflected, got %+v", info)
+	}
+	if _, ok := c.Inspect("bar"); ok {
+		t.Error("expected bar to not exist")
+	}
+}
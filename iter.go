@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package cache
+
+import "iter"
+
+// All returns an iterator over the live items in the cache, to be used with a for range loop.
+// The iterator walks a consistent snapshot taken when the iteration starts.
+func (c *cache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, item := range c.snapshot(c.now()) {
+			if !yield(item.key, c.clone(item.value)) {
+				return
+			}
+		}
+	}
+}
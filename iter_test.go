@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package cache
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	c.SetMany(map[string]int{"a": 1, "b": 2, "c": 3}, DefaultExpiration)
+	sum := 0
+	for key, value := range c.All() {
+		c.Delete(key)
+		sum += value
+	}
+	if sum != 6 {
+		t.Errorf("expected sum to be 6, got %d", sum)
+	}
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected cache to be empty, got %d items", n)
+	}
+}
+
+func TestAllCopies(t *testing.T) {
+	c := New[[]int](NoExpiration, 0, WithCopier(func(v []int) []int {
+		return append([]int(nil), v...)
+	}))
+	c.Set("a", []int{1})
+	for _, value := range c.All() {
+		value[0] = 2
+	}
+	if v, _ := c.Get("a"); v[0] != 1 {
+		t.Errorf("expected the cached value to be unchanged, got %v", v)
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import "time"
+
+// Range calls f sequentially for each live item in the cache. If f returns false, Range stops the iteration.
+// Items are read from a snapshot of one shard at a time, so f may call other cache methods
+// and does not block writers. Range does not count as reads in Stats nor as accesses for the eviction policy.
+func (c *cache[K, V]) Range(f func(key K, value V) bool) {
+	for _, s := range c.shards {
+		for _, item := range s.snapshot(c.now()) {
+			if !f(item.key, c.clone(item.value)) {
+				return
+			}
+		}
+	}
+}
+
+// Item is a cached value with its expiration metadata.
+type Item[V any] struct {
+	Value V
+	// Expiration is the zero time if the item never expires.
+	Expiration time.Time
+}
+
+// Expired reports whether the item had expired at now.
+func (i Item[V]) Expired(now time.Time) bool {
+	return !i.Expiration.IsZero() && !now.Before(i.Expiration)
+}
+
+// Keys returns the keys of all live items in the cache.
+func (c *cache[K, V]) Keys() []K {
+	var keys []K
+	now := c.now()
+	for _, s := range c.shards {
+		for _, item := range s.snapshot(now) {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys
+}
+
+// Items returns a copy of all live items in the cache.
+func (c *cache[K, V]) Items() map[K]Item[V] {
+	items := make(map[K]Item[V])
+	now := c.now()
+	for _, s := range c.shards {
+		for _, item := range s.snapshot(now) {
+			var expiration time.Time
+			if item.expiration > 0 {
+				expiration = time.Unix(0, item.expiration)
+			}
+			items[item.key] = Item[V]{Value: c.clone(item.value), Expiration: expiration}
+		}
+	}
+	return items
+}
+
+// snapshot copies the items that are live at now, holding the locks
+// of all shards at once so that the copy is consistent across shards.
+func (c *cache[K, V]) snapshot(now int64) []snapshotItem[K, V] {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		n += len(s.items)
+	}
+	items := make([]snapshotItem[K, V], 0, n)
+	for _, s := range c.shards {
+		for key, e := range s.items {
+			if !s.expired(e, now) {
+				items = append(items, snapshotItem[K, V]{key, e.value, e.expiration})
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return items
+}
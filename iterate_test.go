@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestRange(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewSharded[int](4, NoExpiration, 0, WithClock(clock))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.SetWithExpireIn("d", 4, time.Millisecond)
+	clock.Advance(time.Millisecond * 2)
+	sum := 0
+	c.Range(func(key string, value int) bool {
+		// Range must not hold any lock while calling f.
+		c.Set(key, value*10)
+		sum += value
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("expected sum of live items to be 6, got %d", sum)
+	}
+	n := 0
+	c.Range(func(string, int) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("expected Range to stop after 1 item, got %d", n)
+	}
+}
+
+func TestKeysItems(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](NoExpiration, 0, WithClock(clock))
+	c.Set("a", 1)
+	c.SetWithExpireIn("b", 2, time.Minute)
+	c.SetWithExpireIn("c", 3, time.Millisecond)
+	clock.Advance(time.Millisecond * 2)
+	if keys := c.Keys(); len(keys) != 2 {
+		t.Errorf("expected 2 keys, got %v", keys)
+	}
+	items := c.Items()
+	if len(items) != 2 || items["a"].Value != 1 || !items["a"].Expiration.IsZero() {
+		t.Errorf("expected a to be 1 without expiration, got %+v", items["a"])
+	}
+	if item := items["b"]; item.Value != 2 || item.Expired(clock.Now()) || !item.Expired(clock.Now().Add(time.Minute)) {
+		t.Errorf("expected b to be 2 expiring in a minute, got %+v", item)
+	}
+}
@@ -0,0 +1,91 @@
+package cache
+
+import "time"
+
+// janitor periodically deletes expired items from a cache.
+type janitor struct {
+	clock    Clock
+	interval time.Duration
+	done     chan struct{}
+}
+
+func newJanitor(clock Clock, interval time.Duration) *janitor {
+	return &janitor{clock: clock, interval: interval, done: make(chan struct{})}
+}
+
+// run calls clean every interval until the janitor is stopped.
+func (j *janitor) run(clean func()) {
+	for {
+		select {
+		case <-j.clock.After(j.interval):
+			// both channels may be ready, stopping wins.
+			select {
+			case <-j.done:
+				return
+			default:
+			}
+			clean()
+		case <-j.done:
+			return
+		}
+	}
+}
+
+func (j *janitor) stop() {
+	close(j.done)
+}
+
+// StopJanitor stops deleting expired items in the background until StartJanitor is called,
+// to keep cleanups out of latency-critical windows. Expired items are still never returned.
+// A cleanup already running completes.
+func (c *cache[K, V]) StopJanitor() {
+	c.janitorMu.Lock()
+	c.stopJanitors()
+	c.janitorMu.Unlock()
+	if c.negative != nil {
+		c.negative.StopJanitor()
+	}
+}
+
+// StartJanitor starts deleting expired items every interval, replacing the current schedule.
+// An interval <= 0 stops the janitor, like StopJanitor.
+func (c *cache[K, V]) StartJanitor(interval time.Duration) {
+	c.janitorMu.Lock()
+	c.stopJanitors()
+	c.startJanitors(interval)
+	c.janitorMu.Unlock()
+	if c.negative != nil {
+		c.negative.StartJanitor(interval)
+	}
+}
+
+// RunCleanupNow deletes the expired items right away, as the janitor does every cleanup interval,
+// so that cleanups can be run while the application is idle.
+func (c *cache[K, V]) RunCleanupNow() {
+	c.DeleteExpired()
+	if c.negative != nil {
+		c.negative.RunCleanupNow()
+	}
+}
+
+// startJanitors starts a janitor per shard if interval > 0. c.janitorMu must be held.
+func (c *cache[K, V]) startJanitors(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for _, s := range c.shards {
+		s := s
+		s.janitor = newJanitor(c.clock, interval)
+		go s.janitor.run(func() { c.cleanup(s) })
+	}
+}
+
+// stopJanitors stops the janitors of the shards. c.janitorMu must be held.
+func (c *cache[K, V]) stopJanitors() {
+	for _, s := range c.shards {
+		if s.janitor != nil {
+			s.janitor.stop()
+			s.janitor = nil
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestJanitorControl(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Second, time.Minute, WithClock(clock))
+	c.StopJanitor()
+	c.Set("foo", "bar")
+	clock.Advance(time.Minute)
+	time.Sleep(time.Millisecond * 10)
+	if n := c.ItemCount(); n != 1 {
+		t.Errorf("expected the stopped janitor not to delete foo, got %d items", n)
+	}
+	c.RunCleanupNow()
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected RunCleanupNow to delete foo, got %d items", n)
+	}
+
+	c.StartJanitor(time.Second)
+	c.Set("foo", "bar")
+	// the janitor may not be waiting on the clock yet.
+	for i := 0; i < 100 && c.ItemCount() > 0; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected the restarted janitor to delete foo, got %d items", n)
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Key builds a key from its parts, separated by NamespaceSeparator. The separators and the
+// backslashes within the parts are escaped with a backslash, so that different parts never
+// build the same key, unlike a plain strings.Join:
+//
+//	Key("user", "a:b", 1) // user:a\:b:1
+//	Key("user", "a", "b:1") // user:a:b\:1
+//
+// Strings, byte slices, integers, floats, booleans and fmt.Stringer values, including those of
+// named types, are written as they are printed, other values with their %#v formatting.
+func Key(parts ...any) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteString(NamespaceSeparator)
+		}
+		writeKeyPart(&b, part)
+	}
+	return b.String()
+}
+
+// KeyOf returns a function building the keys of the items identified by values of type K,
+// such as the IDs of users, with Key(prefix, id). It keeps the keys of a kind of items
+// consistent across the call sites, and checks the type of the IDs at compile time.
+func KeyOf[K any](prefix string) func(id K) string {
+	return func(id K) string {
+		return Key(prefix, id)
+	}
+}
+
+// writeKeyPart writes part to b, escaping the separators and backslashes.
+func writeKeyPart(b *strings.Builder, part any) {
+	var s string
+	switch v := part.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		// the kinds cover the named types, such as type UserID int.
+		rv := reflect.ValueOf(part)
+		switch rv.Kind() {
+		case reflect.String:
+			s = rv.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s = strconv.FormatInt(rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			s = strconv.FormatUint(rv.Uint(), 10)
+		case reflect.Float32:
+			s = strconv.FormatFloat(rv.Float(), 'g', -1, 32)
+		case reflect.Float64:
+			s = strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+		case reflect.Bool:
+			s = strconv.FormatBool(rv.Bool())
+		default:
+			s = fmt.Sprintf("%#v", part)
+		}
+	}
+	if !strings.ContainsAny(s, NamespaceSeparator+`\`) {
+		b.WriteString(s)
+		return
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || strings.HasPrefix(s[i:], NamespaceSeparator) {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		parts []any
+		want  string
+	}{
+		{[]any{"user", 42}, "user:42"},
+		{[]any{"user", "a:b", 1}, `user:a\:b:1`},
+		{[]any{"user", "a", "b:1"}, `user:a:b\:1`},
+		{[]any{`a\`, "b"}, `a\\:b`},
+		{[]any{"a", `\:b`}, `a:\\\:b`},
+		{[]any{[]byte("b"), int8(-1), uint64(2), 1.5, true}, "b:-1:2:1.5:true"},
+		{[]any{time.Second}, "1s"},
+		{[]any{struct{ A string }{"x"}}, `struct { A string }{A\:"x"}`},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		if got := Key(test.parts...); got != test.want {
+			t.Errorf("expected Key(%v) to be %s, got %s", test.parts, test.want, got)
+		}
+	}
+	if Key("a:b", "c") == Key("a", "b:c") {
+		t.Error("expected different parts to build different keys")
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	type userID int
+	userKey := KeyOf[userID]("user")
+	if got := userKey(7); got != "user:7" {
+		t.Errorf("expected user:7, got %s", got)
+	}
+	type slug string
+	postKey := KeyOf[slug]("post")
+	if got := postKey("a:b"); got != `post:a\:b` {
+		t.Errorf("expected post:a\\:b, got %s", got)
+	}
+}
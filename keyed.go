@@ -0,0 +1,869 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyedCache is a generic cache that can be used with any key and value type.
+type KeyedCache[K comparable, V any] struct {
+	*cache[K, V]
+	// If this is confusing, see the comment at the bottom of NewKeyed().
+}
+
+type cache[K comparable, V any] struct {
+	// stats comes first to keep its counters 64-bit aligned for atomic access.
+	stats counters
+
+	defaultExpiration time.Duration
+	cleanupInterval   time.Duration
+	clock             Clock
+	coarse            *coarseClock  // the clock, nil without WithCoarseClock
+	staleFor          time.Duration // how long expired items may still be served by GetOrLoad
+	beta              float64       // the WithEarlyExpiration parameter, 0 if disabled
+	jitter            float64       // the WithTTLJitter fraction, 0 if disabled
+	minTTL, maxTTL    time.Duration // the WithMinTTL and WithMaxTTL bounds, 0 if disabled
+	refresh           *refresher[K, V]
+	negative          *KeyedCache[K, error] // loader errors, nil without WithNegativeCaching nor WithErrorBackoff
+	negativeTTL       time.Duration         // the WithNegativeCaching duration, 0 if disabled
+	backoff           Backoff               // nil without WithErrorBackoff
+	sized             bool                  // whether WithCost is set
+	sub               *subscription         // nil without WithInvalidationBus
+	snapshots         *snapshotter          // nil without WithSnapshot
+	wal               *wal[K, V]            // nil without WithLog
+	overflow          Overflow[K, V]        // nil without WithOverflow
+	hot               *hotKeys[K]           // nil without WithHotKeys
+	copier            func(V) V             // nil without WithCopier or Cloner values
+	expiresAt         func(V) time.Time     // nil unless the values may implement Expirer
+	transform         func(K) K             // nil without WithKeyTransform
+	log               logHook               // nil without WithLogger
+	watchers          *watchers[K, V]
+	evictions         *evictionFeed[V]
+	shards            []*shard[K, V]
+	// hash spreads keys over shards, it is nil when there is a single shard.
+	hash func(K) uint64
+	// migrate converts incompatible dumps, it is nil without WithMigration.
+	migrate func(header DumpHeader, payload []byte) ([]DumpItem[K, V], error)
+	// dumpCodec encodes the dumps of DumpTo, it is nil without WithDumpCodec, which means GobCodec.
+	dumpCodec     Codec[[]DumpItem[K, V]]
+	dumpCodecName string
+
+	// mu protects onEvicted, listeners, onPersistError and closers.
+	mu        sync.RWMutex
+	onEvicted func(key K, value V, reason EvictionReason)
+	// listeners are internal eviction callbacks, called after onEvicted.
+	listeners []func(key K, value V, reason EvictionReason)
+	// onPersistError is called with the errors of the snapshots, the log and the overflow.
+	onPersistError func(err error)
+	// closers are called first by Close.
+	closers []func() error
+
+	// loads deduplicates concurrent GetOrLoad calls.
+	loads group[K, V]
+
+	// janitorMu protects the janitors of the shards.
+	janitorMu sync.Mutex
+	// pressure checks the memory of the process, it is nil without WithMemoryPressure.
+	pressure *janitor
+	// closed is set once Close has been called.
+	closed int32
+	// stopped makes sure that the background goroutines are stopped once,
+	// by Close or the finalizer.
+	stopped sync.Once
+}
+
+// entry is a cached value with its expiration time in unix nanoseconds, 0 if it never expires.
+type entry[V any] struct {
+	// hits and accessed, in unix nanoseconds, record the reads of the entry. They are updated
+	// atomically since reads may only hold a read lock, and come first to be 64-bit aligned.
+	hits       int64
+	accessed   int64
+	value      V
+	expiration int64
+	// created is when the key was first stored, in unix nanoseconds.
+	created int64
+	// stored is when the expiration was last set, in unix nanoseconds.
+	stored int64
+	// delta is how long loading the value took in nanoseconds, 0 if it was not loaded.
+	delta int64
+	cost  int64
+	tags  []string
+	// epoch is the epoch of the cache when the entry was stored, see NewEpoch.
+	epoch uint64
+	// pinned is set while the entry is pinned, see Pin.
+	pinned bool
+	// index is the position of the entry in the expiration index of its shard, -1 if it is not in it.
+	index int
+}
+
+// hit records that the entry was read at now.
+func (e *entry[V]) hit(now int64) {
+	atomic.AddInt64(&e.hits, 1)
+	atomic.StoreInt64(&e.accessed, now)
+}
+
+// expired reports whether the entry had expired at now, which it has once its expiration time is reached.
+func (e *entry[V]) expired(now int64) bool {
+	return e.expiration > 0 && now >= e.expiration
+}
+
+// eviction is an item removed from the cache, waiting to be reported to the eviction callback.
+type eviction[K comparable, V any] struct {
+	key        K
+	value      V
+	reason     EvictionReason
+	expiration int64
+}
+
+// Set add an item to the cache, replacing any existing item. If the duration is 0
+// (DefaultExpiration), the cache's default expiration time is used. If it is -1
+// (NoExpiration), the item never expires.
+func (c *cache[K, V]) Set(key K, v V) {
+	c.SetWithExpireIn(key, v, DefaultExpiration)
+}
+
+// SetWithExpireIn add an item to the cache, replacing any existing item. If the duration is 0
+func (c *cache[K, V]) SetWithExpireIn(key K, value V, expireIn time.Duration) {
+	key = c.normalize(key)
+	c.store(key, value, expireIn, 0)
+	c.changed(key)
+}
+
+// SetWithDeadline adds an item to the cache like SetWithExpireIn, expiring at the given time
+// instead of after a duration, the end of a promotion or the expiration of a token for instance.
+// The item never expires if the deadline is the zero time, and has expired already if it passed.
+func (c *cache[K, V]) SetWithDeadline(key K, value V, deadline time.Time) {
+	key = c.normalize(key)
+	expiration := c.deadline(deadline)
+	s := c.shard(key)
+	s.mu.Lock()
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+}
+
+// SetOptions are the options of SetWithOptions. Unlike the durations of the other methods,
+// whose 0 and -1 stand for DefaultExpiration and NoExpiration, TTL is taken literally.
+type SetOptions struct {
+	// TTL is how long the item lives. The item has expired already if it is 0 or less,
+	// so that computed durations can be passed as they are.
+	TTL time.Duration
+	// NoExpiration stores an item which never expires, TTL is ignored then.
+	NoExpiration bool
+}
+
+// SetWithOptions adds an item to the cache like SetWithExpireIn, with the expiration of opts.
+// It wins over the expiration of Expirer values, like the explicit durations of the other methods.
+func (c *cache[K, V]) SetWithOptions(key K, value V, opts SetOptions) {
+	key = c.normalize(key)
+	var expiration int64
+	switch {
+	case opts.NoExpiration:
+		expiration = c.expiration(NoExpiration)
+	case opts.TTL > 0:
+		expiration = c.expiration(opts.TTL)
+	default:
+		expiration = unixDeadline(c.clock.Now())
+	}
+	s := c.shard(key)
+	s.mu.Lock()
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+}
+
+// unixDeadline returns the expiration of an item expiring at deadline.
+func unixDeadline(deadline time.Time) int64 {
+	// a deadline before 1970 must not turn into 0, which never expires.
+	if expiration := deadline.UnixNano(); expiration > 0 {
+		return expiration
+	}
+	return 1
+}
+
+// store is SetWithExpireIn recording that loading the value took delta.
+func (c *cache[K, V]) store(key K, value V, expireIn, delta time.Duration) {
+	expiration := c.expirationOf(value, expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	evicted := s.set(key, value, expiration, nil)
+	if e, ok := s.items[key]; ok {
+		e.delta = int64(delta)
+	}
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+}
+
+// Get returns the value of the item associated with the key, or nil if no item
+func (c *cache[K, V]) Get(key K) (result V, exists bool) {
+	return c.get(c.normalize(key))
+}
+
+// get is Get without transforming the key.
+func (c *cache[K, V]) get(key K) (result V, exists bool) {
+	result, exists = c.lookup(key)
+	c.stats.hit(exists)
+	if exists {
+		result = c.clone(result)
+	}
+	return result, exists
+}
+
+// lookup is Get without counting the read.
+func (c *cache[K, V]) lookup(key K) (result V, exists bool) {
+	result, exists, _ = c.read(key)
+	return result, exists
+}
+
+// read is lookup also reporting whether GetOrLoad should load the item again
+// before it expires, see WithEarlyExpiration.
+func (c *cache[K, V]) read(key K) (result V, exists, early bool) {
+	result, early, err := c.readE(key)
+	return result, err == nil, early
+}
+
+// readE is read returning why there is no item like GetE, classified under the same lock.
+func (c *cache[K, V]) readE(key K) (result V, early bool, err error) {
+	now := c.now()
+	c.hot.read(key, now)
+	s := c.shard(key)
+	s.rlock()
+	e, ok := s.get(key, now)
+	if !ok {
+		err = s.missing(key, now)
+		_, spilled := s.spilled[key]
+		s.runlock()
+		if spilled {
+			if result, ok = c.unspill(key); ok {
+				return result, false, nil
+			}
+		}
+		return result, false, err
+	}
+	e.hit(now)
+	result, ttl, due, early := e.value, time.Duration(e.expiration-e.stored), c.refresh.due(e, now), c.expiresEarly(e, now)
+	s.runlock()
+	if due {
+		c.refresh.start(c, key, ttl)
+	}
+	return result, early, nil
+}
+
+// GetWithExpiration returns the value of the item associated with the key and its expiration time.
+// If the item never expires, the returned time is the zero time.
+func (c *cache[K, V]) GetWithExpiration(key K) (result V, expiration time.Time, exists bool) {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.rlock()
+	now := c.now()
+	c.hot.read(key, now)
+	e, ok := s.get(key, now)
+	if ok {
+		e.hit(now)
+		result = e.value
+		if e.expiration > 0 {
+			expiration = time.Unix(0, e.expiration)
+		}
+	}
+	s.runlock()
+	c.stats.hit(ok)
+	if ok {
+		result = c.clone(result)
+	}
+	return result, expiration, ok
+}
+
+// TTL returns how long the item associated with the key has left before it expires,
+// or NoExpiration if it never expires.
+func (c *cache[K, V]) TTL(key K) (time.Duration, bool) {
+	_, expiration, ok := c.GetWithExpiration(key)
+	if !ok {
+		return 0, false
+	}
+	if expiration.IsZero() {
+		return NoExpiration, true
+	}
+	return expiration.Sub(c.clock.Now()), true
+}
+
+// Touch resets the expiration of the item associated with the key without changing its value,
+// only if the key already exists. otherwise, does nothing and returns false.
+func (c *cache[K, V]) Touch(key K, expireIn time.Duration) bool {
+	key = c.normalize(key)
+	expiration := c.expiration(expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	now := c.now()
+	e, ok := s.get(key, now)
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	e.expiration, e.stored = expiration, now
+	s.expiry.schedule(key, e)
+	s.mu.Unlock()
+	c.logItem(key)
+	return true
+}
+
+// Delete removes the provided key from the cache.
+func (c *cache[K, V]) Delete(key K) {
+	key = c.normalize(key)
+	c.remove(key)
+	c.changed(key)
+}
+
+// remove is Delete without publishing the key.
+func (c *cache[K, V]) remove(key K) {
+	s := c.shard(key)
+	s.mu.Lock()
+	var evicted []eviction[K, V]
+	if e, ok := s.items[key]; ok {
+		evicted = s.evict(key, e, EvictionReasonDeleted, nil)
+	} else {
+		s.unspilled(key)
+	}
+	s.mu.Unlock()
+	if evicted != nil {
+		c.report(evicted)
+	}
+}
+
+// DeleteExpired removes all expired items from the cache, and the items outdated by NewEpoch.
+func (c *cache[K, V]) DeleteExpired() {
+	var evicted []eviction[K, V]
+	for _, s := range c.shards {
+		evicted = s.deleteExpired(evicted)
+	}
+	c.report(evicted)
+}
+
+// FlushFunc removes the items matching f, but the pinned ones like Flush, and returns how many
+// were removed. The eviction callbacks are called with EvictionReasonFlushed. f is called with
+// the shards locked and must not use the cache. DeleteExpired removes the expired items only.
+func (c *cache[K, V]) FlushFunc(f func(key K, value V) bool) int {
+	return c.deleteFunc(f, EvictionReasonFlushed, false)
+}
+
+// deleteFunc removes the items matching f for reason, and the pinned ones if pinned is set,
+// and returns how many were removed.
+func (c *cache[K, V]) deleteFunc(f func(key K, value V) bool, reason EvictionReason, pinned bool) int {
+	var evicted []eviction[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, e := range s.items {
+			if (pinned || !e.pinned) && f(key, e.value) {
+				evicted = s.evict(key, e, reason, evicted)
+			}
+		}
+		s.mu.Unlock()
+	}
+	c.report(evicted)
+	c.changedEvicted(evicted)
+	return len(evicted)
+}
+
+// Add adds an item to the cache, only if the key does not already exist.
+// otherwise, it returns false and does nothing.
+func (c *cache[K, V]) Add(key K, value V) bool {
+	return c.AddWithExpireIn(key, value, DefaultExpiration)
+}
+
+// AddWithExpireIn adds an item to the cache, only if the key does not already exist.
+// otherwise, it returns false and does nothing.
+func (c *cache[K, V]) AddWithExpireIn(key K, value V, expireIn time.Duration) bool {
+	return c.add(c.normalize(key), value, expireIn) == nil
+}
+
+// add is AddWithExpireIn returning ErrAlreadyExists if the key already exists.
+func (c *cache[K, V]) add(key K, value V, expireIn time.Duration) error {
+	expiration := c.expirationOf(value, expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	if e, ok := s.items[key]; ok && !s.expired(e, c.now()) {
+		s.mu.Unlock()
+		return ErrAlreadyExists
+	}
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+	return nil
+}
+
+// GetOrSet returns the value of the item associated with the key and true if there is one,
+// and otherwise stores value with the given expiration and returns it and false, atomically,
+// like sync.Map.LoadOrStore.
+func (c *cache[K, V]) GetOrSet(key K, value V, expireIn time.Duration) (actual V, loaded bool) {
+	key = c.normalize(key)
+	expiration := c.expirationOf(value, expireIn)
+	now := c.now()
+	s := c.shard(key)
+	s.mu.Lock()
+	if e, ok := s.get(key, now); ok {
+		e.hit(now)
+		actual = e.value
+		s.mu.Unlock()
+		c.stats.hit(true)
+		return c.clone(actual), true
+	}
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	c.stats.hit(false)
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+	return value, false
+}
+
+// SetIfNotExists sets the value of the item associated with the key, only if the key does not already exist.
+// otherwise, it returns an error.
+func (c *cache[K, V]) SetIfNotExists(key K, value V) bool {
+	return c.SetIfNotExistsWithExpireIn(key, value, DefaultExpiration)
+}
+
+// SetIfNotExistsWithExpireIn sets the value of the item associated with the key, only if the key does not already exist.
+// otherwise, it returns an error.
+func (c *cache[K, V]) SetIfNotExistsWithExpireIn(key K, value V, expireIn time.Duration) bool {
+	return c.AddWithExpireIn(key, value, expireIn)
+}
+
+// Replace replaces an item in the cache, only if the key already exists.
+// otherwise, does nothing and returns false.
+func (c *cache[K, V]) Replace(key K, value V) bool {
+	return c.ReplaceWithExpireIn(key, value, DefaultExpiration)
+}
+
+// ReplaceWithExpireIn replaces an item in the cache, only if the key already exists.
+// otherwise, does nothing and returns false.
+func (c *cache[K, V]) ReplaceWithExpireIn(key K, value V, expireIn time.Duration) bool {
+	return c.replace(c.normalize(key), value, expireIn) == nil
+}
+
+// replace is ReplaceWithExpireIn returning ErrNotFound or ErrExpired if the key does not exist.
+func (c *cache[K, V]) replace(key K, value V, expireIn time.Duration) error {
+	expiration := c.expirationOf(value, expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	now := c.now()
+	if e, ok := s.items[key]; !ok || s.expired(e, now) {
+		err := s.missing(key, now)
+		s.mu.Unlock()
+		return err
+	}
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+	return nil
+}
+
+// SetIfExists sets the value of the item associated with the key, only if the key already exists.
+// otherwise, does nothing and returns false.
+func (c *cache[K, V]) SetIfExists(key K, value V) bool {
+	return c.SetIfExistsWithExpireIn(key, value, DefaultExpiration)
+}
+
+// SetIfExistsWithExpireIn sets the value of the item associated with the key, only if the key already exists.
+// otherwise, does nothing and returns false.
+func (c *cache[K, V]) SetIfExistsWithExpireIn(key K, value V, expireIn time.Duration) bool {
+	return c.ReplaceWithExpireIn(key, value, expireIn)
+}
+
+// Flush removes all items from the cache, but the pinned ones, see Pin. The eviction callbacks
+// are called with EvictionReasonFlushed, see FlushFunc to remove some of the items only.
+func (c *cache[K, V]) Flush() {
+	report := c.reporting()
+	var evicted []eviction[K, V]
+	flush := func() {
+		for _, s := range c.shards {
+			evicted = s.flush(evicted, report, true)
+		}
+	}
+	if c.wal != nil {
+		c.wal.logFlush(c, flush)
+		// the log is replayed from the flush, which removes the pinned items as well.
+		c.wal.log(c, c.pinnedKeys())
+	} else {
+		flush()
+	}
+	if c.negative != nil {
+		c.negative.Flush()
+	}
+	if c.overflow != nil {
+		c.clearOverflow()
+	}
+	c.report(evicted)
+}
+
+// ItemCount returns the number of items in the cache. This may include items that have
+// expired, but have not yet been cleaned up, see Len.
+func (c *cache[K, V]) ItemCount() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		n += len(s.items)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Len returns the number of items in the cache which have not expired. Unlike ItemCount,
+// it goes through all the items.
+func (c *cache[K, V]) Len() int {
+	now := c.now()
+	n := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for _, e := range s.items {
+			if !s.expired(e, now) {
+				n++
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// totalCost returns the total cost of the items in the cache, 0 if costs are not tracked.
+// This may include items that have expired, but have not yet been cleaned up.
+func (c *cache[K, V]) totalCost() int64 {
+	var cost int64
+	for _, s := range c.shards {
+		s.mu.RLock()
+		cost += s.cost
+		s.mu.RUnlock()
+	}
+	return cost
+}
+
+// OnEvicted sets a function that is called with the key, value and the reason
+// whenever an item is removed from the cache. Replacing an item by a Set does not
+// count as a removal. Set to nil to disable.
+func (c *cache[K, V]) OnEvicted(f func(key K, value V, reason EvictionReason)) {
+	c.mu.Lock()
+	c.onEvicted = f
+	c.mu.Unlock()
+}
+
+// expiration returns the expiration time for an item stored now for d.
+func (c *cache[K, V]) expiration(d time.Duration) int64 {
+	if d == DefaultExpiration {
+		d = c.defaultExpiration
+	}
+	if d > 0 && c.jitter > 0 {
+		d += time.Duration(float64(d) * c.jitter * (2*rand.Float64() - 1))
+	}
+	if d <= 0 {
+		if c.maxTTL <= 0 {
+			return 0
+		}
+		d = c.maxTTL
+	}
+	return c.clock.Now().Add(c.clamp(d)).UnixNano()
+}
+
+// clamp bounds the time to live d by WithMinTTL and WithMaxTTL.
+func (c *cache[K, V]) clamp(d time.Duration) time.Duration {
+	if c.minTTL > 0 && d < c.minTTL {
+		d = c.minTTL
+	}
+	if c.maxTTL > 0 && d > c.maxTTL {
+		d = c.maxTTL
+	}
+	return d
+}
+
+// deadline returns the expiration of an item expiring at t, never if t is the zero time,
+// bounded by WithMinTTL and WithMaxTTL unless t has passed.
+func (c *cache[K, V]) deadline(t time.Time) int64 {
+	if t.IsZero() {
+		return c.expiration(NoExpiration)
+	}
+	now := c.clock.Now()
+	if d := t.Sub(now); d > 0 && (c.minTTL > 0 || c.maxTTL > 0) {
+		return now.Add(c.clamp(d)).UnixNano()
+	}
+	return unixDeadline(t)
+}
+
+// now returns the current time in unix nanoseconds.
+func (c *cache[K, V]) now() int64 {
+	return c.clock.Now().UnixNano()
+}
+
+// shard returns the shard the key belongs to.
+func (c *cache[K, V]) shard(key K) *shard[K, V] {
+	if c.hash == nil {
+		return c.shards[0]
+	}
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+// partition groups keys by the index of the shard they belong to.
+func (c *cache[K, V]) partition(keys []K) [][]K {
+	if c.hash == nil {
+		return [][]K{keys}
+	}
+	parts := make([][]K, len(c.shards))
+	for _, key := range keys {
+		i := c.hash(key) % uint64(len(c.shards))
+		parts[i] = append(parts[i], key)
+	}
+	return parts
+}
+
+func mapKeys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// report passes evicted items to the eviction callback. c.mu must not be held.
+func (c *cache[K, V]) report(evicted []eviction[K, V]) {
+	if len(evicted) == 0 {
+		return
+	}
+	for _, e := range evicted {
+		c.stats.evicted(e.reason)
+		if c.log != nil {
+			c.log.evicted(e.key, e.reason)
+		}
+	}
+	if c.overflow != nil {
+		c.spill(evicted)
+	}
+	c.mu.RLock()
+	onEvicted, listeners := c.onEvicted, c.listeners
+	c.mu.RUnlock()
+	for _, e := range evicted {
+		if onEvicted != nil {
+			onEvicted(e.key, e.value, e.reason)
+		}
+		for _, listener := range listeners {
+			listener(e.key, e.value, e.reason)
+		}
+	}
+}
+
+// changed records that the keys were written or deleted, for the invalidation bus and the log.
+func (c *cache[K, V]) changed(keys ...K) {
+	c.publish(keys...)
+	if c.wal != nil {
+		c.wal.log(c, keys)
+	}
+}
+
+// changedEvicted records that the evicted items were deleted.
+func (c *cache[K, V]) changedEvicted(evicted []eviction[K, V]) {
+	if c.sub == nil && c.wal == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.changed(e.key)
+	}
+}
+
+// reporting reports whether removed items have to be collected for report.
+func (c *cache[K, V]) reporting() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.onEvicted != nil || len(c.listeners) > 0
+}
+
+// listen registers an internal eviction callback.
+func (c *cache[K, V]) listen(listener func(key K, value V, reason EvictionReason)) {
+	c.mu.Lock()
+	// copy on write, report iterates over the slice without holding c.mu.
+	listeners := make([]func(K, V, EvictionReason), len(c.listeners), len(c.listeners)+1)
+	copy(listeners, c.listeners)
+	c.listeners = append(listeners, listener)
+	c.mu.Unlock()
+}
+
+// NewKeyed returns a new KeyedCache[K, V] with the given default expiration duration and cleanup interval.
+// If the default expiration is less than one (or NoExpiration), items never expire by default.
+// If the cleanup interval is less than one, expired items are not deleted automatically
+// and DeleteExpired has to be called instead.
+func NewKeyed[K comparable, V any](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *KeyedCache[K, V] {
+	return newKeyed[K, V](1, nil, defaultExpiration, cleanupInterval, opts)
+}
+
+// NewShardedKeyed returns a new KeyedCache[K, V] which spreads its items over the given number of shards
+// using hash. Every shard has its own lock and cleanup janitor, which reduces lock contention under
+// concurrent writes. The WithMaxEntries bound is divided evenly between the shards.
+func NewShardedKeyed[K comparable, V any](shards int, hash func(K) uint64, defaultExpiration, cleanupInterval time.Duration, opts ...Option) *KeyedCache[K, V] {
+	return newKeyed[K, V](shards, hash, defaultExpiration, cleanupInterval, opts)
+}
+
+func newKeyed[K comparable, V any](shards int, hash func(K) uint64, defaultExpiration, cleanupInterval time.Duration, opts []Option) *KeyedCache[K, V] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var coarse *coarseClock
+	if o.clock == nil && o.clockResolution > 0 {
+		coarse = newCoarseClock(o.clockResolution)
+		o.clock = coarse
+	} else if o.clock == nil {
+		o.clock = realClock{}
+	}
+	if defaultExpiration == 0 {
+		defaultExpiration = NoExpiration
+	}
+	if shards < 1 {
+		shards = 1
+	}
+	shards, hash = stripes[K](o.backend, shards, hash, o.maxEntries, o.maxCost)
+	c := &cache[K, V]{
+		defaultExpiration: defaultExpiration,
+		cleanupInterval:   cleanupInterval,
+		clock:             o.clock,
+		coarse:            coarse,
+		staleFor:          o.staleFor,
+		beta:              o.beta,
+		jitter:            o.jitter,
+		minTTL:            o.minTTL,
+		maxTTL:            o.maxTTL,
+		shards:            make([]*shard[K, V], shards),
+		watchers:          newWatchers[K, V](o.watchBuffer),
+		evictions:         newEvictionFeed[V](o.evictionBuffer),
+	}
+	if shards > 1 {
+		c.hash = hash
+	}
+	config := &shardConfig[K, V]{clock: o.clock, epoch: new(uint64), staleFor: o.staleFor, newPolicy: newPolicyFunc[K](&o), newExpiry: newExpiryFunc[K, V](&o, cleanupInterval)}
+	config.stored = c.stored
+	config.entries = &sync.Pool{New: func() any { return new(entry[V]) }}
+	if o.maxEntries > 0 {
+		config.maxEntries = (o.maxEntries + shards - 1) / shards
+	}
+	if o.cost != nil {
+		costOf, ok := o.cost.(func(K, V) int64)
+		if !ok {
+			panic(fmt.Sprintf("cache: WithCost function %T does not match cache of %T keys and %T values", o.cost, *new(K), *new(V)))
+		}
+		config.costOf = costOf
+		c.sized = true
+	}
+	if o.bus != nil {
+		c.subscribe(o.bus)
+	}
+	if o.negativeTTL > 0 || o.backoff != nil {
+		c.negativeTTL, c.backoff = o.negativeTTL, o.backoff
+		c.negative = newKeyed[K, error](shards, hash, NoExpiration, cleanupInterval, []Option{WithClock(o.clock)})
+	}
+	if o.refreshLoader != nil {
+		c.refresh = newRefresher[K, V](o.refreshFactor, o.refreshLoader)
+	}
+	if o.hotWindow > 0 || o.hotSampleRate > 0 {
+		c.hot = newHotKeys[K](o.hotWindow, o.hotSampleRate, c.now())
+	}
+	c.copier = newCopier[V](&o)
+	c.expiresAt = newExpiresAt[V]()
+	if o.keyTransform != nil {
+		transform, ok := any(o.keyTransform).(func(K) K)
+		if !ok {
+			panic(fmt.Sprintf("cache: WithKeyTransform does not match cache of %T keys", *new(K)))
+		}
+		c.transform = transform
+	}
+	c.log = o.logger
+	if o.migrate != nil {
+		migrate, ok := o.migrate.(func(DumpHeader, []byte) ([]DumpItem[K, V], error))
+		if !ok {
+			panic(fmt.Sprintf("cache: WithMigration function %T does not match cache of %T keys and %T values", o.migrate, *new(K), *new(V)))
+		}
+		c.migrate = migrate
+	}
+	if o.dumpCodec != nil {
+		codec, ok := o.dumpCodec.(Codec[[]DumpItem[K, V]])
+		if !ok {
+			panic(fmt.Sprintf("cache: WithDumpCodec codec %T does not match cache of %T keys and %T values", o.dumpCodec, *new(K), *new(V)))
+		}
+		c.dumpCodec, c.dumpCodecName = codec, o.dumpCodecName
+	}
+	if o.overflow != nil {
+		overflow, ok := o.overflow.(Overflow[K, V])
+		if !ok {
+			panic(fmt.Sprintf("cache: WithOverflow %T does not match cache of %T keys and %T values", o.overflow, *new(K), *new(V)))
+		}
+		c.overflow = overflow
+		config.forget = c.forget
+	}
+	if o.maxCost > 0 {
+		config.maxCost = (o.maxCost + int64(shards) - 1) / int64(shards)
+		if config.costOf == nil {
+			config.costOf = func(K, V) int64 { return 1 }
+		}
+	}
+	for i := range c.shards {
+		c.shards[i] = newShard[K, V](config)
+	}
+	// This trick ensures that the janitor goroutines (which are running
+	// DeleteExpired on the shards of c forever) do not keep the returned
+	// KeyedCache object from being garbage collected. When it is garbage
+	// collected, the finalizer stops the janitor goroutines, after which
+	// c can be collected. The same goes for the invalidation bus handler,
+	// the snapshot goroutine, the janitors started by StartJanitor and the memory checks.
+	k := &KeyedCache[K, V]{c}
+	c.startJanitors(cleanupInterval)
+	if o.pressure != nil {
+		c.watchMemory(*o.pressure)
+	}
+	// the log holds the writes made after the snapshot was taken.
+	if o.snapshotPath != "" {
+		c.restoreSnapshot(o.snapshotPath)
+	}
+	if o.logPath != "" {
+		c.openLog(o.logPath)
+	}
+	if o.snapshotPath != "" {
+		c.startSnapshots(o.snapshotPath, o.snapshotInterval)
+	}
+	runtime.SetFinalizer(k, stop[K, V])
+	return k
+}
+
+// stop stops the background goroutines of k and unsubscribes it from its invalidation bus.
+func stop[K comparable, V any](k *KeyedCache[K, V]) {
+	k.shutdown()
+}
+
+// shutdown stops the background goroutines of c, unsubscribes it from its invalidation bus
+// and closes its log, the first time it is called.
+func (c *cache[K, V]) shutdown() {
+	c.stopped.Do(func() {
+		c.janitorMu.Lock()
+		c.stopJanitors()
+		c.janitorMu.Unlock()
+		if c.sub != nil {
+			c.sub.unsubscribe()
+		}
+		if c.snapshots != nil {
+			c.snapshots.stop()
+		}
+		if c.pressure != nil {
+			c.pressure.stop()
+		}
+		if c.wal != nil {
+			c.wal.close()
+		}
+		if c.coarse != nil {
+			c.coarse.stop()
+		}
+	})
+}
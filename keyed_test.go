@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestKeyedCache(t *testing.T) {
+	type point struct{ x, y int }
+	c := NewKeyed[point, string](time.Second, 0)
+	c.Set(point{1, 2}, "foo")
+	if v, ok := c.Get(point{1, 2}); !ok || v != "foo" {
+		t.Errorf("expected {1 2} to be foo, got %v", v)
+	}
+	if _, ok := c.Get(point{2, 1}); ok {
+		t.Errorf("expected {2 1} to not exist")
+	}
+	if ok := c.Add(point{1, 2}, "bar"); ok {
+		t.Errorf("expected {1 2} to not be added")
+	}
+}
+
+func TestKeyedCacheDumpLoad(t *testing.T) {
+	c := NewKeyed[int, string](NoExpiration, 0)
+	c.Set(1, "foo")
+	c.SetWithExpireIn(2, "bar", time.Minute)
+	var buf bytes.Buffer
+	if err := c.DumpTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewKeyed[int, string](NoExpiration, 0)
+	loaded.Set(1, "baz")
+	if err := loaded.LoadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := loaded.Get(1); v != "baz" {
+		t.Errorf("expected 1 to be kept as baz, got %v", v)
+	}
+	if v, _ := loaded.Get(2); v != "bar" {
+		t.Errorf("expected 2 to be bar, got %v", v)
+	}
+}
+
+func TestGetWithExpiration(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](NoExpiration, 0, WithClock(clock))
+	c.SetWithExpireIn("a", 1, time.Minute)
+	c.Set("b", 2)
+	if v, expiration, ok := c.GetWithExpiration("a"); !ok || v != 1 || !expiration.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("expected a to be 1 expiring in a minute, got %v, %v", v, expiration)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != NoExpiration {
+		t.Errorf("expected b to never expire, got %v", ttl)
+	}
+	if _, ok := c.TTL("c"); ok {
+		t.Errorf("expected c to not exist")
+	}
+}
+
+func TestSetWithDeadline(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](time.Second, 0, WithClock(clock))
+	deadline := clock.Now().Add(time.Hour)
+	c.SetWithDeadline("a", 1, deadline)
+	c.SetWithDeadline("b", 2, time.Time{})
+	c.SetWithDeadline("c", 3, clock.Now().Add(-time.Second))
+	if _, expiration, ok := c.GetWithExpiration("a"); !ok || !expiration.Equal(deadline) {
+		t.Errorf("expected a to expire at %v, got %v", deadline, expiration)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != NoExpiration {
+		t.Errorf("expected b to never expire, got %v", ttl)
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Errorf("expected c to have expired")
+	}
+	clock.Advance(time.Hour)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to expire at its deadline")
+	}
+}
+
+func TestTouch(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](NoExpiration, 0, WithClock(clock))
+	c.SetWithExpireIn("a", 1, time.Millisecond*5)
+	if ok := c.Touch("a", time.Minute); !ok {
+		t.Errorf("expected a to be touched")
+	}
+	clock.Advance(time.Millisecond * 10)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a to be 1, got %v", v)
+	}
+	if ok := c.Touch("b", time.Minute); ok {
+		t.Errorf("expected b to not be touched")
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	c := NewKeyed[string, int](NoExpiration, 0)
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	stored := make([]bool, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, loaded := c.GetOrSet("a", i, DefaultExpiration)
+			results[i], stored[i] = v, !loaded
+		}(i)
+	}
+	wg.Wait()
+	winner, _ := c.Get("a")
+	n := 0
+	for i, v := range results {
+		if v != winner {
+			t.Errorf("expected every call to return %d, got %d", winner, v)
+		}
+		if stored[i] {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected a single call to store its value, got %d", n)
+	}
+}
+
+func TestLen(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](NoExpiration, 0, WithClock(clock))
+	c.Set("a", 1)
+	c.SetWithExpireIn("b", 2, time.Second)
+	clock.Advance(time.Second)
+	if n := c.Len(); n != 1 {
+		t.Errorf("expected 1 live item, got %d", n)
+	}
+	if n := c.ItemCount(); n != 2 {
+		t.Errorf("expected 2 items before the cleanup, got %d", n)
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[int, int](time.Minute, 0, WithClock(clock), WithTTLJitter(0.1))
+	ttls := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		c.Set(i, i)
+		ttl, _ := c.TTL(i)
+		if ttl < time.Second*54 || ttl > time.Second*66 {
+			t.Errorf("expected ttl within 10%% of a minute, got %v", ttl)
+		}
+		ttls[ttl] = struct{}{}
+	}
+	if len(ttls) < 2 {
+		t.Errorf("expected ttls to be randomized, got %v", ttls)
+	}
+	c.SetWithExpireIn(0, 0, NoExpiration)
+	if ttl, _ := c.TTL(0); ttl != NoExpiration {
+		t.Errorf("expected items without expiration to be left alone, got %v", ttl)
+	}
+}
+
+func TestSetWithOptions(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](time.Minute, 0, WithClock(clock))
+	c.SetWithOptions("ttl", 1, SetOptions{TTL: time.Hour})
+	c.SetWithOptions("forever", 2, SetOptions{TTL: time.Hour, NoExpiration: true})
+	c.Set("zero", 3)
+	c.SetWithOptions("zero", 3, SetOptions{})
+	c.SetWithOptions("negative", 4, SetOptions{TTL: -time.Second})
+	if ttl, ok := c.TTL("ttl"); !ok || ttl != time.Hour {
+		t.Errorf("expected ttl to live for an hour, got %v", ttl)
+	}
+	if ttl, ok := c.TTL("forever"); !ok || ttl != NoExpiration {
+		t.Errorf("expected forever to never expire, got %v", ttl)
+	}
+	for _, key := range []string{"zero", "negative"} {
+		if _, ok := c.Get(key); ok {
+			t.Errorf("expected %s to have expired right away", key)
+		}
+	}
+}
+
+func TestTTLBounds(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewKeyed[string, int](NoExpiration, 0, WithClock(clock), WithMinTTL(time.Second), WithMaxTTL(time.Hour))
+	c.Set("default", 1)
+	c.SetWithExpireIn("short", 2, time.Millisecond)
+	c.SetWithExpireIn("long", 3, 24*time.Hour)
+	c.SetWithExpireIn("within", 4, time.Minute)
+	c.SetWithDeadline("deadline", 5, clock.Now().Add(48*time.Hour))
+	for key, want := range map[string]time.Duration{
+		"default":  time.Hour,
+		"short":    time.Second,
+		"long":     time.Hour,
+		"within":   time.Minute,
+		"deadline": time.Hour,
+	} {
+		if ttl, ok := c.TTL(key); !ok || ttl != want {
+			t.Errorf("expected %s to live for %v, got %v", key, want, ttl)
+		}
+	}
+	c.SetWithDeadline("passed", 6, clock.Now().Add(-time.Second))
+	if _, ok := c.Get("passed"); ok {
+		t.Errorf("expected a passed deadline to be left alone")
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	for _, strategy := range []ExpirationStrategy{ExpirationHeap, TimingWheel} {
+		// a whole second, so that items expire at the end of the ticks of the wheel.
+		clock := cachetest.NewClock(time.Unix(1e9, 0))
+		c := NewKeyed[int, int](NoExpiration, 0, WithClock(clock), WithExpirationStrategy(strategy))
+		for i := 0; i < 100; i++ {
+			c.SetWithExpireIn(i, i, time.Duration(i+1)*time.Second)
+		}
+		c.Touch(10, NoExpiration)
+		c.Touch(90, time.Second)
+		c.SetWithExpireIn(20, 20, time.Hour)
+		c.Set(30, 30)
+		c.Delete(40)
+
+		clock.Advance(time.Second * 50)
+		c.DeleteExpired()
+		if n := c.ItemCount(); n != 52 {
+			t.Errorf("%v: expected 52 items, got %d", strategy, n)
+		}
+		for key := 0; key < 100; key++ {
+			_, ok := c.Get(key)
+			want := key >= 50 && key != 90 || key == 10 || key == 20 || key == 30
+			if ok != want {
+				t.Errorf("%v: expected %d to be kept: %v, got %v", strategy, key, want, ok)
+			}
+		}
+
+		clock.Advance(time.Hour)
+		c.DeleteExpired()
+		if n := c.ItemCount(); n != 2 {
+			t.Errorf("%v: expected the items which never expire to be kept, got %d items", strategy, n)
+		}
+	}
+}
+
+func TestTimingWheel(t *testing.T) {
+	start := time.Unix(1e9, 0)
+	clock := cachetest.NewClock(start)
+	c := NewKeyed[int, int](NoExpiration, 0, WithClock(clock), WithExpirationStrategy(TimingWheel))
+	// spread over every level of the wheel, and past it.
+	ttls := []time.Duration{time.Second, time.Minute + time.Second, time.Hour, time.Hour * 24 * 3, time.Hour * 24 * 400}
+	for i, ttl := range ttls {
+		c.SetWithExpireIn(i, i, ttl)
+	}
+	for i, ttl := range ttls {
+		clock.Advance(start.Add(ttl).Sub(clock.Now()) - time.Second)
+		c.DeleteExpired()
+		if n := c.ItemCount(); n != len(ttls)-i {
+			t.Errorf("expected %d to be kept a second before it expires, got %d items", i, n)
+		}
+		clock.Advance(time.Second)
+		c.DeleteExpired()
+		if n := c.ItemCount(); n != len(ttls)-i-1 {
+			t.Errorf("expected %d to be deleted once it expired, got %d items", i, n)
+		}
+	}
+}
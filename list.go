@@ -0,0 +1,67 @@
+package cache
+
+import "time"
+
+// ListCache is a cache of lists of items keyed by strings.
+// Its operations are atomic under the lock of the shard holding the key. Stored lists are never
+// modified, every change stores a copy, so the lists returned by Get can be read without locking
+// but must not be modified.
+type ListCache[T any] struct {
+	*GenericCache[[]T]
+}
+
+// NewListCache returns a new ListCache[T] with the given default expiration duration and cleanup interval.
+// A new list gets the default expiration, use Touch to change the expiration of a key.
+func NewListCache[T any](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *ListCache[T] {
+	return &ListCache[T]{GenericCache: New[[]T](defaultExpiration, cleanupInterval, opts...)}
+}
+
+// Append appends the items to the list associated with the key, creating it if needed,
+// and returns the length of the list. An existing list keeps its expiration.
+func (l *ListCache[T]) Append(key string, items ...T) int {
+	list, _ := l.Update(key, func(current []T, _ bool) ([]T, bool) {
+		if len(items) == 0 {
+			return current, false
+		}
+		list := make([]T, 0, len(current)+len(items))
+		return append(append(list, current...), items...), true
+	})
+	return len(list)
+}
+
+// Trim keeps the last max items of the list associated with the key, the most recently appended,
+// and returns how many were removed. The key is deleted once its list is empty.
+func (l *ListCache[T]) Trim(key string, max int) int {
+	if max < 0 {
+		max = 0
+	}
+	var removed int
+	l.Update(key, func(current []T, _ bool) ([]T, bool) {
+		if len(current) <= max {
+			return current, false
+		}
+		removed = len(current) - max
+		return append([]T(nil), current[removed:]...), true
+	})
+	if removed > 0 {
+		l.CompareAndDeleteFunc(key, nil, func(current, _ []T) bool {
+			return len(current) == 0
+		})
+	}
+	return removed
+}
+
+// Len returns the length of the list associated with the key.
+func (l *ListCache[T]) Len(key string) int {
+	list, _ := l.Get(key)
+	return len(list)
+}
+
+// GetAll returns a copy of the list associated with the key, nil if there is none.
+func (l *ListCache[T]) GetAll(key string) []T {
+	list, _ := l.Get(key)
+	if len(list) == 0 {
+		return nil
+	}
+	return append([]T(nil), list...)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListCache(t *testing.T) {
+	c := NewListCache[int](NoExpiration, 0)
+	if n := c.Append("foo", 1, 2, 3); n != 3 {
+		t.Errorf("expected length 3, got %d", n)
+	}
+	held, _ := c.Get("foo")
+	if n := c.Append("foo", 4, 5); n != 5 {
+		t.Errorf("expected length 5, got %d", n)
+	}
+	if n := c.Trim("foo", 3); n != 2 {
+		t.Errorf("expected 2 items to be removed, got %d", n)
+	}
+	if len(held) != 3 || held[0] != 1 {
+		t.Errorf("expected a list returned by Get not to be modified, got %v", held)
+	}
+	all := c.GetAll("foo")
+	if len(all) != 3 || all[0] != 3 || all[2] != 5 {
+		t.Errorf("expected the last 3 items [3 4 5], got %v", all)
+	}
+	all[0] = 42
+	if v := c.GetAll("foo"); v[0] != 3 {
+		t.Error("expected GetAll to return a copy")
+	}
+	if n := c.Trim("foo", 3); n != 0 {
+		t.Errorf("expected nothing to be removed, got %d", n)
+	}
+	c.Trim("foo", 0)
+	if _, ok := c.Get("foo"); ok {
+		t.Error("expected an empty list to be deleted")
+	}
+	if c.Len("foo") != 0 || c.GetAll("foo") != nil {
+		t.Error("expected a missing list to be empty")
+	}
+}
+
+func TestListCacheConcurrent(t *testing.T) {
+	c := NewListCache[int](time.Minute, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Append("foo", i)
+			c.Trim("foo", 50)
+			c.GetAll("foo")
+		}(i)
+	}
+	wg.Wait()
+	if n := c.Len("foo"); n != 50 {
+		t.Errorf("expected 50 items, got %d", n)
+	}
+}
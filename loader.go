@@ -0,0 +1,116 @@
+package cache
+
+import "time"
+
+// GetOrLoad returns the value of the item associated with the key. If there is no such item,
+// it calls loader and stores the returned value with the default expiration.
+// Concurrent calls for the same key share a single loader call.
+// Loader errors are returned to every waiting caller and nothing is stored.
+func (c *cache[K, V]) GetOrLoad(key K, loader func() (V, error)) (V, error) {
+	return c.GetOrLoadWithExpireIn(key, loader, DefaultExpiration)
+}
+
+// GetOrLoadWithExpireIn is like GetOrLoad, but stores the loaded value with the given expiration.
+func (c *cache[K, V]) GetOrLoadWithExpireIn(key K, loader func() (V, error), expireIn time.Duration) (V, error) {
+	return c.getOrLoad(key, func() (V, time.Duration, error) {
+		v, err := loader()
+		return v, expireIn, err
+	})
+}
+
+// GetOrLoadWithTTL is like GetOrLoad, but stores the loaded value with the expiration returned
+// by loader, so that it can depend on the value, such as the max-age of an HTTP response.
+// As for SetWithExpireIn, DefaultExpiration selects the default expiration of the cache
+// and NoExpiration means the value never expires.
+func (c *cache[K, V]) GetOrLoadWithTTL(key K, loader func() (V, time.Duration, error)) (V, error) {
+	return c.getOrLoad(key, loader)
+}
+
+// getOrLoad is GetOrLoad with a loader returning the expiration of the value it loaded.
+func (c *cache[K, V]) getOrLoad(key K, loader func() (V, time.Duration, error)) (V, error) {
+	return c.getOrLoadCounting(key, loader, nil)
+}
+
+// getOrLoadCounting is getOrLoad also counting whether the item was found in views, if not nil,
+// so that the views of the cache keeping their own statistics share its read.
+func (c *cache[K, V]) getOrLoadCounting(key K, loader func() (V, time.Duration, error), views *counters) (V, error) {
+	if err := c.isClosed(); err != nil {
+		var zero V
+		return zero, err
+	}
+	key = c.normalize(key)
+	v, ok, early := c.read(key)
+	c.stats.hit(ok)
+	if views != nil {
+		views.hit(ok)
+	}
+	if ok {
+		if early {
+			return c.clone(c.loadEarly(key, v, c.loadFunc(key, loader, false))), nil
+		}
+		return c.clone(v), nil
+	}
+	load := c.loadFunc(key, loader, true)
+	if v, ok := c.lookupStale(key); ok {
+		c.loads.doAsync(key, load)
+		return c.clone(v), nil
+	}
+	if err := c.failed(key); err != nil {
+		var zero V
+		return zero, err
+	}
+	v, err := c.loads.do(key, load)
+	if err != nil {
+		return v, err
+	}
+	return c.clone(v), nil
+}
+
+// loadFunc returns the function loading the item associated with the key through c.loads.
+// With recheck, an item stored while waiting for the group is returned instead of being loaded.
+func (c *cache[K, V]) loadFunc(key K, loader func() (V, time.Duration, error), recheck bool) func() (V, error) {
+	return func() (V, error) {
+		if recheck {
+			if v, ok := c.lookup(key); ok {
+				return v, nil
+			}
+		}
+		start := c.clock.Now()
+		v, expireIn, err := loader()
+		if err != nil {
+			c.loaded(key, err)
+			return v, err
+		}
+		c.store(key, v, expireIn, c.clock.Now().Sub(start))
+		if c.backoff != nil {
+			c.negative.remove(key)
+		}
+		return v, nil
+	}
+}
+
+// loadEarly loads the item associated with the key before it expires,
+// returning its current value v if loading fails.
+func (c *cache[K, V]) loadEarly(key K, v V, load func() (V, error)) V {
+	if loaded, err := c.loads.do(key, load); err == nil {
+		return loaded
+	}
+	return v
+}
+
+// lookupStale returns the value of the item associated with the key if it has expired,
+// but less than the WithStaleWhileRevalidate duration ago.
+func (c *cache[K, V]) lookupStale(key K) (result V, stale bool) {
+	if c.staleFor <= 0 {
+		return
+	}
+	now := c.now()
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.items[key]
+	if !ok || s.outdated(e) || !e.expired(now) || e.expired(now-int64(c.staleFor)) {
+		return
+	}
+	return e.value, true
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	var calls int32
+	release := make(chan struct{})
+	loader := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "bar", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.GetOrLoad("foo", loader); err != nil || v != "bar" {
+				t.Errorf("expected foo to be bar, got %v, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected loader to be called once, got %d", n)
+	}
+	if v, ok := c.Get("foo"); !ok || v != "bar" {
+		t.Errorf("expected foo to be bar, got %v", v)
+	}
+
+	errLoad := errors.New("load failed")
+	if _, err := c.GetOrLoad("baz", func() (string, error) { return "", errLoad }); err != errLoad {
+		t.Errorf("expected %v, got %v", errLoad, err)
+	}
+	if _, ok := c.Get("baz"); ok {
+		t.Errorf("expected baz to not be set")
+	}
+}
+
+func TestGetOrLoadStaleWhileRevalidate(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](time.Minute, 0, WithClock(clock), WithStaleWhileRevalidate(time.Minute))
+	c.Set("foo", 1)
+	clock.Advance(time.Minute + time.Second)
+
+	loaded := make(chan struct{})
+	v, err := c.GetOrLoad("foo", func() (int, error) {
+		defer close(loaded)
+		return 2, nil
+	})
+	if err != nil || v != 1 {
+		t.Errorf("expected stale foo to be 1, got %v, %v", v, err)
+	}
+	<-loaded
+	// the refresh stores the value right after the loader returns.
+	for i := 0; i < 100; i++ {
+		if v, ok := c.Get("foo"); ok {
+			if v != 2 {
+				t.Errorf("expected foo to be refreshed to 2, got %v", v)
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Minute * 3)
+	c.DeleteExpired()
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected items past the stale cutoff to be deleted, got %d", n)
+	}
+	v, err = c.GetOrLoad("foo", func() (int, error) { return 3, nil })
+	if err != nil || v != 3 {
+		t.Errorf("expected foo to be loaded as 3, got %v, %v", v, err)
+	}
+}
+
+func TestGetOrLoadNegativeCaching(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, 0, WithClock(clock), WithNegativeCaching(time.Second*10))
+	var calls int
+	loader := func() (string, error) {
+		calls++
+		return "", fmt.Errorf("user foo: %w", ErrNotFound)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetOrLoad("foo", loader); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the not found result to be cached, got %d loader calls", calls)
+	}
+
+	clock.Advance(time.Second * 10)
+	if _, err := c.GetOrLoad("foo", loader); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the not found result to expire, got %d loader calls", calls)
+	}
+
+	c.Set("foo", "bar")
+	if v, err := c.GetOrLoad("foo", loader); err != nil || v != "bar" {
+		t.Errorf("expected a stored item to take precedence, got %v, %v", v, err)
+	}
+
+	errBackend := errors.New("backend down")
+	for i := 0; i < 2; i++ {
+		c.GetOrLoad("baz", func() (string, error) {
+			calls++
+			return "", errBackend
+		})
+	}
+	if calls != 4 {
+		t.Errorf("expected other errors not to be cached, got %d loader calls", calls)
+	}
+}
+
+func TestGetOrLoadErrorBackoff(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, 0, WithClock(clock), WithErrorBackoff(ExponentialBackoff(time.Second, 3*time.Second)))
+	var calls int
+	errBackend := errors.New("backend down")
+	failing := func() (string, error) {
+		calls++
+		return "", errBackend
+	}
+	// the errors are remembered for 1s, 2s, then 3s.
+	for _, d := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		before := calls
+		if _, err := c.GetOrLoad("foo", failing); err != errBackend {
+			t.Errorf("expected the loader error, got %v", err)
+		}
+		clock.Advance(d - time.Millisecond)
+		if _, err := c.GetOrLoad("foo", failing); err != errBackend {
+			t.Errorf("expected the remembered error, got %v", err)
+		}
+		if calls != before+1 {
+			t.Errorf("expected the error to be remembered for %v, got %d loader calls", d, calls-before)
+		}
+		clock.Advance(time.Millisecond)
+	}
+
+	if v, err := c.GetOrLoad("foo", func() (string, error) { return "bar", nil }); err != nil || v != "bar" {
+		t.Errorf("expected bar once the backend is back, got %v, %v", v, err)
+	}
+	c.Delete("foo")
+	c.GetOrLoad("foo", failing)
+	clock.Advance(time.Second)
+	calls = 0
+	c.GetOrLoad("foo", failing)
+	if calls != 1 {
+		t.Errorf("expected a success to reset the backoff, got %d loader calls", calls)
+	}
+}
+
+func TestGetOrLoadWithTTL(t *testing.T) {
+	clock := cachetest.NewClock(time.Unix(0, 0))
+	c := New[string](time.Hour, 0, WithClock(clock))
+	ttls := map[string]time.Duration{"short": time.Second, "default": DefaultExpiration, "forever": NoExpiration}
+	for key, ttl := range ttls {
+		ttl := ttl
+		if v, err := c.GetOrLoadWithTTL(key, func() (string, time.Duration, error) { return "v", ttl, nil }); err != nil || v != "v" {
+			t.Errorf("expected %s to be loaded, got %v, %v", key, v, err)
+		}
+	}
+	want := map[string]time.Time{"short": clock.Now().Add(time.Second), "default": clock.Now().Add(time.Hour), "forever": {}}
+	for key, expiration := range want {
+		if _, got, ok := c.GetWithExpiration(key); !ok || !got.Equal(expiration) {
+			t.Errorf("expected %s to expire at %v, got %v", key, expiration, got)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package cache
+
+import "time"
+
+// logHook receives the background events of a cache, see WithLogger. It is an interface so
+// that the package builds with versions of Go older than log/slog.
+type logHook interface {
+	evicted(key any, reason EvictionReason)
+	loadFailed(key any, err error)
+	persistFailed(err error)
+	cleaned(expired int, took time.Duration)
+}
+
+// cleanup deletes the expired items of the shard, as the janitor does every cleanup interval.
+func (c *cache[K, V]) cleanup(s *shard[K, V]) {
+	start := c.clock.Now()
+	evicted := s.deleteExpired(nil)
+	if c.log != nil {
+		c.log.cleaned(len(evicted), c.clock.Now().Sub(start))
+	}
+	c.report(evicted)
+}
@@ -0,0 +1,52 @@
+package cache
+
+import "container/list"
+
+// lru is the least recently used Policy.
+type lru[K comparable] struct {
+	ll    *list.List
+	elems map[K]*list.Element
+}
+
+func newLRU[K comparable]() Policy[K] {
+	return &lru[K]{ll: list.New(), elems: make(map[K]*list.Element)}
+}
+
+// Add marks key as the most recently used one, adding it if necessary.
+func (l *lru[K]) Add(key K) {
+	if e, ok := l.elems[key]; ok {
+		l.ll.MoveToFront(e)
+		return
+	}
+	l.elems[key] = l.ll.PushFront(key)
+}
+
+// Access marks key as the most recently used one if it is tracked.
+func (l *lru[K]) Access(key K) {
+	if e, ok := l.elems[key]; ok {
+		l.ll.MoveToFront(e)
+	}
+}
+
+// Remove stops tracking key.
+func (l *lru[K]) Remove(key K) {
+	if e, ok := l.elems[key]; ok {
+		l.ll.Remove(e)
+		delete(l.elems, key)
+	}
+}
+
+// Victim returns the least recently used key.
+func (l *lru[K]) Victim() (key K, ok bool) {
+	e := l.ll.Back()
+	if e == nil {
+		return
+	}
+	return e.Value.(K), true
+}
+
+// Reset stops tracking all keys.
+func (l *lru[K]) Reset() {
+	l.ll.Init()
+	l.elems = make(map[K]*list.Element)
+}
@@ -0,0 +1,457 @@
+// Package memcached serves a cache with the memcached text protocol, so that existing memcached
+// clients can use it, for example as a local memcached in tests.
+//
+// The storage commands (set, add, replace, append, prepend, cas), get, gets, delete, incr, decr,
+// touch, flush_all, version and quit are supported. flush_all ignores its delay.
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// Item is a value stored by the memcached protocol.
+type Item struct {
+	Value []byte
+	// Flags are opaque to the server, clients use them to describe the value.
+	Flags uint32
+	// CAS changes every time the item is stored, see the cas and gets commands.
+	CAS uint64
+}
+
+const (
+	// maxKeyLength is the maximum length of the keys in the memcached protocol.
+	maxKeyLength = 250
+	// relativeExptimeLimit is the largest exptime which is relative to now, larger ones are unix times.
+	relativeExptimeLimit = 60 * 60 * 24 * 30
+	// version is reported by the version command.
+	version = "1.6.0-cache"
+)
+
+// Option configures a Server.
+type Option func(*options)
+
+type options struct {
+	maxValueSize int
+}
+
+// WithMaxValueSize sets the maximum size of the values in bytes, 1 MiB by default like memcached.
+func WithMaxValueSize(n int) Option {
+	return func(o *options) {
+		o.maxValueSize = n
+	}
+}
+
+// Server serves a cache with the memcached text protocol.
+type Server struct {
+	cache        *cache.GenericCache[Item]
+	maxValueSize int
+	cas          uint64
+
+	// mu protects the fields below.
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("memcached: server closed")
+
+// New returns a Server storing its items in c.
+func New(c *cache.GenericCache[Item], opts ...Option) *Server {
+	o := options{maxValueSize: 1 << 20}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Server{
+		cache:        c,
+		maxValueSize: o.maxValueSize,
+		listeners:    make(map[net.Listener]struct{}),
+		conns:        make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and serves the connections, see Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves the connections accepted by l until Close is called, then returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, l)
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes the listeners and the connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return
+		}
+		quit, err := s.handle(line, r, w)
+		if err != nil {
+			return
+		}
+		// replies are sent once the pipelined commands have been read.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// readLine reads a line terminated by \r\n, or \n, without its terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// clientError is a malformed command, replied to with CLIENT_ERROR.
+type clientError string
+
+func (e clientError) Error() string { return string(e) }
+
+// handle runs the command line, reading its data block from r, and writes the reply to w.
+// It returns an error if the connection has to be closed.
+func (s *Server) handle(line string, r *bufio.Reader, w *bufio.Writer) (quit bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		_, err = w.WriteString("ERROR\r\n")
+		return false, err
+	}
+	var reply string
+	switch cmd, args := fields[0], fields[1:]; cmd {
+	case "get", "gets":
+		return false, s.get(w, args, cmd == "gets")
+	case "set", "add", "replace", "append", "prepend", "cas":
+		reply, err = s.store(cmd, args, r)
+	case "delete":
+		reply, err = s.delete(args)
+	case "incr", "decr":
+		reply, err = s.incr(args, cmd == "decr")
+	case "touch":
+		reply, err = s.touch(args)
+	case "flush_all":
+		s.cache.Flush()
+		reply = "OK"
+	case "version":
+		reply = "VERSION " + version
+	case "quit":
+		return true, nil
+	default:
+		reply = "ERROR"
+	}
+	var ce clientError
+	if errors.As(err, &ce) {
+		reply, err = "CLIENT_ERROR "+ce.Error(), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if noreply(fields) {
+		return false, nil
+	}
+	_, err = w.WriteString(reply + "\r\n")
+	return false, err
+}
+
+// noreply reports whether the command asks not to be replied to.
+func noreply(fields []string) bool {
+	return len(fields) > 1 && fields[len(fields)-1] == "noreply"
+}
+
+func (s *Server) get(w *bufio.Writer, keys []string, withCAS bool) error {
+	if len(keys) == 0 {
+		_, err := w.WriteString("ERROR\r\n")
+		return err
+	}
+	for _, key := range keys {
+		item, ok := s.cache.Get(key)
+		if !ok {
+			continue
+		}
+		if withCAS {
+			fmt.Fprintf(w, "VALUE %s %d %d %d\r\n", key, item.Flags, len(item.Value), item.CAS)
+		} else {
+			fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, item.Flags, len(item.Value))
+		}
+		w.Write(item.Value)
+		w.WriteString("\r\n")
+	}
+	_, err := w.WriteString("END\r\n")
+	return err
+}
+
+// store runs a storage command: <cmd> <key> <flags> <exptime> <bytes> [<cas unique>] [noreply].
+func (s *Server) store(cmd string, args []string, r *bufio.Reader) (string, error) {
+	n := 4
+	if cmd == "cas" {
+		n = 5
+	}
+	if len(args) < n || len(args) > n+1 || len(args) == n+1 && args[n] != "noreply" {
+		return "", clientError("bad command line format")
+	}
+	key := args[0]
+	flags, err1 := strconv.ParseUint(args[1], 10, 32)
+	exptime, err2 := strconv.ParseInt(args[2], 10, 64)
+	size, err3 := strconv.Atoi(args[3])
+	var unique uint64
+	var err4 error
+	if cmd == "cas" {
+		unique, err4 = strconv.ParseUint(args[4], 10, 64)
+	}
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || size < 0 {
+		return "", clientError("bad command line format")
+	}
+	if size > s.maxValueSize {
+		// the data block cannot be skipped safely, the connection is closed.
+		return "", fmt.Errorf("memcached: value of %d bytes is too large", size)
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	if string(data[size:]) != "\r\n" {
+		return "", clientError("bad data chunk")
+	}
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	value := data[:size]
+	ttl, expired := expireIn(exptime)
+	item := Item{Value: value, Flags: uint32(flags), CAS: s.nextCAS()}
+
+	switch cmd {
+	case "set":
+		if expired {
+			s.cache.Delete(key)
+		} else {
+			s.cache.SetWithExpireIn(key, item, ttl)
+		}
+		return "STORED", nil
+	case "add":
+		if expired {
+			return "STORED", nil
+		}
+		if !s.cache.AddWithExpireIn(key, item, ttl) {
+			return "NOT_STORED", nil
+		}
+		return "STORED", nil
+	case "replace":
+		if !s.cache.ReplaceWithExpireIn(key, item, ttl) {
+			return "NOT_STORED", nil
+		}
+		if expired {
+			s.cache.Delete(key)
+		}
+		return "STORED", nil
+	case "append", "prepend":
+		// the flags and the expiration of the item are kept.
+		_, ok := s.cache.Update(key, func(current Item, exists bool) (Item, bool) {
+			if !exists {
+				return current, false
+			}
+			joined := make([]byte, 0, len(current.Value)+len(value))
+			if cmd == "append" {
+				joined = append(append(joined, current.Value...), value...)
+			} else {
+				joined = append(append(joined, value...), current.Value...)
+			}
+			return Item{Value: joined, Flags: current.Flags, CAS: item.CAS}, true
+		})
+		if !ok {
+			return "NOT_STORED", nil
+		}
+		return "STORED", nil
+	default: // cas
+		reply := "NOT_FOUND"
+		s.cache.Update(key, func(current Item, exists bool) (Item, bool) {
+			switch {
+			case !exists:
+				return current, false
+			case current.CAS != unique:
+				reply = "EXISTS"
+				return current, false
+			}
+			reply = "STORED"
+			return item, true
+		})
+		if reply == "STORED" {
+			if expired {
+				s.cache.Delete(key)
+			} else {
+				s.cache.Touch(key, ttl)
+			}
+		}
+		return reply, nil
+	}
+}
+
+// delete runs delete <key> [noreply].
+func (s *Server) delete(args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 || len(args) == 2 && args[1] != "noreply" {
+		return "", clientError("bad command line format")
+	}
+	if _, ok := s.cache.Pop(args[0]); !ok {
+		return "NOT_FOUND", nil
+	}
+	return "DELETED", nil
+}
+
+// incr runs incr|decr <key> <value> [noreply]. Decrementing below zero gives zero, and
+// incrementing wraps around at 64 bits, like memcached does.
+func (s *Server) incr(args []string, decr bool) (string, error) {
+	if len(args) < 2 || len(args) > 3 || len(args) == 3 && args[2] != "noreply" {
+		return "", clientError("bad command line format")
+	}
+	delta, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return "", clientError("invalid numeric delta argument")
+	}
+	reply := "NOT_FOUND"
+	s.cache.Update(args[0], func(current Item, exists bool) (Item, bool) {
+		if !exists {
+			return current, false
+		}
+		n, err := strconv.ParseUint(string(current.Value), 10, 64)
+		if err != nil {
+			reply = "CLIENT_ERROR cannot increment or decrement non-numeric value"
+			return current, false
+		}
+		switch {
+		case !decr:
+			n += delta
+		case delta > n:
+			n = 0
+		default:
+			n -= delta
+		}
+		reply = strconv.FormatUint(n, 10)
+		return Item{Value: []byte(reply), Flags: current.Flags, CAS: s.nextCAS()}, true
+	})
+	return reply, nil
+}
+
+// touch runs touch <key> <exptime> [noreply].
+func (s *Server) touch(args []string) (string, error) {
+	if len(args) < 2 || len(args) > 3 || len(args) == 3 && args[2] != "noreply" {
+		return "", clientError("bad command line format")
+	}
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return "", clientError("invalid exptime argument")
+	}
+	ttl, expired := expireIn(exptime)
+	if expired {
+		if _, ok := s.cache.Pop(args[0]); !ok {
+			return "NOT_FOUND", nil
+		}
+		return "TOUCHED", nil
+	}
+	if !s.cache.Touch(args[0], ttl) {
+		return "NOT_FOUND", nil
+	}
+	return "TOUCHED", nil
+}
+
+func (s *Server) nextCAS() uint64 {
+	return atomic.AddUint64(&s.cas, 1)
+}
+
+// checkKey reports whether key is a valid memcached key.
+func checkKey(key string) error {
+	if len(key) > maxKeyLength {
+		return clientError("key too long")
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return clientError("invalid key")
+		}
+	}
+	return nil
+}
+
+// expireIn converts a memcached exptime to the expiration of the cache: 0 never expires,
+// up to 30 days it is a number of seconds, and a unix time beyond. expired is set if the
+// item has to expire right away.
+func expireIn(exptime int64) (ttl time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return cache.NoExpiration, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= relativeExptimeLimit:
+		return time.Duration(exptime) * time.Second, false
+	}
+	ttl = time.Until(time.Unix(exptime, 0))
+	return ttl, ttl <= 0
+}
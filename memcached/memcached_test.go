@@ -0,0 +1,113 @@
+package memcached
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// session sends commands to a server and reads its replies.
+type session struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newSession(t *testing.T, opts ...Option) (*session, *cache.GenericCache[Item]) {
+	c := cache.New[Item](cache.NoExpiration, 0)
+	s := New(c, opts...)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &session{t: t, conn: conn, r: bufio.NewReader(conn)}, c
+}
+
+// do sends the command and checks that the server replies with the lines of want.
+func (s *session) do(command string, want ...string) {
+	s.t.Helper()
+	if _, err := s.conn.Write([]byte(command)); err != nil {
+		s.t.Fatal(err)
+	}
+	s.conn.SetReadDeadline(time.Now().Add(time.Second))
+	for _, line := range want {
+		got, err := s.r.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("%q: expected %q, got %v", command, line, err)
+		}
+		if got != line+"\r\n" {
+			s.t.Errorf("%q: expected %q, got %q", command, line, strings.TrimSpace(got))
+		}
+	}
+}
+
+func TestStorageCommands(t *testing.T) {
+	s, c := newSession(t)
+	s.do("set foo 5 0 3\r\nbar\r\n", "STORED")
+	s.do("get foo baz\r\n", "VALUE foo 5 3", "bar", "END")
+	s.do("add foo 0 0 1\r\nx\r\n", "NOT_STORED")
+	s.do("add baz 0 0 1\r\nx\r\n", "STORED")
+	s.do("replace qux 0 0 1\r\nx\r\n", "NOT_STORED")
+	s.do("replace baz 0 0 1\r\ny\r\n", "STORED")
+	s.do("append foo 0 0 2\r\n!!\r\n", "STORED")
+	s.do("prepend foo 0 0 2\r\n<<\r\n", "STORED")
+	s.do("append qux 0 0 1\r\nx\r\n", "NOT_STORED")
+	s.do("get foo\r\n", "VALUE foo 5 7", "<<bar!!", "END")
+	s.do("set quiet 0 0 1 noreply\r\nx\r\nget quiet\r\n", "VALUE quiet 0 1", "x", "END")
+
+	item, _ := c.Get("baz")
+	s.do("gets baz\r\n", "VALUE baz 0 1 "+strconv.FormatUint(item.CAS, 10), "y", "END")
+	s.do("cas baz 0 0 1 "+strconv.FormatUint(item.CAS+100, 10)+"\r\nz\r\n", "EXISTS")
+	s.do("cas baz 0 0 1 "+strconv.FormatUint(item.CAS, 10)+"\r\nz\r\n", "STORED")
+	s.do("cas qux 0 0 1 1\r\nz\r\n", "NOT_FOUND")
+	s.do("get baz\r\n", "VALUE baz 0 1", "z", "END")
+
+	s.do("set foo 0 0 3\r\nbarbaz\r\n", "CLIENT_ERROR bad data chunk")
+}
+
+func TestOtherCommands(t *testing.T) {
+	s, c := newSession(t)
+	s.do("set n 0 0 2\r\n10\r\n", "STORED")
+	s.do("incr n 5\r\n", "15")
+	s.do("decr n 20\r\n", "0")
+	s.do("incr missing 1\r\n", "NOT_FOUND")
+	s.do("set s 0 0 1\r\nx\r\n", "STORED")
+	s.do("incr s 1\r\n", "CLIENT_ERROR cannot increment or decrement non-numeric value")
+
+	s.do("touch n 100\r\n", "TOUCHED")
+	if ttl, _ := c.TTL("n"); ttl <= 0 || ttl > 100*time.Second {
+		t.Errorf("expected touch to set the ttl, got %v", ttl)
+	}
+	s.do("touch missing 100\r\n", "NOT_FOUND")
+	s.do("set gone 0 -1 1\r\nx\r\n", "STORED")
+	s.do("get gone\r\n", "END")
+
+	s.do("delete n\r\n", "DELETED")
+	s.do("delete n\r\n", "NOT_FOUND")
+	s.do("flush_all\r\n", "OK")
+	s.do("get s\r\n", "END")
+	s.do("version\r\n", "VERSION "+version)
+	s.do("bogus\r\n", "ERROR")
+	s.do("get "+strings.Repeat("k", 10)+"\r\n", "END")
+	s.do("set "+strings.Repeat("k", 251)+" 0 0 1\r\nx\r\n", "CLIENT_ERROR key too long")
+}
+
+func TestMaxValueSize(t *testing.T) {
+	s, _ := newSession(t, WithMaxValueSize(4))
+	s.conn.Write([]byte("set foo 0 0 5\r\nhello\r\n"))
+	s.conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := s.r.ReadString('\n'); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build go1.19
+
+package cache
+
+import (
+	"math"
+	"runtime/debug"
+)
+
+// memoryLimit returns the limit set by GOMEMLIMIT or debug.SetMemoryLimit, 0 if there is none.
+func memoryLimit() uint64 {
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		return 0
+	}
+	return uint64(limit)
+}
@@ -0,0 +1,8 @@
+//go:build !go1.19
+
+package cache
+
+// memoryLimit returns 0, since memory limits require Go 1.19.
+func memoryLimit() uint64 {
+	return 0
+}
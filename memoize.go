@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Memoize returns a function calling fn once per argument and caching its results in c for ttl,
+// see GetOrLoadWithExpireIn. Concurrent calls with the same argument share a single call to fn,
+// and errors are not cached. The key of an argument is the argument itself for strings, and its
+// %#v formatting otherwise, so c should only be used by one memoized function.
+func Memoize[A comparable, R any](c *GenericCache[R], fn func(A) (R, error), ttl time.Duration) func(A) (R, error) {
+	return func(arg A) (R, error) {
+		return c.GetOrLoadWithExpireIn(memoKey(arg), func() (R, error) {
+			return fn(arg)
+		}, ttl)
+	}
+}
+
+// MemoizeCtx is like Memoize for functions taking a context, see GetOrLoadWithExpireInCtx.
+func MemoizeCtx[A comparable, R any](c *GenericCache[R], fn func(context.Context, A) (R, error), ttl time.Duration) func(context.Context, A) (R, error) {
+	return func(ctx context.Context, arg A) (R, error) {
+		return c.GetOrLoadWithExpireInCtx(ctx, memoKey(arg), func(ctx context.Context) (R, error) {
+			return fn(ctx, arg)
+		}, ttl)
+	}
+}
+
+// memoKey returns the key of a memoized function argument.
+func memoKey[A comparable](arg A) string {
+	if s, ok := any(arg).(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%#v", arg)
+}
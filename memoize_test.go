@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	type point struct{ X, Y int }
+	var calls int32
+	release := make(chan struct{})
+	distance := Memoize(New[int](NoExpiration, 0), func(p point) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		if p.X < 0 {
+			return 0, errors.New("negative")
+		}
+		return p.X + p.Y, nil
+	}, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := distance(point{1, 2}); err != nil || v != 3 {
+				t.Errorf("expected 3, got %v, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	distance(point{2, 1})
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected a call per argument, got %d", n)
+	}
+	distance(point{-1, 0})
+	if _, err := distance(point{-1, 0}); err == nil || atomic.LoadInt32(&calls) != 4 {
+		t.Errorf("expected errors not to be cached, got %v and %d calls", err, calls)
+	}
+}
+
+func TestMemoizeCtx(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	greet := MemoizeCtx(c, func(ctx context.Context, name string) (string, error) {
+		return "hello " + name, ctx.Err()
+	}, time.Minute)
+	if v, err := greet(context.Background(), "foo"); err != nil || v != "hello foo" {
+		t.Errorf("expected hello foo, got %v, %v", v, err)
+	}
+	if v, ok := c.Get("foo"); !ok || v != "hello foo" {
+		t.Errorf("expected string arguments to be the keys, got %v", v)
+	}
+}
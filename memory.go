@@ -0,0 +1,55 @@
+package cache
+
+import "unsafe"
+
+// Sizer is implemented by values knowing how much memory they reference, see EstimateMemory.
+type Sizer interface {
+	// Size returns the number of bytes referenced by the value, not counting the value itself.
+	Size() int64
+}
+
+// EstimateMemory returns an estimate of the memory taken by the items of the cache, in bytes.
+// It counts the fixed size of every item and its bookkeeping, plus the cost of the item if
+// WithCost is set, or else the bytes referenced by its key and value when they are strings,
+// byte slices, string slices or Sizers. The bytes referenced by other types are not counted.
+// It goes through all the items, expired ones included.
+func (c *cache[K, V]) EstimateMemory() int64 {
+	var e entry[V]
+	var key K
+	// the entry, the pointer to it and the key in the map.
+	overhead := int64(unsafe.Sizeof(e)) + int64(unsafe.Sizeof(&e)) + int64(unsafe.Sizeof(key))
+	var total int64
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for key, e := range s.items {
+			total += overhead
+			if c.sized {
+				total += e.cost
+			} else {
+				total += referenced(key) + referenced(e.value)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return total
+}
+
+// referenced returns the number of bytes referenced by v, if it is a Sizer or a common type.
+func referenced(v any) int64 {
+	switch v := v.(type) {
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(cap(v))
+	case []string:
+		n := int64(cap(v)) * int64(unsafe.Sizeof(""))
+		for _, s := range v {
+			n += int64(len(s))
+		}
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+type sized []int
+
+func (s sized) Size() int64 {
+	return int64(cap(s)) * 8
+}
+
+func TestEstimateMemory(t *testing.T) {
+	c := New[string](NoExpiration, 0)
+	empty := c.EstimateMemory()
+	c.Set("a", strings.Repeat("x", 1000))
+	small := c.EstimateMemory()
+	if small < empty+1001 {
+		t.Errorf("expected the key and the value to be counted, got %d", small-empty)
+	}
+	c.Set("b", "")
+	if n := c.EstimateMemory(); n <= small+1 {
+		t.Errorf("expected the overhead of an item to be counted, got %d", n-small)
+	}
+
+	s := New[sized](NoExpiration, 0)
+	s.Set("a", make(sized, 100))
+	if n := s.EstimateMemory(); n < 801 {
+		t.Errorf("expected the size of the Sizer to be counted, got %d", n)
+	}
+
+	costs := New[int](NoExpiration, 0, WithCost(func(string, int) int64 { return 1 << 20 }))
+	costs.Set("a", 1)
+	if n := costs.EstimateMemory(); n < 1<<20 {
+		t.Errorf("expected the cost to be counted, got %d", n)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import "sync/atomic"
+
+// MergePolicy decides which item Merge keeps when both caches have one for a key.
+type MergePolicy int
+
+const (
+	// MergeKeepNewer keeps the item which expires last, which is the one written last when both
+	// were stored with the same time to live. Items which never expire are kept over the others.
+	// The existing item is kept when both expire at the same time.
+	MergeKeepNewer MergePolicy = iota
+	// MergeKeepExisting keeps the item of the cache, only the missing keys are added.
+	MergeKeepExisting
+	// MergeOverwrite replaces the item of the cache with the one of the other cache.
+	MergeOverwrite
+)
+
+// Merge copies the live items of other into the cache with their expiration times, resolving
+// the keys both caches have with policy, and returns how many items were copied. The items
+// of other are read at once across its shards, and their tags are not copied.
+// Copied items count as writes, for the invalidation bus and the WithLog log for instance.
+func (c *cache[K, V]) Merge(other *KeyedCache[K, V], policy MergePolicy) int {
+	now := c.now()
+	byKey := make(map[K]snapshotItem[K, V])
+	for _, item := range other.snapshot(now) {
+		byKey[item.key] = item
+	}
+	var (
+		evicted []eviction[K, V]
+		merged  []K
+	)
+	for i, keys := range c.partition(mapKeys(byKey)) {
+		if len(keys) == 0 {
+			continue
+		}
+		s := c.shards[i]
+		s.mu.Lock()
+		for _, key := range keys {
+			item := byKey[key]
+			if e, ok := s.items[key]; ok && !s.expired(e, now) && !policy.replaces(e.expiration, item.expiration) {
+				continue
+			}
+			evicted = s.set(key, other.clone(item.value), item.expiration, evicted)
+			merged = append(merged, key)
+		}
+		s.mu.Unlock()
+	}
+	atomic.AddUint64(&c.stats.sets, uint64(len(merged)))
+	c.report(evicted)
+	c.changed(merged...)
+	return len(merged)
+}
+
+// replaces reports whether an item expiring at expiration replaces an existing item
+// expiring at existing, in unix nanoseconds, 0 if they never expire.
+func (p MergePolicy) replaces(existing, expiration int64) bool {
+	switch p {
+	case MergeOverwrite:
+		return true
+	case MergeKeepNewer:
+		if existing == 0 {
+			return false
+		}
+		return expiration == 0 || expiration > existing
+	default:
+		return false
+	}
+}
+
+// Merge copies the live items of other into the cache, see KeyedCache.Merge.
+func (g *GenericCache[T]) Merge(other *GenericCache[T], policy MergePolicy) int {
+	return g.KeyedCache.Merge(other.KeyedCache, policy)
+}
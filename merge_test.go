@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestMerge(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	newCaches := func() (*GenericCache[string], *GenericCache[string]) {
+		live := NewSharded[string](4, time.Minute, 0, WithClock(clock))
+		snapshot := New[string](time.Minute, 0, WithClock(clock))
+		snapshot.Set("old", "snapshot")
+		snapshot.Set("missing", "snapshot")
+		snapshot.SetWithExpireIn("forever", "snapshot", NoExpiration)
+		snapshot.SetWithExpireIn("expired", "snapshot", time.Second)
+		clock.Advance(time.Second)
+		live.Set("old", "live")
+		live.Set("forever", "live")
+		snapshot.Set("new", "snapshot")
+		live.SetWithExpireIn("new", "live", time.Second)
+		return live, snapshot
+	}
+	tests := []struct {
+		policy MergePolicy
+		merged int
+		want   map[string]string
+	}{
+		{MergeKeepNewer, 3, map[string]string{"old": "live", "missing": "snapshot", "forever": "snapshot", "new": "snapshot"}},
+		{MergeKeepExisting, 1, map[string]string{"old": "live", "missing": "snapshot", "forever": "live", "new": "live"}},
+		{MergeOverwrite, 4, map[string]string{"old": "snapshot", "missing": "snapshot", "forever": "snapshot", "new": "snapshot"}},
+	}
+	for _, test := range tests {
+		live, snapshot := newCaches()
+		if n := live.Merge(snapshot, test.policy); n != test.merged {
+			t.Errorf("expected policy %d to merge %d items, got %d", test.policy, test.merged, n)
+		}
+		for key, want := range test.want {
+			if v, _ := live.Get(key); v != want {
+				t.Errorf("expected policy %d to keep the %s %s, got %q", test.policy, want, key, v)
+			}
+		}
+		if _, ok := live.Get("expired"); ok {
+			t.Errorf("expected policy %d not to merge expired items", test.policy)
+		}
+		if _, expiration, _ := live.GetWithExpiration("missing"); !expiration.Equal(clock.Now().Add(time.Minute - time.Second)) {
+			t.Errorf("expected the expiration to be kept, got %v", expiration)
+		}
+	}
+}
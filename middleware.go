@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is the interface of the caches keyed by strings, implemented by GenericCache and
+// the caches built on it. It lets middlewares be layered over a cache, see Wrap.
+type Cache[T any] interface {
+	// Get returns the value associated with the key, and whether it exists.
+	Get(key string) (T, bool)
+	// GetCtx is like Get, but returns the context error without reading if ctx is already done.
+	GetCtx(ctx context.Context, key string) (T, bool, error)
+	// Set adds an item to the cache, replacing any existing item, with the default expiration.
+	Set(key string, value T)
+	// SetWithExpireIn adds an item to the cache, replacing any existing item, with the given expiration.
+	SetWithExpireIn(key string, value T, expireIn time.Duration)
+	// SetCtx is like Set, but returns the context error without writing if ctx is already done.
+	SetCtx(ctx context.Context, key string, value T) error
+	// Delete removes the item associated with the key, if any.
+	Delete(key string)
+	// GetOrLoad returns the value associated with the key, calling loader and storing its
+	// result if there is none.
+	GetOrLoad(key string, loader func() (T, error)) (T, error)
+	// GetOrLoadCtx is like GetOrLoad, but passes ctx to loader.
+	GetOrLoadCtx(ctx context.Context, key string, loader func(context.Context) (T, error)) (T, error)
+}
+
+var _ Cache[any] = (*GenericCache[any])(nil)
+
+// Middleware decorates a cache, for example to log, measure or trace its calls.
+// A middleware typically embeds next and overrides the methods it intercepts:
+//
+//	type logging[T any] struct{ cache.Cache[T] }
+//
+//	func (l logging[T]) Get(key string) (T, bool) {
+//		v, ok := l.Cache.Get(key)
+//		log.Printf("get %s: %v", key, ok)
+//		return v, ok
+//	}
+type Middleware[T any] func(next Cache[T]) Cache[T]
+
+// Wrap returns c decorated by the middlewares. The first middleware is the outermost one,
+// it sees the calls first and the results last.
+func Wrap[T any](c Cache[T], mw ...Middleware[T]) Cache[T] {
+	for i := len(mw) - 1; i >= 0; i-- {
+		c = mw[i](c)
+	}
+	return c
+}
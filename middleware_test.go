@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+// recorder is a middleware recording the Get calls it sees under its name.
+type recorder[T any] struct {
+	Cache[T]
+	name  string
+	calls *[]string
+}
+
+func (r recorder[T]) Get(key string) (T, bool) {
+	*r.calls = append(*r.calls, r.name+" "+key)
+	return r.Cache.Get(key)
+}
+
+func record[T any](name string, calls *[]string) Middleware[T] {
+	return func(next Cache[T]) Cache[T] {
+		return recorder[T]{Cache: next, name: name, calls: calls}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	var calls []string
+	c := Wrap[string](New[string](NoExpiration, 0), record[string]("outer", &calls), record[string]("inner", &calls))
+	c.Set("foo", "bar")
+	if v, ok := c.Get("foo"); !ok || v != "bar" {
+		t.Errorf("expected bar, got %v", v)
+	}
+	if want := []string{"outer foo", "inner foo"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+	if v, _ := c.GetOrLoad("foo", nil); v != "bar" {
+		t.Errorf("expected the other methods to reach the cache, got %v", v)
+	}
+}
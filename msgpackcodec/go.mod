@@ -0,0 +1,12 @@
+module github.com/eatmoreapple/cache/msgpackcodec
+
+go 1.25.0
+
+require (
+	github.com/eatmoreapple/cache v0.0.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+
+replace github.com/eatmoreapple/cache => ../
@@ -0,0 +1,23 @@
+// Package msgpackcodec implements cache.Codec with MessagePack.
+package msgpackcodec
+
+import (
+	"github.com/eatmoreapple/cache"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec is a cache.Codec using MessagePack, which is more compact and faster than JSON.
+type Codec[T any] struct{}
+
+var _ cache.Codec[any] = Codec[any]{}
+
+// Encode returns the MessagePack encoding of value.
+func (Codec[T]) Encode(value T) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+// Decode parses the MessagePack encoded data.
+func (Codec[T]) Decode(data []byte) (value T, err error) {
+	err = msgpack.Unmarshal(data, &value)
+	return value, err
+}
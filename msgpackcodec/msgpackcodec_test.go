@@ -0,0 +1,33 @@
+package msgpackcodec
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+func TestCodec(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+	c := cache.NewKeyed[int, user](cache.NoExpiration, 0)
+	c.Set(1, user{"foo", 42})
+	c.SetWithExpireIn(2, user{"bar", 7}, time.Minute)
+	var buf bytes.Buffer
+	if err := c.DumpWith(&buf, Codec[[]cache.DumpItem[int, user]]{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded := cache.NewKeyed[int, user](cache.NoExpiration, 0)
+	if err := loaded.LoadWith(&buf, Codec[[]cache.DumpItem[int, user]]{}); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := loaded.Get(1); u != (user{"foo", 42}) {
+		t.Errorf("expected 1 to be foo, got %v", u)
+	}
+	if ttl, _ := loaded.TTL(2); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the expiration of 2 to be kept, got %v", ttl)
+	}
+}
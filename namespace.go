@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// NamespaceSeparator separates the name of a namespace from the keys within it.
+const NamespaceSeparator = ":"
+
+// Namespace is a view of a GenericCache whose keys are prefixed by the namespace name,
+// so that several packages can share one cache without key collisions.
+type Namespace[T any] struct {
+	// stats comes first to keep its counters 64-bit aligned for atomic access.
+	stats  counters
+	cache  *GenericCache[T]
+	name   string
+	prefix string
+	// match is the prefix of the keys of the items of the namespace, the prefix transformed
+	// by WithKeyTransform.
+	match string
+}
+
+// Namespace returns the view of the cache for the given name.
+// Calling it again with the same name returns the same view. With WithKeyTransform, the keys of
+// the namespace are matched by the transformed prefix, so the transform has to keep the prefixes
+// of the keys, like lowercasing does and hashing does not.
+func (g *GenericCache[T]) Namespace(name string) *Namespace[T] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n, ok := g.namespaces[name]; ok {
+		return n
+	}
+	prefix := name + NamespaceSeparator
+	n := &Namespace[T]{cache: g, name: name, prefix: prefix, match: g.normalize(prefix)}
+	if g.namespaces == nil {
+		g.namespaces = make(map[string]*Namespace[T])
+	}
+	g.namespaces[name] = n
+	g.listen(n.evicted)
+	return n
+}
+
+// Name returns the name of the namespace.
+func (n *Namespace[T]) Name() string {
+	return n.name
+}
+
+// Set adds an item to the namespace, replacing any existing item.
+func (n *Namespace[T]) Set(key string, value T) {
+	n.SetWithExpireIn(key, value, DefaultExpiration)
+}
+
+// SetWithExpireIn adds an item to the namespace with the given expiration, replacing any existing item.
+func (n *Namespace[T]) SetWithExpireIn(key string, value T, expireIn time.Duration) {
+	n.cache.SetWithExpireIn(n.prefix+key, value, expireIn)
+	atomic.AddUint64(&n.stats.sets, 1)
+}
+
+// Get returns the value of the item associated with the key.
+func (n *Namespace[T]) Get(key string) (T, bool) {
+	v, ok := n.cache.Get(n.prefix + key)
+	n.stats.hit(ok)
+	return v, ok
+}
+
+// Add adds an item to the namespace, only if the key does not already exist.
+func (n *Namespace[T]) Add(key string, value T) bool {
+	return n.AddWithExpireIn(key, value, DefaultExpiration)
+}
+
+// AddWithExpireIn adds an item to the namespace with the given expiration, only if the key does not already exist.
+func (n *Namespace[T]) AddWithExpireIn(key string, value T, expireIn time.Duration) bool {
+	ok := n.cache.AddWithExpireIn(n.prefix+key, value, expireIn)
+	if ok {
+		atomic.AddUint64(&n.stats.sets, 1)
+	}
+	return ok
+}
+
+// Replace replaces an item in the namespace, only if the key already exists.
+func (n *Namespace[T]) Replace(key string, value T) bool {
+	return n.ReplaceWithExpireIn(key, value, DefaultExpiration)
+}
+
+// ReplaceWithExpireIn replaces an item in the namespace with the given expiration, only if the key already exists.
+func (n *Namespace[T]) ReplaceWithExpireIn(key string, value T, expireIn time.Duration) bool {
+	ok := n.cache.ReplaceWithExpireIn(n.prefix+key, value, expireIn)
+	if ok {
+		atomic.AddUint64(&n.stats.sets, 1)
+	}
+	return ok
+}
+
+// GetOrLoad is like GenericCache.GetOrLoad within the namespace.
+func (n *Namespace[T]) GetOrLoad(key string, loader func() (T, error)) (T, error) {
+	return n.GetOrLoadWithExpireIn(key, loader, DefaultExpiration)
+}
+
+// GetOrLoadWithExpireIn is like GenericCache.GetOrLoadWithExpireIn within the namespace.
+func (n *Namespace[T]) GetOrLoadWithExpireIn(key string, loader func() (T, error), expireIn time.Duration) (T, error) {
+	return n.cache.getOrLoadCounting(n.prefix+key, func() (T, time.Duration, error) {
+		v, err := loader()
+		if err == nil {
+			atomic.AddUint64(&n.stats.sets, 1)
+		}
+		return v, expireIn, err
+	}, &n.stats)
+}
+
+// Delete removes the provided key from the namespace.
+func (n *Namespace[T]) Delete(key string) {
+	n.cache.Delete(n.prefix + key)
+}
+
+// Keys returns the keys of all live items in the namespace, without the namespace prefix.
+func (n *Namespace[T]) Keys() []string {
+	var keys []string
+	n.cache.Range(func(key string, _ T) bool {
+		if strings.HasPrefix(key, n.match) {
+			keys = append(keys, key[len(n.match):])
+		}
+		return true
+	})
+	return keys
+}
+
+// Flush removes all items from the namespace but the pinned ones, leaving the rest of the cache untouched.
+func (n *Namespace[T]) Flush() {
+	n.cache.deleteFunc(func(key string, _ T) bool {
+		return strings.HasPrefix(key, n.match)
+	}, EvictionReasonFlushed, false)
+}
+
+// Stats returns a snapshot of the namespace counters.
+func (n *Namespace[T]) Stats() Stats {
+	return n.stats.snapshot()
+}
+
+// evicted is registered as an eviction listener of the cache to count the removals within the namespace.
+func (n *Namespace[T]) evicted(key string, _ T, reason EvictionReason) {
+	if strings.HasPrefix(key, n.match) {
+		n.stats.evicted(reason)
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import "testing"
+
+func TestNamespace(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	users := c.Namespace("users")
+	if c.Namespace("users") != users {
+		t.Errorf("expected the same namespace to be returned")
+	}
+	orders := c.Namespace("orders")
+	users.Set("1", 1)
+	orders.Set("1", 2)
+	if v, ok := c.Get("users:1"); !ok || v != 1 {
+		t.Errorf("expected users:1 to be 1, got %v", v)
+	}
+	if v, ok := orders.Get("1"); !ok || v != 2 {
+		t.Errorf("expected orders 1 to be 2, got %v", v)
+	}
+	users.Get("2")
+	users.Flush()
+	if _, ok := users.Get("1"); ok {
+		t.Errorf("expected users 1 to be flushed")
+	}
+	if keys := orders.Keys(); len(keys) != 1 || keys[0] != "1" {
+		t.Errorf("expected orders to keep 1, got %v", keys)
+	}
+	orders.Delete("1")
+	expected := Stats{Hits: 0, Misses: 2, Sets: 1}
+	if stats := users.Stats(); stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+	if stats := orders.Stats(); stats.Deletes != 1 {
+		t.Errorf("expected 1 delete, got %+v", stats)
+	}
+}
+
+func TestNamespaceGetOrLoad(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	users := c.Namespace("users")
+	loader := func() (int, error) { return 1, nil }
+	for i := 0; i < 2; i++ {
+		if v, err := users.GetOrLoad("1", loader); err != nil || v != 1 {
+			t.Errorf("expected 1, got %v, %v", v, err)
+		}
+	}
+	expected := Stats{Hits: 1, Misses: 1, Sets: 1}
+	if stats := users.Stats(); stats != expected {
+		t.Errorf("expected %+v in the namespace, got %+v", expected, stats)
+	}
+	if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected a single read per call in the cache, got %+v", stats)
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by GetE and ReplaceE when there is no item for a key, and by loaders
+// when there is no value for a key. With WithNegativeCaching, GetOrLoad remembers the latter
+// instead of calling the loader again.
+var ErrNotFound = errors.New("cache: not found")
+
+// WithNegativeCaching makes GetOrLoad remember loader errors matching ErrNotFound for ttl,
+// and return them instead of calling the loader again for the same key until then.
+// Storing an item for the key takes precedence over the remembered error.
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// Backoff returns how long to remember the error of the given consecutive failed load of a key,
+// counting from 1. A duration less than one means the error is not remembered.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff returns a Backoff starting with base and doubling on every failure,
+// up to max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// WithErrorBackoff makes GetOrLoad remember loader errors for backoff(n), n being the number of
+// consecutive failures of the key, and return them instead of calling the loader again until then,
+// so that a failing backend is not called by every request. The failures are counted until
+// a load succeeds, or the key is not loaded again within twice the last duration.
+// ErrNotFound errors are left to WithNegativeCaching if both are set.
+func WithErrorBackoff(backoff Backoff) Option {
+	return func(o *options) {
+		o.backoff = backoff
+	}
+}
+
+// loadFailure is a loader error remembered by WithErrorBackoff.
+type loadFailure struct {
+	err     error
+	attempt int
+	// retry is when the loader may be called again, in unix nanoseconds.
+	retry int64
+}
+
+func (f *loadFailure) Error() string {
+	return f.err.Error()
+}
+
+func (f *loadFailure) Unwrap() error {
+	return f.err
+}
+
+// failed returns the remembered loader error of the key, nil if there is none.
+func (c *cache[K, V]) failed(key K) error {
+	if c.negative == nil {
+		return nil
+	}
+	err, _ := c.negative.lookup(key)
+	if f, ok := err.(*loadFailure); ok {
+		if c.now() < f.retry {
+			return f.err
+		}
+		return nil
+	}
+	return err
+}
+
+// loaded records that loading the key failed with err: it is logged, and remembered
+// if it is an ErrNotFound error to cache or an error to back off from.
+func (c *cache[K, V]) loaded(key K, err error) {
+	if c.log != nil {
+		c.log.loadFailed(key, err)
+	}
+	if c.negativeTTL > 0 && errors.Is(err, ErrNotFound) {
+		c.negative.SetWithExpireIn(key, err, c.negativeTTL)
+		return
+	}
+	if c.backoff == nil {
+		return
+	}
+	attempt := 1
+	if previous, ok := c.negative.lookup(key); ok {
+		if f, ok := previous.(*loadFailure); ok {
+			attempt = f.attempt + 1
+		}
+	}
+	if d := c.backoff(attempt); d > 0 {
+		c.negative.SetWithExpireIn(key, &loadFailure{err: err, attempt: attempt, retry: c.now() + int64(d)}, 2*d)
+	}
+}
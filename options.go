@@ -0,0 +1,131 @@
+package cache
+
+import "time"
+
+// Option configures a GenericCache.
+type Option func(*options)
+
+type options struct {
+	maxEntries int
+	maxCost    int64
+	// cost is a func(K, V) int64 matching the key and value types of the cache.
+	cost  any
+	clock Clock
+	// clockResolution is the WithCoarseClock resolution, ignored if clock is set.
+	clockResolution time.Duration
+	// policy is ignored if customPolicy, a func() Policy[K], is set.
+	policy       PolicyKind
+	customPolicy any
+	// expirationStrategy selects the expiration index of the shards.
+	expirationStrategy ExpirationStrategy
+	backend            Backend
+
+	staleFor time.Duration
+	// refreshLoader is a LoaderFunc[K, V] matching the key and value types of the cache.
+	refreshFactor float64
+	refreshLoader any
+	negativeTTL   time.Duration
+	backoff       Backoff
+	beta          float64
+	jitter        float64
+	minTTL        time.Duration
+	maxTTL        time.Duration
+	bus           Bus
+	// overflow is an Overflow[K, V] matching the key and value types of the cache.
+	overflow      any
+	hotWindow     time.Duration
+	hotSampleRate float64
+	keepTTL       bool
+	// maxBodySize is the WithMaxBodySize size, used by Handler only.
+	maxBodySize int
+	copyBytes   bool
+	// copier is a func(V) V matching the value type of the cache.
+	copier any
+	logger logHook
+	// watchBuffer is the size of the channels returned by Watch.
+	watchBuffer int
+	// evictionBuffer is the size of the channel returned by Evictions.
+	evictionBuffer int
+	pressure       *MemoryPressure
+	// keyTransform is the WithKeyTransform function, asserted to a func(K) K by the constructor.
+	keyTransform func(string) string
+
+	snapshotPath     string
+	snapshotInterval time.Duration
+	logPath          string
+	// migrate is a func(DumpHeader, []byte) ([]DumpItem[K, V], error) matching the key and value types of the cache.
+	migrate any
+	// dumpCodec is a Codec[[]DumpItem[K, V]] matching the key and value types of the cache.
+	dumpCodec     any
+	dumpCodecName string
+}
+
+// WithMaxEntries bounds the cache to at most n items.
+// When the bound is reached, an item is evicted according to the policy, see WithPolicy.
+// n <= 0 means the cache is unbounded, which is the default.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithClock sets the clock used to expire items and schedule the janitor.
+// It defaults to the system clock and is meant to be replaced in tests.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithMaxCost bounds the total cost of the items in the cache, as measured by the WithCost function.
+// When the bound is exceeded, items are evicted according to the policy, see WithPolicy.
+// Without WithCost, every item costs 1. maxCost <= 0 means there is no bound, which is the default.
+func WithMaxCost(maxCost int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+	}
+}
+
+// WithCost sets the function estimating the cost of an item, typically its size in bytes.
+// Its key and value types must match the ones of the cache, or the constructor panics.
+func WithCost[K comparable, V any](cost func(key K, value V) int64) Option {
+	return func(o *options) {
+		o.cost = cost
+	}
+}
+
+// WithStaleWhileRevalidate lets GetOrLoad serve items that expired less than maxStale ago,
+// while their value is reloaded in the background. Expired items are kept for maxStale
+// before DeleteExpired removes them, but other reads do not see them.
+func WithStaleWhileRevalidate(maxStale time.Duration) Option {
+	return func(o *options) {
+		o.staleFor = maxStale
+	}
+}
+
+// WithTTLJitter randomizes the time to live of every stored item by up to ±fraction of it,
+// so that items stored together, after a deploy for example, do not all expire at once.
+// A fraction of 0.1 makes an item stored for a minute expire between 54 and 66 seconds later.
+func WithTTLJitter(fraction float64) Option {
+	return func(o *options) {
+		o.jitter = fraction
+	}
+}
+
+// WithMaxTTL bounds the time to live of every stored item to d, whatever the method stores it
+// for, the items which would never expire included, as a safety net against items living forever
+// by mistake. It applies after WithTTLJitter and wins over WithMinTTL.
+func WithMaxTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.maxTTL = d
+	}
+}
+
+// WithMinTTL makes every stored item live for at least d, as a safety net against items expiring
+// right away by mistake. Items which never expire and deadlines which already passed are left as
+// they are.
+func WithMinTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.minTTL = d
+	}
+}
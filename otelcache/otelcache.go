@@ -0,0 +1,202 @@
+// Package otelcache instruments caches with OpenTelemetry traces and metrics.
+package otelcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer and the meter of the package.
+const instrumentationName = "github.com/eatmoreapple/cache/otelcache"
+
+// Option configures the instrumentation.
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attributes     []attribute.KeyValue
+}
+
+// WithTracerProvider sets the provider of the tracer, the global one by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the provider of the meter, the global one by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = provider
+	}
+}
+
+// WithName sets the cache.name attribute of the spans and metrics,
+// to tell caches apart when a process has more than one.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.attributes = append(o.attributes, attribute.String("cache.name", name))
+	}
+}
+
+// Middleware returns a cache.Middleware instrumenting the cache it wraps, see cache.Wrap.
+//
+// GetCtx, SetCtx and GetOrLoadCtx create spans, with a child cache.load span when the value
+// is loaded. The methods without a context only record metrics, since their spans would have
+// no parent. Every call increments the cache.operations counter, and loads are recorded
+// by the cache.load.duration histogram, in seconds. Reads carry a cache.hit attribute.
+func Middleware[T any](opts ...Option) cache.Middleware[T] {
+	o := options{tracerProvider: otel.GetTracerProvider(), meterProvider: otel.GetMeterProvider()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	meter := o.meterProvider.Meter(instrumentationName)
+	// the errors are reported to the global error handler, and no-op instruments returned.
+	operations, _ := meter.Int64Counter("cache.operations",
+		metric.WithDescription("Number of cache operations."))
+	loads, _ := meter.Float64Histogram("cache.load.duration",
+		metric.WithDescription("Duration of the loads of missing values."), metric.WithUnit("s"))
+	tracer := o.tracerProvider.Tracer(instrumentationName)
+	return func(next cache.Cache[T]) cache.Cache[T] {
+		return &instrumented[T]{
+			Cache:      next,
+			tracer:     tracer,
+			operations: operations,
+			loads:      loads,
+			attributes: o.attributes,
+		}
+	}
+}
+
+// instrumented is a cache instrumented by Middleware.
+type instrumented[T any] struct {
+	cache.Cache[T]
+	tracer     trace.Tracer
+	operations metric.Int64Counter
+	loads      metric.Float64Histogram
+	attributes []attribute.KeyValue
+}
+
+// Get returns the value associated with the key, and whether it exists.
+func (c *instrumented[T]) Get(key string) (T, bool) {
+	v, ok := c.Cache.Get(key)
+	c.count(context.Background(), "get", attribute.Bool("cache.hit", ok))
+	return v, ok
+}
+
+// GetCtx is like Get, but returns the context error without reading if ctx is already done.
+func (c *instrumented[T]) GetCtx(ctx context.Context, key string) (T, bool, error) {
+	ctx, span := c.start(ctx, "cache.get")
+	defer span.End()
+	v, ok, err := c.Cache.GetCtx(ctx, key)
+	if err != nil {
+		fail(span, err)
+		return v, ok, err
+	}
+	hit := attribute.Bool("cache.hit", ok)
+	span.SetAttributes(hit)
+	c.count(ctx, "get", hit)
+	return v, ok, nil
+}
+
+// Set adds an item to the cache, replacing any existing item, with the default expiration.
+func (c *instrumented[T]) Set(key string, value T) {
+	c.Cache.Set(key, value)
+	c.count(context.Background(), "set")
+}
+
+// SetWithExpireIn adds an item to the cache, replacing any existing item, with the given expiration.
+func (c *instrumented[T]) SetWithExpireIn(key string, value T, expireIn time.Duration) {
+	c.Cache.SetWithExpireIn(key, value, expireIn)
+	c.count(context.Background(), "set")
+}
+
+// SetCtx is like Set, but returns the context error without writing if ctx is already done.
+func (c *instrumented[T]) SetCtx(ctx context.Context, key string, value T) error {
+	ctx, span := c.start(ctx, "cache.set")
+	defer span.End()
+	if err := c.Cache.SetCtx(ctx, key, value); err != nil {
+		fail(span, err)
+		return err
+	}
+	c.count(ctx, "set")
+	return nil
+}
+
+// Delete removes the item associated with the key, if any.
+func (c *instrumented[T]) Delete(key string) {
+	c.Cache.Delete(key)
+	c.count(context.Background(), "delete")
+}
+
+// GetOrLoad returns the value associated with the key, calling loader and storing its
+// result if there is none.
+func (c *instrumented[T]) GetOrLoad(key string, loader func() (T, error)) (T, error) {
+	ctx := context.Background()
+	var loaded bool
+	v, err := c.Cache.GetOrLoad(key, func() (T, error) {
+		loaded = true
+		start := time.Now()
+		v, err := loader()
+		c.loads.Record(ctx, time.Since(start).Seconds(), c.measurement(attribute.Bool("cache.error", err != nil)))
+		return v, err
+	})
+	c.count(ctx, "get_or_load", attribute.Bool("cache.hit", !loaded))
+	return v, err
+}
+
+// GetOrLoadCtx is like GetOrLoad, but passes ctx to loader.
+func (c *instrumented[T]) GetOrLoadCtx(ctx context.Context, key string, loader func(context.Context) (T, error)) (T, error) {
+	ctx, span := c.start(ctx, "cache.get_or_load")
+	defer span.End()
+	var loaded bool
+	v, err := c.Cache.GetOrLoadCtx(ctx, key, func(ctx context.Context) (T, error) {
+		loaded = true
+		ctx, span := c.start(ctx, "cache.load")
+		defer span.End()
+		start := time.Now()
+		v, err := loader(ctx)
+		c.loads.Record(ctx, time.Since(start).Seconds(), c.measurement(attribute.Bool("cache.error", err != nil)))
+		if err != nil {
+			fail(span, err)
+		}
+		return v, err
+	})
+	// the loader of a concurrent caller may have run instead.
+	hit := attribute.Bool("cache.hit", !loaded)
+	span.SetAttributes(hit)
+	if err != nil {
+		fail(span, err)
+	}
+	c.count(ctx, "get_or_load", hit)
+	return v, err
+}
+
+// start starts a span with the attributes of the cache.
+func (c *instrumented[T]) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(c.attributes...))
+}
+
+// count increments the operations counter.
+func (c *instrumented[T]) count(ctx context.Context, operation string, attrs ...attribute.KeyValue) {
+	c.operations.Add(ctx, 1, c.measurement(append(attrs, attribute.String("cache.operation", operation))...))
+}
+
+// measurement returns the option recording attrs along with the attributes of the cache.
+func (c *instrumented[T]) measurement(attrs ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(append(attrs, c.attributes...)...)
+}
+
+// fail records err on the span.
+func fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
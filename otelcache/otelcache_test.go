@@ -0,0 +1,93 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eatmoreapple/cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	c := cache.Wrap[string](cache.New[string](cache.NoExpiration, 0), Middleware[string](
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithName("users"),
+	))
+	ctx := context.Background()
+	if err := c.SetCtx(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	c.GetCtx(ctx, "foo")
+	c.Get("baz")
+	errLoad := errors.New("load failed")
+	c.GetOrLoadCtx(ctx, "qux", func(context.Context) (string, error) { return "", errLoad })
+
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans.Ended() {
+		names[span.Name()] = span
+	}
+	if len(names) != 4 {
+		t.Errorf("expected set, get, get_or_load and load spans, got %v", names)
+	}
+	if span := names["cache.get"]; span == nil || !hasAttribute(span.Attributes(), attribute.Bool("cache.hit", true)) {
+		t.Error("expected the get span to be a hit")
+	}
+	load, getOrLoad := names["cache.load"], names["cache.get_or_load"]
+	if load == nil || getOrLoad == nil || load.Parent().SpanID() != getOrLoad.SpanContext().SpanID() {
+		t.Fatal("expected the load span to be a child of the get_or_load span")
+	}
+	if load.Status().Code != codes.Error {
+		t.Errorf("expected the failed load to be an error, got %v", load.Status())
+	}
+	if !hasAttribute(getOrLoad.Attributes(), attribute.String("cache.name", "users")) {
+		t.Error("expected the spans to carry the cache name")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	var loads uint64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, p := range data.DataPoints {
+				op, _ := p.Attributes.Value("cache.operation")
+				hit, _ := p.Attributes.Value("cache.hit")
+				counts[op.AsString()+" "+hit.Emit()] += p.Value
+			}
+		case metricdata.Histogram[float64]:
+			for _, p := range data.DataPoints {
+				loads += p.Count
+			}
+		}
+	}
+	want := map[string]int64{"set ": 1, "get true": 1, "get false": 1, "get_or_load false": 1}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("expected %d %q operations, got %d", n, key, counts[key])
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected 1 load to be recorded, got %d", loads)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package cache
+
+// Overflow is a second tier, typically on disk, holding the items evicted from the cache
+// to make room for others. See WithOverflow, and the boltstore module for an implementation.
+type Overflow[K comparable, V any] interface {
+	// Put stores the item with its expiration time in unix nanoseconds, 0 if it never expires.
+	Put(key K, value V, expiration int64) error
+	// Take removes the item associated with the key and returns it, ok is false if there is none.
+	Take(key K) (value V, expiration int64, ok bool, err error)
+	// Delete removes the item associated with the key, if any.
+	Delete(key K) error
+	// Clear removes all items.
+	Clear() error
+}
+
+// WithOverflow moves the items evicted because the cache is full (EvictionReasonCapacity) to o,
+// and Get moves them back into the cache when they are missing from memory.
+// The cache keeps the keys of the items it moved to o, so o is only used for these keys, and
+// items left in o by another cache are not read. Writes and deletes of these keys remove them
+// from o under the lock of their shard, so o never returns a value older than the cache.
+// Items in o are not tagged and not seen by Items, Range, FlushFunc or InvalidateTag.
+// Its key and value types must match the ones of the cache, or the constructor panics.
+// Errors of o are passed to the OnSnapshotError function, the ones of Delete while the shard of
+// the key is locked.
+func WithOverflow[K comparable, V any](o Overflow[K, V]) Option {
+	return func(opts *options) {
+		opts.overflow = o
+	}
+}
+
+// spill moves the items evicted for capacity to the overflow,
+// unless their keys were written or deleted since.
+func (c *cache[K, V]) spill(evicted []eviction[K, V]) {
+	for _, e := range evicted {
+		if e.reason != EvictionReasonCapacity {
+			continue
+		}
+		var err error
+		s := c.shard(e.key)
+		s.mu.Lock()
+		if _, ok := s.spilled[e.key]; ok {
+			err = c.overflow.Put(e.key, e.value, e.expiration)
+		}
+		s.mu.Unlock()
+		if err != nil {
+			c.persistError(err)
+		}
+	}
+}
+
+// unspill moves the item associated with the key back from the overflow,
+// unless it has expired or the key was set in the meantime.
+func (c *cache[K, V]) unspill(key K) (result V, exists bool) {
+	now := c.now()
+	s := c.shard(key)
+	s.mu.Lock()
+	if e, ok := s.get(key, now); ok {
+		result = e.value
+		s.mu.Unlock()
+		return result, true
+	}
+	if _, ok := s.spilled[key]; !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.spilled, key)
+	value, expiration, ok, err := c.overflow.Take(key)
+	if err != nil || !ok || expiration > 0 && now >= expiration {
+		s.mu.Unlock()
+		if err != nil {
+			c.persistError(err)
+		}
+		return
+	}
+	evicted := s.set(key, value, expiration, nil)
+	s.mu.Unlock()
+	c.report(evicted)
+	return value, true
+}
+
+// forget removes the key from the overflow.
+func (c *cache[K, V]) forget(key K) {
+	if err := c.overflow.Delete(key); err != nil {
+		c.persistError(err)
+	}
+}
+
+// clearOverflow removes all items from the overflow.
+func (c *cache[K, V]) clearOverflow() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.spilled = nil
+		s.mu.Unlock()
+	}
+	if err := c.overflow.Clear(); err != nil {
+		c.persistError(err)
+	}
+}
+
+// spilling records that the item evicted for capacity is moved to the overflow. s.mu must be held.
+func (s *shard[K, V]) spilling(key K) {
+	if s.spilled == nil {
+		s.spilled = make(map[K]struct{})
+	}
+	s.spilled[key] = struct{}{}
+}
+
+// unspilled removes the key from the overflow, if its item was moved there, as the key is
+// written or deleted. s.mu must be held.
+func (s *shard[K, V]) unspilled(key K) {
+	if _, ok := s.spilled[key]; ok {
+		delete(s.spilled, key)
+		s.forget(key)
+	}
+}
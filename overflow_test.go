@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// mapOverflow is an Overflow counting the calls to the store.
+type mapOverflow struct {
+	items map[string]int
+	calls int
+}
+
+func (o *mapOverflow) Put(key string, value int, _ int64) error {
+	o.calls++
+	o.items[key] = value
+	return nil
+}
+
+func (o *mapOverflow) Take(key string) (int, int64, bool, error) {
+	o.calls++
+	value, ok := o.items[key]
+	delete(o.items, key)
+	return value, 0, ok, nil
+}
+
+func (o *mapOverflow) Delete(key string) error {
+	o.calls++
+	delete(o.items, key)
+	return nil
+}
+
+func (o *mapOverflow) Clear() error {
+	o.calls++
+	o.items = make(map[string]int)
+	return nil
+}
+
+func TestOverflowSpilledKeys(t *testing.T) {
+	o := &mapOverflow{items: make(map[string]int)}
+	c := NewSharded[int](1, time.Minute, 0, WithMaxEntries(1), WithOverflow[string, int](o))
+	c.Set("a", 1)
+	c.Get("b")
+	c.Delete("b")
+	if o.calls != 0 {
+		t.Errorf("expected the overflow not to be used for keys never spilled, got %d calls", o.calls)
+	}
+
+	c.Set("b", 2)
+	if o.items["a"] != 1 {
+		t.Fatalf("expected a to be spilled, got %v", o.items)
+	}
+	c.Delete("a")
+	if _, ok := o.items["a"]; ok {
+		t.Error("expected a to be deleted from the overflow")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a to stay deleted")
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import "sync/atomic"
+
+// Pin keeps the item associated with the key from being evicted to make room for other items,
+// by the WithMaxEntries or WithMaxCost bounds or WithMemoryPressure, and from being removed by
+// Flush, until Unpin is called. The item is still removed by Delete and the other explicit
+// removals, and once it expires. Pinned items may keep a shard above its bounds.
+// Pin returns false if there is no item associated with the key.
+func (c *cache[K, V]) Pin(key K) bool {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.Lock()
+	e, ok := s.items[key]
+	if !ok || s.expired(e, c.now()) {
+		s.mu.Unlock()
+		return false
+	}
+	s.pin(key, e)
+	s.mu.Unlock()
+	c.logItem(key)
+	return true
+}
+
+// Unpin lets the item associated with the key be evicted again, see Pin.
+// It returns false if there is no pinned item associated with the key.
+func (c *cache[K, V]) Unpin(key K) bool {
+	key = c.normalize(key)
+	s := c.shard(key)
+	s.mu.Lock()
+	e, ok := s.items[key]
+	if !ok || !e.pinned {
+		s.mu.Unlock()
+		return false
+	}
+	// the item is kept by the epochs started while it was pinned.
+	e.pinned, e.epoch = false, atomic.LoadUint64(s.epoch)
+	s.pinned--
+	if s.policy != nil {
+		s.policy.unpin(key)
+	}
+	s.mu.Unlock()
+	c.logItem(key)
+	return true
+}
+
+// pinnedKeys returns the keys of the pinned items.
+func (c *cache[K, V]) pinnedKeys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		s.mu.RLock()
+		if s.pinned > 0 {
+			for key, e := range s.items {
+				if e.pinned {
+					keys = append(keys, key)
+				}
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// pin pins the item associated with the key. s.mu must be held.
+func (s *shard[K, V]) pin(key K, e *entry[V]) {
+	if e.pinned {
+		return
+	}
+	e.pinned = true
+	s.pinned++
+	if s.policy != nil {
+		s.policy.pin(key)
+	}
+}
+
+// pin stops tracking key until it is unpinned, so that it is never the victim.
+func (p *priorities[K]) pin(key K) {
+	p.levels[p.of[key]].Remove(key)
+	if p.pinned == nil {
+		p.pinned = make(map[K]struct{})
+	}
+	p.pinned[key] = struct{}{}
+}
+
+// unpin tracks key again, as if it was stored.
+func (p *priorities[K]) unpin(key K) {
+	delete(p.pinned, key)
+	p.levels[p.of[key]].Add(key)
+}
+
+// isPinned reports whether key is pinned.
+func (p *priorities[K]) isPinned(key K) bool {
+	_, ok := p.pinned[key]
+	return ok
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestPin(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(3))
+	if c.Pin("flags") {
+		t.Error("expected Pin to fail without an item")
+	}
+	c.Set("flags", 1)
+	if !c.Pin("flags") {
+		t.Error("expected flags to be pinned")
+	}
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	if _, ok := c.Get("flags"); !ok {
+		t.Error("expected the pinned item not to be evicted")
+	}
+	if n := c.ItemCount(); n != 3 {
+		t.Errorf("expected 3 items, got %d", n)
+	}
+	if info, _ := c.Inspect("flags"); !info.Pinned {
+		t.Error("expected Inspect to report the pin")
+	}
+
+	c.Flush()
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "flags" {
+		t.Errorf("expected Flush to keep the pinned item, got %v", keys)
+	}
+	c.Set("foo", 2)
+	c.Set("bar", 3)
+	c.Set("baz", 4)
+	if _, ok := c.Get("flags"); !ok {
+		t.Error("expected the pinned item to be kept after a Flush")
+	}
+
+	if !c.Unpin("flags") || c.Unpin("flags") {
+		t.Error("expected flags to be unpinned once")
+	}
+	// the unpinned item counts as stored again.
+	for i := 0; i < 3; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	if _, ok := c.Get("flags"); ok {
+		t.Error("expected the unpinned item to be evicted")
+	}
+
+	c.Set("config", 6)
+	c.Pin("config")
+	c.Delete("config")
+	if _, ok := c.Get("config"); ok {
+		t.Error("expected Delete to remove the pinned item")
+	}
+	c.Set("config", 7)
+	if info, _ := c.Inspect("config"); info.Pinned {
+		t.Error("expected the pin to be removed along with the item")
+	}
+}
+
+func TestPinExpires(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](time.Minute, 0, WithClock(clock))
+	c.Set("flags", 1)
+	c.Pin("flags")
+	clock.Advance(time.Minute)
+	c.DeleteExpired()
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected the pinned item to expire, got %d items", n)
+	}
+}
+
+func TestPinMemoryPressure(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i)
+		c.Pin(strconv.Itoa(i))
+	}
+	c.Unpin("0")
+	c.shed(1)
+	if n := c.ItemCount(); n != 9 {
+		t.Errorf("expected only the unpinned item to be shed, got %d items", n)
+	}
+}
+
+func TestPinFlushKeepsPriority(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(2))
+	c.SetWithPriority("flags", 1, DefaultExpiration, 5)
+	c.Pin("flags")
+	c.Flush()
+	c.Unpin("flags")
+	c.Set("foo", 2)
+	c.Set("bar", 3)
+	if _, ok := c.Get("flags"); !ok {
+		t.Error("expected the item to keep its priority through Flush")
+	}
+
+	c.Pin("flags")
+	var removed []string
+	c.OnEvicted(func(key string, _ int, _ EvictionReason) {
+		removed = append(removed, key)
+	})
+	c.Close()
+	if n := c.ItemCount(); n != 0 || len(removed) != 2 {
+		t.Errorf("expected Close to remove the pinned item as well, got %d items and %v removed", n, removed)
+	}
+	c.Set("flags", 1)
+	c.Set("foo", 2)
+	c.Set("bar", 3)
+	if _, ok := c.Get("flags"); ok {
+		t.Error("expected the pin to be forgotten by Close")
+	}
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Policy decides which item a bounded cache evicts when it is full.
+// Every shard of a cache has its own Policy, which is only called with the shard locked,
+// so implementations do not need to be safe for concurrent use.
+type Policy[K comparable] interface {
+	// Add records that an item was stored for key, either new or replacing another one.
+	Add(key K)
+	// Access records that the item stored for key was read.
+	Access(key K)
+	// Remove forgets key, its item was removed from the cache.
+	Remove(key K)
+	// Victim returns the key of the item to evict next, and false if no key is tracked.
+	Victim() (K, bool)
+	// Reset forgets all keys.
+	Reset()
+}
+
+// PolicyKind selects a built-in eviction Policy.
+type PolicyKind int
+
+const (
+	// LRU evicts the least recently used item first. It is the default.
+	LRU PolicyKind = iota
+	// LFU evicts the least frequently used item first, the least recently used one among equals.
+	// It suits workloads where some keys are steadily popular and scans should not flush them.
+	LFU
+)
+
+// String implements fmt.Stringer.
+func (k PolicyKind) String() string {
+	switch k {
+	case LRU:
+		return "LRU"
+	case LFU:
+		return "LFU"
+	default:
+		return fmt.Sprintf("PolicyKind(%d)", int(k))
+	}
+}
+
+// WithPolicy selects the built-in eviction policy of a bounded cache.
+func WithPolicy(kind PolicyKind) Option {
+	return func(o *options) {
+		o.policy = kind
+	}
+}
+
+// WithCustomPolicy sets the eviction policy of a bounded cache. newPolicy is called once per shard.
+// Its key type must match the one of the cache, or the constructor panics.
+func WithCustomPolicy[K comparable](newPolicy func() Policy[K]) Option {
+	return func(o *options) {
+		o.customPolicy = newPolicy
+	}
+}
+
+// newPolicyFunc returns the function creating the policy of each shard for the options.
+func newPolicyFunc[K comparable](o *options) func() Policy[K] {
+	if o.customPolicy != nil {
+		newPolicy, ok := o.customPolicy.(func() Policy[K])
+		if !ok {
+			panic(fmt.Sprintf("cache: WithCustomPolicy function %T does not match cache of %T keys", o.customPolicy, *new(K)))
+		}
+		return newPolicy
+	}
+	switch o.policy {
+	case LRU:
+		return newLRU[K]
+	case LFU:
+		return newLFU[K]
+	default:
+		panic(fmt.Sprintf("cache: unknown policy %v", o.policy))
+	}
+}
+
+// lfu is the least frequently used Policy.
+type lfu[K comparable] struct {
+	heap lfuHeap[K]
+	// seq orders accesses, to break frequency ties in favor of the most recent one.
+	seq   uint64
+	elems map[K]*lfuEntry[K]
+}
+
+type lfuEntry[K comparable] struct {
+	key   K
+	freq  uint64
+	seq   uint64
+	index int
+}
+
+func newLFU[K comparable]() Policy[K] {
+	return &lfu[K]{elems: make(map[K]*lfuEntry[K])}
+}
+
+// Add counts a use of key, adding it if necessary.
+func (l *lfu[K]) Add(key K) {
+	if _, ok := l.elems[key]; ok {
+		l.Access(key)
+		return
+	}
+	l.seq++
+	e := &lfuEntry[K]{key: key, freq: 1, seq: l.seq}
+	l.elems[key] = e
+	heap.Push(&l.heap, e)
+}
+
+// Access counts a use of key if it is tracked.
+func (l *lfu[K]) Access(key K) {
+	e, ok := l.elems[key]
+	if !ok {
+		return
+	}
+	l.seq++
+	e.freq++
+	e.seq = l.seq
+	heap.Fix(&l.heap, e.index)
+}
+
+// Remove stops tracking key.
+func (l *lfu[K]) Remove(key K) {
+	if e, ok := l.elems[key]; ok {
+		heap.Remove(&l.heap, e.index)
+		delete(l.elems, key)
+	}
+}
+
+// Victim returns the least frequently used key.
+func (l *lfu[K]) Victim() (key K, ok bool) {
+	if len(l.heap) == 0 {
+		return
+	}
+	return l.heap[0].key, true
+}
+
+// Reset stops tracking all keys.
+func (l *lfu[K]) Reset() {
+	l.heap = nil
+	l.elems = make(map[K]*lfuEntry[K])
+}
+
+// lfuHeap is a min-heap of entries by frequency, then by last use.
+type lfuHeap[K comparable] []*lfuEntry[K]
+
+func (h lfuHeap[K]) Len() int {
+	return len(h)
+}
+
+func (h lfuHeap[K]) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h lfuHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap[K]) Push(x any) {
+	e := x.(*lfuEntry[K])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap[K]) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
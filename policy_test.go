@@ -0,0 +1,67 @@
+package cache
+
+import "testing"
+
+func TestLFUPolicy(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(2), WithPolicy(LFU))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	c.Get("c")
+	c.Set("d", 4)
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("expected a to be kept")
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Errorf("expected c to be evicted")
+	}
+}
+
+// fifo evicts the oldest stored key, ignoring reads.
+type fifo struct {
+	keys []string
+}
+
+func (f *fifo) Add(key string) {
+	f.Remove(key)
+	f.keys = append(f.keys, key)
+}
+
+func (f *fifo) Access(string) {}
+
+func (f *fifo) Remove(key string) {
+	for i, k := range f.keys {
+		if k == key {
+			f.keys = append(f.keys[:i], f.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *fifo) Victim() (string, bool) {
+	if len(f.keys) == 0 {
+		return "", false
+	}
+	return f.keys[0], true
+}
+
+func (f *fifo) Reset() {
+	f.keys = nil
+}
+
+func TestCustomPolicy(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(2), WithCustomPolicy(func() Policy[string] { return &fifo{} }))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+// MemoryPressure configures WithMemoryPressure.
+type MemoryPressure struct {
+	// Limit is the memory limit of the process in bytes. If it is 0, the limit set by GOMEMLIMIT
+	// or debug.SetMemoryLimit is used with Go 1.19 or later, and nothing is evicted without one.
+	Limit uint64
+	// Threshold is the share of Limit above which items are evicted, 0.9 by default.
+	Threshold float64
+	// Evict is the share of the items evicted at every check above Threshold, 0.1 by default.
+	Evict float64
+	// Interval is how often the memory is checked, every second by default.
+	Interval time.Duration
+
+	// inUse returns the memory in use, memoryInUse outside of tests.
+	inUse func() uint64
+}
+
+// WithMemoryPressure makes the cache check the memory obtained from the OS by the Go runtime
+// every Interval, and evict a share of its items when it nears the limit of the process, with
+// EvictionReasonCapacity. Expired items are evicted first, then those chosen by the eviction
+// policy of bounded caches, or arbitrary ones. Reading the memory statistics briefly stops
+// the world, so the interval should not be too short.
+func WithMemoryPressure(pressure MemoryPressure) Option {
+	return func(o *options) {
+		if pressure.Threshold <= 0 {
+			pressure.Threshold = 0.9
+		}
+		if pressure.Evict <= 0 {
+			pressure.Evict = 0.1
+		}
+		if pressure.Interval <= 0 {
+			pressure.Interval = time.Second
+		}
+		if pressure.inUse == nil {
+			pressure.inUse = memoryInUse
+		}
+		o.pressure = &pressure
+	}
+}
+
+// memoryInUse returns the memory obtained from the OS by the runtime and not released yet,
+// which is what the memory limit applies to.
+func memoryInUse() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys - m.HeapReleased
+}
+
+// watchMemory starts checking the memory of the process every p.Interval.
+func (c *cache[K, V]) watchMemory(p MemoryPressure) {
+	c.pressure = newJanitor(c.clock, p.Interval)
+	go c.pressure.run(func() {
+		limit := p.Limit
+		if limit == 0 {
+			limit = memoryLimit()
+		}
+		if limit > 0 && float64(p.inUse()) > p.Threshold*float64(limit) {
+			c.shed(p.Evict)
+		}
+	})
+}
+
+// shed evicts the given share of the items of every shard.
+func (c *cache[K, V]) shed(share float64) {
+	now := c.now()
+	var evicted []eviction[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		evicted = s.shed(int(math.Ceil(share*float64(len(s.items)))), now, evicted)
+		s.mu.Unlock()
+	}
+	c.report(evicted)
+	c.changedEvicted(evicted)
+}
+
+// shed evicts n items, the expired ones first, then those chosen by the policy but the pinned
+// ones, and appends them to evicted. s.mu must be held.
+func (s *shard[K, V]) shed(n int, now int64, evicted []eviction[K, V]) []eviction[K, V] {
+	for key, e := range s.items {
+		if n == 0 {
+			return evicted
+		}
+		if s.expired(e, now) {
+			evicted = s.evict(key, e, EvictionReasonExpired, evicted)
+			n--
+		}
+	}
+	for ; n > 0 && len(s.items) > 0; n-- {
+		var victim K
+		ok := false
+		if s.policy != nil {
+			victim, ok = s.policy.Victim()
+		}
+		if !ok {
+			for key, e := range s.items {
+				if !e.pinned {
+					victim, ok = key, true
+					break
+				}
+			}
+		}
+		if !ok {
+			// only pinned items are left.
+			break
+		}
+		evicted = s.evict(victim, s.items[victim], EvictionReasonCapacity, evicted)
+	}
+	return evicted
+}
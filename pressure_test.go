@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestMemoryPressure(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	var inUse uint64 = 50
+	c := New[int](NoExpiration, 0, WithClock(clock), WithMemoryPressure(MemoryPressure{
+		Limit:    100,
+		Evict:    0.25,
+		Interval: time.Second,
+		inUse:    func() uint64 { return atomic.LoadUint64(&inUse) },
+	}))
+	defer c.Close()
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	clock.Advance(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if n := c.ItemCount(); n != 100 {
+		t.Errorf("expected nothing to be evicted below the threshold, got %d items", n)
+	}
+	atomic.StoreUint64(&inUse, 95)
+	// the check may not be waiting on the clock yet.
+	for i := 0; i < 100 && c.ItemCount() == 100; i++ {
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	if n := c.ItemCount(); n != 75 && n != 56 {
+		t.Errorf("expected a quarter of the items to be evicted, got %d items", n)
+	}
+}
+
+func TestShedExpiredFirst(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](NoExpiration, 0, WithClock(clock))
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	c.SetWithExpireIn("expired", 0, time.Second)
+	clock.Advance(time.Second)
+	c.shed(0.01)
+	if n := c.ItemCount(); n != 10 {
+		t.Errorf("expected a single item to be evicted, got %d items", n)
+	}
+	if _, ok := c.Get("0"); !ok {
+		t.Error("expected the expired item to be evicted first")
+	}
+	c.shed(0.5)
+	if n := c.ItemCount(); n != 5 {
+		t.Errorf("expected half the items to be evicted, got %d items", n)
+	}
+}
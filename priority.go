@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// SetWithPriority adds an item to the cache like SetWithExpireIn, with a priority deciding when
+// a bounded cache evicts it: the items of the lowest priority are all evicted, in the order of
+// the eviction policy, before any item of a higher priority. The items stored by the other
+// methods have priority 0, and storing an item again with them resets its priority.
+func (c *cache[K, V]) SetWithPriority(key K, value V, expireIn time.Duration, priority int) {
+	key = c.normalize(key)
+	expiration := c.expirationOf(value, expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	evicted := s.setWithPriority(key, value, expiration, priority, nil)
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+}
+
+// priorities is the Policy of a bounded shard, it runs a Policy per priority
+// and picks the victims from the lowest priority first.
+type priorities[K comparable] struct {
+	newPolicy func() Policy[K]
+	// levels holds the policy of every priority which was used, sorted holds them in order.
+	levels map[int]Policy[K]
+	sorted []int
+	// of holds the priority of the keys which do not have priority 0.
+	of map[K]int
+	// pinned holds the pinned keys, which are not tracked by the policies, see Pin.
+	pinned map[K]struct{}
+}
+
+func newPriorities[K comparable](newPolicy func() Policy[K]) *priorities[K] {
+	return &priorities[K]{
+		newPolicy: newPolicy,
+		levels:    map[int]Policy[K]{0: newPolicy()},
+		sorted:    []int{0},
+		of:        make(map[K]int),
+	}
+}
+
+// prioritize sets the priority of key, which is tracked by the policy of that priority once
+// it is added. If key is already tracked with another priority, it is forgotten until then.
+func (p *priorities[K]) prioritize(key K, priority int) {
+	current := p.of[key]
+	if current == priority {
+		return
+	}
+	p.levels[current].Remove(key)
+	if priority == 0 {
+		delete(p.of, key)
+		return
+	}
+	p.of[key] = priority
+	if _, ok := p.levels[priority]; !ok {
+		p.levels[priority] = p.newPolicy()
+		i := sort.SearchInts(p.sorted, priority)
+		p.sorted = append(p.sorted, 0)
+		copy(p.sorted[i+1:], p.sorted[i:])
+		p.sorted[i] = priority
+	}
+}
+
+// priority returns the priority of key.
+func (p *priorities[K]) priority(key K) int {
+	return p.of[key]
+}
+
+func (p *priorities[K]) Add(key K) {
+	if !p.isPinned(key) {
+		p.levels[p.of[key]].Add(key)
+	}
+}
+
+func (p *priorities[K]) Access(key K) {
+	if !p.isPinned(key) {
+		p.levels[p.of[key]].Access(key)
+	}
+}
+
+func (p *priorities[K]) Remove(key K) {
+	p.levels[p.of[key]].Remove(key)
+	delete(p.of, key)
+	delete(p.pinned, key)
+}
+
+// Victim returns the victim of the lowest priority tracking a key.
+func (p *priorities[K]) Victim() (K, bool) {
+	for _, priority := range p.sorted {
+		if key, ok := p.levels[priority].Victim(); ok {
+			return key, true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+func (p *priorities[K]) Reset() {
+	for _, policy := range p.levels {
+		policy.Reset()
+	}
+	p.of = make(map[K]int)
+	p.pinned = nil
+}
+
+// priority returns the priority of the item associated with the key. s.mu must be held.
+func (s *shard[K, V]) priority(key K) int {
+	if s.policy == nil {
+		return 0
+	}
+	return s.policy.priority(key)
+}
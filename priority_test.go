@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSetWithPriority(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithMaxEntries(4))
+	c.SetWithPriority("expensive", 1, DefaultExpiration, 10)
+	c.SetWithPriority("medium", 2, DefaultExpiration, 5)
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	for _, key := range []string{"expensive", "medium", "8", "9"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+
+	// within a priority, the eviction policy decides.
+	c.SetWithPriority("a", 3, DefaultExpiration, 5)
+	if _, ok := c.Get("medium"); !ok {
+		t.Error("expected medium to be kept over the items of priority 0")
+	}
+	c.SetWithPriority("b", 4, DefaultExpiration, 5)
+	c.SetWithPriority("c", 5, DefaultExpiration, 5)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected the least recently used item of priority 5 to be evicted")
+	}
+	if info, ok := c.Inspect("expensive"); !ok || info.Priority != 10 {
+		t.Errorf("expected expensive to have priority 10, got %d", info.Priority)
+	}
+
+	c.Set("expensive", 6)
+	if info, _ := c.Inspect("expensive"); info.Priority != 0 {
+		t.Errorf("expected Set to reset the priority, got %d", info.Priority)
+	}
+	c.SetWithPriority("d", 7, DefaultExpiration, 1)
+	if _, ok := c.Get("expensive"); ok {
+		t.Error("expected expensive to be evicted first once its priority is reset")
+	}
+}
@@ -0,0 +1,148 @@
+// Package promcache exports cache statistics as Prometheus metrics.
+package promcache
+
+import (
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is a cache the collector reads from.
+// *cache.GenericCache and *cache.KeyedCache implement it.
+type Source interface {
+	Stats() cache.Stats
+	ItemCount() int
+}
+
+// Option configures a Collector.
+type Option func(*options)
+
+type options struct {
+	namespace   string
+	subsystem   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// WithNamespace sets the namespace of the exported metrics.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithSubsystem sets the subsystem of the exported metrics, "cache" by default.
+func WithSubsystem(subsystem string) Option {
+	return func(o *options) {
+		o.subsystem = subsystem
+	}
+}
+
+// WithConstLabels attaches labels to all exported metrics,
+// for example the name of the cache when a process has more than one.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(o *options) {
+		o.constLabels = labels
+	}
+}
+
+// WithBuckets sets the buckets of the load latency histogram, in seconds.
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// Collector is a prometheus.Collector exporting the statistics of a cache.
+type Collector struct {
+	source Source
+
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	sets      *prometheus.Desc
+	deletes   *prometheus.Desc
+	evictions *prometheus.Desc
+	expired   *prometheus.Desc
+	hitRatio  *prometheus.Desc
+	entries   *prometheus.Desc
+
+	loads *prometheus.HistogramVec
+}
+
+// New returns a Collector exporting the statistics of source.
+func New(source Source, opts ...Option) *Collector {
+	o := options{subsystem: "cache", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(o.namespace, o.subsystem, name), help, nil, o.constLabels)
+	}
+	return &Collector{
+		source:    source,
+		hits:      desc("hits_total", "Number of reads that found an item."),
+		misses:    desc("misses_total", "Number of reads that found no item."),
+		sets:      desc("sets_total", "Number of items written."),
+		deletes:   desc("deletes_total", "Number of items deleted."),
+		evictions: desc("evictions_total", "Number of items evicted to make room for others."),
+		expired:   desc("expired_total", "Number of expired items removed."),
+		hitRatio:  desc("hit_ratio", "Share of reads that found an item."),
+		entries:   desc("entries", "Number of items in the cache, including expired ones not cleaned up yet."),
+		loads: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   o.namespace,
+			Subsystem:   o.subsystem,
+			Name:        "load_duration_seconds",
+			Help:        "Latency of loads on cache misses.",
+			ConstLabels: o.constLabels,
+			Buckets:     o.buckets,
+		}, []string{"result"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.sets
+	ch <- c.deletes
+	ch <- c.evictions
+	ch <- c.expired
+	ch <- c.hitRatio
+	ch <- c.entries
+	c.loads.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.source.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.sets, prometheus.CounterValue, float64(stats.Sets))
+	ch <- prometheus.MustNewConstMetric(c.deletes, prometheus.CounterValue, float64(stats.Deletes))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(stats.Expired))
+	ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, stats.HitRatio())
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(c.source.ItemCount()))
+	c.loads.Collect(ch)
+}
+
+// ObserveLoad records the latency of a load.
+func (c *Collector) ObserveLoad(d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	c.loads.WithLabelValues(result).Observe(d.Seconds())
+}
+
+// Loader wraps loader so that its latency is recorded by c.
+// It is meant to be passed to GetOrLoad.
+func Loader[T any](c *Collector, loader func() (T, error)) func() (T, error) {
+	return func() (T, error) {
+		start := time.Now()
+		v, err := loader()
+		c.ObserveLoad(time.Since(start), err)
+		return v, err
+	}
+}
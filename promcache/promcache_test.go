@@ -0,0 +1,40 @@
+package promcache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := cache.New[string](cache.NoExpiration, 0)
+	collector := New(c, WithNamespace("app"), WithConstLabels(prometheus.Labels{"cache": "users"}))
+	c.Set("foo", "bar")
+	c.Get("foo")
+	c.Get("baz")
+	if _, err := c.GetOrLoad("qux", Loader(collector, func() (string, error) { return "quux", nil })); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP app_cache_entries Number of items in the cache, including expired ones not cleaned up yet.
+# TYPE app_cache_entries gauge
+app_cache_entries{cache="users"} 2
+# HELP app_cache_hit_ratio Share of reads that found an item.
+# TYPE app_cache_hit_ratio gauge
+app_cache_hit_ratio{cache="users"} 0.3333333333333333
+# HELP app_cache_hits_total Number of reads that found an item.
+# TYPE app_cache_hits_total counter
+app_cache_hits_total{cache="users"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"app_cache_entries", "app_cache_hit_ratio", "app_cache_hits_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(collector, "app_cache_load_duration_seconds"); n != 1 {
+		t.Errorf("expected 1 load histogram, got %d", n)
+	}
+}
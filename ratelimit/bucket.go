@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// TokenBucket allows bursts of up to capacity events per key, refilled at a steady rate.
+// It is safe for concurrent use.
+type TokenBucket struct {
+	buckets  *cache.GenericCache[bucket]
+	now      func() time.Time
+	capacity float64
+	rate     float64
+	// idle is how long an empty bucket takes to be full again.
+	idle time.Duration
+}
+
+// bucket is the state of the bucket of a key.
+type bucket struct {
+	tokens float64
+	// updated is when tokens was computed, in unix nanoseconds.
+	updated int64
+}
+
+// NewTokenBucket returns a TokenBucket holding up to capacity tokens per key, refilled with
+// rate tokens per second. Buckets start full, and the bucket of a key is deleted once it is full
+// again, so idle keys take no memory. Expired buckets are deleted every cleanupInterval.
+func NewTokenBucket(capacity int, rate float64, cleanupInterval time.Duration, opts ...Option) *TokenBucket {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	b := &TokenBucket{now: time.Now, capacity: float64(capacity), rate: rate}
+	var cacheOpts []cache.Option
+	if o.clock != nil {
+		b.now = o.clock.Now
+		cacheOpts = append(cacheOpts, cache.WithClock(o.clock))
+	}
+	// an empty bucket is full again after capacity/rate, then it is as good as a missing one.
+	b.idle = cache.NoExpiration
+	if rate > 0 {
+		b.idle = time.Duration(float64(capacity) / rate * float64(time.Second))
+	}
+	b.buckets = cache.New[bucket](b.idle, cleanupInterval, cacheOpts...)
+	return b
+}
+
+// Take removes n tokens from the bucket of the key and reports whether there were enough.
+// Nothing is removed when there were not.
+func (b *TokenBucket) Take(key string, n int) bool {
+	now := b.now().UnixNano()
+	var taken bool
+	b.buckets.Update(key, func(current bucket, exists bool) (bucket, bool) {
+		tokens := b.capacity
+		if exists {
+			tokens = current.tokens + float64(now-current.updated)/float64(time.Second)*b.rate
+			if tokens > b.capacity {
+				tokens = b.capacity
+			}
+		}
+		if tokens < float64(n) {
+			return current, false
+		}
+		taken = true
+		return bucket{tokens: tokens - float64(n), updated: now}, true
+	})
+	if taken {
+		// Update keeps the expiration of existing buckets.
+		b.buckets.Touch(key, b.idle)
+	}
+	return taken
+}
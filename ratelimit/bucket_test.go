@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	clock := cachetest.NewClock(time.Unix(1e9, 0))
+	b := NewTokenBucket(10, 2, 0, WithClock(clock))
+	if !b.Take("foo", 8) {
+		t.Error("expected a new bucket to be full")
+	}
+	if b.Take("foo", 3) {
+		t.Error("expected 3 tokens to exceed the 2 left")
+	}
+	if !b.Take("foo", 2) {
+		t.Error("expected a failed take to leave the tokens")
+	}
+	if b.Take("foo", 1) {
+		t.Error("expected the bucket to be empty")
+	}
+	if !b.Take("bar", 10) {
+		t.Error("expected keys to have separate buckets")
+	}
+	if b.Take("baz", 11) {
+		t.Error("expected taking more than the capacity to fail")
+	}
+
+	clock.Advance(time.Second)
+	if !b.Take("foo", 2) || b.Take("foo", 1) {
+		t.Error("expected 2 tokens to be refilled after a second")
+	}
+	// the bucket expires once it is full again, and comes back full.
+	clock.Advance(time.Hour)
+	if _, ok := b.buckets.Get("foo"); ok {
+		t.Error("expected the idle bucket to expire")
+	}
+	if !b.Take("foo", 10) {
+		t.Error("expected an expired bucket to be full")
+	}
+}
+
+func TestTokenBucketConcurrent(t *testing.T) {
+	b := NewTokenBucket(10, 0, 0)
+	var taken int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.Take("foo", 1) {
+				atomic.AddInt32(&taken, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&taken); n != 10 {
+		t.Errorf("expected 10 tokens to be taken, got %d", n)
+	}
+}
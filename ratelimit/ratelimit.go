@@ -0,0 +1,87 @@
+// Package ratelimit limits the rate of events per key with counters stored in a cache.NumericCache.
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// Option configures a Limiter.
+type Option func(*options)
+
+type options struct {
+	clock cache.Clock
+}
+
+// WithClock sets the clock of the limiter and of its counters, it is meant to be replaced in tests.
+func WithClock(clock cache.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// Limiter allows at most a number of events per key within a window of time.
+// It is safe for concurrent use, and concurrent events never exceed the limit.
+type Limiter struct {
+	counters *cache.NumericCache[int64]
+	now      func() time.Time
+	sliding  bool
+}
+
+// NewFixedWindow returns a Limiter counting the events of consecutive windows separately,
+// which lets up to twice the limit through around the start of a window.
+// Expired counters are deleted every cleanupInterval.
+func NewFixedWindow(cleanupInterval time.Duration, opts ...Option) *Limiter {
+	return newLimiter(false, cleanupInterval, opts)
+}
+
+// NewSlidingWindow returns a Limiter counting the events of the last window, estimated from
+// the counts of the current and the previous fixed window, assuming the events of the previous
+// one were evenly spread. Expired counters are deleted every cleanupInterval.
+func NewSlidingWindow(cleanupInterval time.Duration, opts ...Option) *Limiter {
+	return newLimiter(true, cleanupInterval, opts)
+}
+
+func newLimiter(sliding bool, cleanupInterval time.Duration, opts []Option) *Limiter {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	l := &Limiter{now: time.Now, sliding: sliding}
+	// counters expire two windows after they were created, however often they are incremented.
+	cacheOpts := []cache.Option{cache.WithKeepTTL()}
+	if o.clock != nil {
+		l.now = o.clock.Now
+		cacheOpts = append(cacheOpts, cache.WithClock(o.clock))
+	}
+	l.counters = cache.NewNumericCache[int64](cache.NoExpiration, cleanupInterval, cacheOpts...)
+	return l
+}
+
+// Allow records an event for the key and reports whether it is within limit events per window.
+// Events which are not allowed are not counted. Allow panics if window is not positive.
+func (l *Limiter) Allow(key string, limit int, window time.Duration) bool {
+	if window <= 0 {
+		panic("ratelimit: non-positive window for Allow")
+	}
+	now := l.now().UnixNano()
+	index := now / int64(window)
+	prefix := key + "\x00" + strconv.FormatInt(int64(window), 36) + "\x00"
+	current := prefix + strconv.FormatInt(index, 36)
+	var previous float64
+	if l.sliding {
+		if p, ok := l.counters.Get(prefix + strconv.FormatInt(index-1, 36)); ok {
+			elapsed := float64(now-index*int64(window)) / float64(window)
+			previous = float64(p) * (1 - elapsed)
+		}
+	}
+	// an event is allowed while the count of the window is less than what the previous one
+	// leaves of the limit, and only counted if it is, so that the events which are not never
+	// count against the concurrent ones. The counter of a window is created with its expiration,
+	// and still read during the next window.
+	_, allowed := l.counters.AddIfLessWithExpireIn(current, 1, int64(math.Floor(float64(limit)-previous)), 2*window)
+	return allowed
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestFixedWindow(t *testing.T) {
+	clock := cachetest.NewClock(time.Unix(1e9, 0))
+	l := NewFixedWindow(0, WithClock(clock))
+	for i := 0; i < 5; i++ {
+		if ok := l.Allow("foo", 3, time.Minute); ok != (i < 3) {
+			t.Errorf("expected event %d to be allowed: %v, got %v", i, i < 3, ok)
+		}
+	}
+	if !l.Allow("bar", 3, time.Minute) {
+		t.Error("expected keys to be limited separately")
+	}
+	clock.Advance(time.Minute)
+	if !l.Allow("foo", 3, time.Minute) {
+		t.Error("expected a new window to start")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// windows start at multiples of their length.
+	clock := cachetest.NewClock(time.Unix(1e9, 0).Truncate(time.Minute))
+	l := NewSlidingWindow(0, WithClock(clock))
+	for i := 0; i < 4; i++ {
+		l.Allow("foo", 4, time.Minute)
+	}
+	// half of the previous window, 2 events, still counts.
+	clock.Advance(time.Minute + time.Second*30)
+	for i := 0; i < 3; i++ {
+		if ok := l.Allow("foo", 4, time.Minute); ok != (i < 2) {
+			t.Errorf("expected event %d to be allowed: %v, got %v", i, i < 2, ok)
+		}
+	}
+	clock.Advance(time.Minute * 2)
+	if !l.Allow("foo", 4, time.Minute) {
+		t.Error("expected old events to be forgotten")
+	}
+}
+
+func TestAllowConcurrent(t *testing.T) {
+	l := NewSlidingWindow(0)
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.Allow("foo", 10, time.Hour) {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&allowed); n != 10 {
+		t.Errorf("expected exactly 10 events to be allowed, got %d", n)
+	}
+}
+
+func TestAllowWindow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a window of 0 to panic")
+		}
+	}()
+	NewFixedWindow(0).Allow("foo", 1, 0)
+}
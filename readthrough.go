@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ReadThroughOption configures a ReadThrough.
+type ReadThroughOption[T any] func(*ReadThrough[T])
+
+// WithTTLFunc sets the function returning the expiration of a value loaded from the store,
+// for example from a field of the value. It defaults to the default expiration of the cache.
+func WithTTLFunc[T any](ttl func(key string, value T) time.Duration) ReadThroughOption[T] {
+	return func(r *ReadThrough[T]) {
+		r.ttl = ttl
+	}
+}
+
+// ReadThrough is a cache loading the missing items from a Store.
+type ReadThrough[T any] struct {
+	cache *GenericCache[T]
+	store Store[T]
+	ttl   func(key string, value T) time.Duration
+}
+
+// NewReadThrough returns a ReadThrough loading the items missing from cache from store.
+func NewReadThrough[T any](cache *GenericCache[T], store Store[T], opts ...ReadThroughOption[T]) *ReadThrough[T] {
+	r := &ReadThrough[T]{cache: cache, store: store}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Get returns the value of the item associated with the key, loading it from the store if it
+// is not cached. It returns false if the store returns an error matching ErrNotFound.
+// Concurrent calls for the same key share a single load, like with GetOrLoadCtx.
+func (r *ReadThrough[T]) Get(ctx context.Context, key string) (result T, exists bool, err error) {
+	result, err = r.cache.getOrLoadCtx(ctx, key, func(ctx context.Context) (T, time.Duration, error) {
+		v, err := r.store.Load(ctx, key)
+		if err != nil || r.ttl == nil {
+			return v, DefaultExpiration, err
+		}
+		return v, r.ttl(key, v), nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		return result, false, nil
+	}
+	return result, err == nil, err
+}
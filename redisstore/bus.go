@@ -0,0 +1,81 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Bus is a cache.Bus using Redis pub/sub.
+type Bus struct {
+	client  redis.UniversalClient
+	channel string
+
+	mu      sync.RWMutex
+	onError func(err error)
+}
+
+var _ cache.Bus = (*Bus)(nil)
+
+// NewBus returns a Bus publishing invalidations on the Redis channel.
+func NewBus(client redis.UniversalClient, channel string) *Bus {
+	return &Bus{client: client, channel: channel}
+}
+
+// OnError sets the function called with the errors of publishing and receiving invalidations,
+// which are dropped otherwise. Set to nil to disable.
+func (b *Bus) OnError(f func(err error)) {
+	b.mu.Lock()
+	b.onError = f
+	b.mu.Unlock()
+}
+
+func (b *Bus) error(err error) {
+	b.mu.RLock()
+	onError := b.onError
+	b.mu.RUnlock()
+	if onError != nil {
+		onError(err)
+	}
+}
+
+// Publish sends inv on the channel.
+func (b *Bus) Publish(inv cache.Invalidation) {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		b.error(err)
+		return
+	}
+	if err := b.client.Publish(context.Background(), b.channel, data).Err(); err != nil {
+		b.error(err)
+	}
+}
+
+// Subscribe calls handler with the invalidations received on the channel until unsubscribe is called.
+// It returns once the subscription is confirmed by Redis.
+func (b *Bus) Subscribe(handler func(inv cache.Invalidation)) (unsubscribe func()) {
+	ctx := context.Background()
+	ps := b.client.Subscribe(ctx, b.channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		b.error(err)
+	}
+	messages := ps.Channel()
+	go func() {
+		for msg := range messages {
+			var inv cache.Invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				b.error(err)
+				continue
+			}
+			handler(inv)
+		}
+	}()
+	return func() {
+		if err := ps.Close(); err != nil {
+			b.error(err)
+		}
+	}
+}
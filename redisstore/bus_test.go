@@ -0,0 +1,33 @@
+package redisstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/eatmoreapple/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestBus(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	a := cache.New[string](time.Minute, 0, cache.WithInvalidationBus(NewBus(client, "invalidations")))
+	b := cache.New[string](time.Minute, 0, cache.WithInvalidationBus(NewBus(client, "invalidations")))
+
+	// loaded values are not published, unlike a.Set which has to invalidate it.
+	b.GetOrLoad("foo", func() (string, error) { return "bar", nil })
+	a.Set("foo", "baz")
+	for i := 0; i < 100; i++ {
+		if _, ok := b.Get("foo"); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if _, ok := b.Get("foo"); ok {
+		t.Error("expected foo to be invalidated in b after a set it")
+	}
+	if v, _ := a.Get("foo"); v != "baz" {
+		t.Errorf("expected a to keep its own write, got %v", v)
+	}
+}
@@ -0,0 +1,95 @@
+// Package redisstore implements cache.RemoteStore with Redis.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Option configures a Store.
+type Option func(*options)
+
+type options struct {
+	prefix string
+	// codec is a cache.Codec[T] matching the value type of the store.
+	codec any
+}
+
+// WithPrefix prepends prefix to the keys stored in Redis, to share a database between caches.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithCodec sets the codec of the stored values, cache.JSONCodec by default.
+// Its value type must match the one of the store, or New panics.
+func WithCodec[T any](codec cache.Codec[T]) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// Store is a cache.RemoteStore storing values in Redis.
+type Store[T any] struct {
+	client redis.UniversalClient
+	codec  cache.Codec[T]
+	prefix string
+}
+
+var _ cache.RemoteStore[any] = (*Store[any])(nil)
+
+// New returns a Store using client.
+func New[T any](client redis.UniversalClient, opts ...Option) *Store[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	s := &Store[T]{client: client, codec: cache.JSONCodec[T]{}, prefix: o.prefix}
+	if o.codec != nil {
+		codec, ok := o.codec.(cache.Codec[T])
+		if !ok {
+			panic(fmt.Sprintf("redisstore: WithCodec codec %T does not match store of %T values", o.codec, *new(T)))
+		}
+		s.codec = codec
+	}
+	return s
+}
+
+// Get returns the value associated with the key, and whether there is one.
+func (s *Store[T]) Get(ctx context.Context, key string) (value T, exists bool, err error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return value, false, nil
+	}
+	if err != nil {
+		return value, false, err
+	}
+	value, err = s.codec.Decode(data)
+	if err != nil {
+		return value, false, fmt.Errorf("redisstore: decode %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set stores the value for ttl, or without expiration if ttl is less than one.
+func (s *Store[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := s.codec.Encode(value)
+	if err != nil {
+		return fmt.Errorf("redisstore: encode %s: %w", key, err)
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.Set(ctx, s.prefix+key, data, ttl).Err()
+}
+
+// Delete removes the value associated with the key, if any.
+func (s *Store[T]) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
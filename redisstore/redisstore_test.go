@@ -0,0 +1,60 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/eatmoreapple/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	s := New[user](client, WithPrefix("users:"))
+
+	if _, ok, err := s.Get(ctx, "foo"); err != nil || ok {
+		t.Errorf("expected foo to be missing, got %v, %v", ok, err)
+	}
+	if err := s.Set(ctx, "foo", user{"foo", 42}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if u, ok, err := s.Get(ctx, "foo"); err != nil || !ok || u != (user{"foo", 42}) {
+		t.Errorf("expected foo to be stored, got %v, %v, %v", u, ok, err)
+	}
+	if ttl := server.TTL("users:foo"); ttl != time.Minute {
+		t.Errorf("expected users:foo to expire in a minute, got %v", ttl)
+	}
+
+	server.FastForward(time.Minute)
+	if _, ok, _ := s.Get(ctx, "foo"); ok {
+		t.Error("expected foo to expire")
+	}
+
+	if err := s.Set(ctx, "bar", user{"bar", 7}, cache.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if server.Exists("users:bar") {
+		t.Error("expected bar to be deleted")
+	}
+}
+
+func TestWithCodecMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a mismatched codec to panic")
+		}
+	}()
+	New[user](redis.NewClient(&redis.Options{}), WithCodec[string](cache.JSONCodec[string]{}))
+}
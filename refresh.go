@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoaderFunc loads the value associated with the key.
+type LoaderFunc[K comparable, V any] func(key K) (V, error)
+
+// WithRefreshAhead reloads items with loader in the background when they are read
+// after factor of their time to live has passed, so that frequently read items never expire.
+// The reloaded value is stored with the time to live of the item it replaces,
+// a failed reload leaves the item as it is. Items that never expire are not reloaded.
+// factor must be between 0 and 1, and the key and value types of loader must match
+// the ones of the cache, or the constructor panics.
+func WithRefreshAhead[K comparable, V any](factor float64, loader LoaderFunc[K, V]) Option {
+	return func(o *options) {
+		o.refreshFactor = factor
+		o.refreshLoader = loader
+	}
+}
+
+// refresher reloads items read after a share of their time to live.
+type refresher[K comparable, V any] struct {
+	factor float64
+	loader LoaderFunc[K, V]
+}
+
+func newRefresher[K comparable, V any](factor float64, loader any) *refresher[K, V] {
+	if factor <= 0 || factor >= 1 {
+		panic(fmt.Sprintf("cache: WithRefreshAhead factor %v is not between 0 and 1", factor))
+	}
+	f, ok := loader.(LoaderFunc[K, V])
+	if !ok {
+		panic(fmt.Sprintf("cache: WithRefreshAhead loader %T does not match cache of %T keys and %T values", loader, *new(K), *new(V)))
+	}
+	return &refresher[K, V]{factor: factor, loader: f}
+}
+
+// due reports whether e, read at now, has to be reloaded. r may be nil.
+func (r *refresher[K, V]) due(e *entry[V], now int64) bool {
+	if r == nil || e.expiration == 0 {
+		return false
+	}
+	return now-e.stored >= int64(r.factor*float64(e.expiration-e.stored))
+}
+
+// start reloads the item associated with the key in the background, unless it is already being loaded.
+func (r *refresher[K, V]) start(c *cache[K, V], key K, ttl time.Duration) {
+	c.loads.doAsync(key, func() (V, error) {
+		v, err := r.loader(key)
+		if err != nil {
+			if c.log != nil {
+				c.log.loadFailed(key, err)
+			}
+			return v, err
+		}
+		c.store(key, v, ttl, 0)
+		return v, nil
+	})
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	loaded := make(chan string, 1)
+	c := New[string](time.Minute, 0, WithClock(clock), WithRefreshAhead(0.8, func(key string) (string, error) {
+		loaded <- key
+		return "baz", nil
+	}))
+	c.Set("foo", "bar")
+
+	clock.Advance(time.Second * 30)
+	if v, _ := c.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to be bar, got %v", v)
+	}
+	select {
+	case key := <-loaded:
+		t.Errorf("expected no refresh before 80%% of the ttl, got %v", key)
+	default:
+	}
+
+	clock.Advance(time.Second * 20)
+	if v, _ := c.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to still be bar while refreshing, got %v", v)
+	}
+	if key := <-loaded; key != "foo" {
+		t.Errorf("expected foo to be refreshed, got %v", key)
+	}
+	// the refresh stores the value right after the loader returns.
+	for i := 0; i < 100; i++ {
+		if ttl, _ := c.TTL("foo"); ttl == time.Minute {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if v, _ := c.Get("foo"); v != "baz" {
+		t.Errorf("expected foo to be refreshed to baz, got %v", v)
+	}
+	if ttl, _ := c.TTL("foo"); ttl != time.Minute {
+		t.Errorf("expected the refreshed foo to keep its ttl, got %v", ttl)
+	}
+}
+
+func TestRefreshAheadInvalidFactor(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an invalid factor to panic")
+		}
+	}()
+	New[string](time.Minute, 0, WithRefreshAhead(1.5, func(key string) (string, error) { return "", nil }))
+}
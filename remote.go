@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// RemoteStore is a cache shared by several processes, such as Redis or memcached.
+// Implementations must be safe for concurrent use.
+type RemoteStore[T any] interface {
+	// Get returns the value associated with the key, and whether there is one.
+	Get(ctx context.Context, key string) (value T, exists bool, err error)
+	// Set stores the value for ttl, or without expiration if ttl is less than one.
+	Set(ctx context.Context, key string, value T, ttl time.Duration) error
+	// Delete removes the value associated with the key, if any.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,4 @@
+// Package cachepb holds the gRPC service of the server package, generated from cache.proto.
+package cachepb
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cache.proto
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/eatmoreapple/cache/server/cachepb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Client is a typed client of a Server, decoding its values with a codec.
+// It implements cache.RemoteStore, so that it can be the L2 of a tiered cache.
+type Client[T any] struct {
+	client cachepb.CacheClient
+	codec  cache.Codec[T]
+}
+
+var _ cache.RemoteStore[any] = (*Client[any])(nil)
+
+// NewClient returns a Client of the server at the other end of conn.
+// codec must match the codec of the server.
+func NewClient[T any](conn grpc.ClientConnInterface, codec cache.Codec[T]) *Client[T] {
+	return &Client[T]{client: cachepb.NewCacheClient(conn), codec: codec}
+}
+
+// Get returns the value associated with the key, and whether there is one.
+func (c *Client[T]) Get(ctx context.Context, key string) (value T, exists bool, err error) {
+	resp, err := c.client.Get(ctx, &cachepb.GetRequest{Key: key})
+	if err != nil || !resp.GetFound() {
+		return value, false, err
+	}
+	value, err = c.codec.Decode(resp.GetValue())
+	if err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores the value for ttl, or without expiration if ttl is less than one.
+func (c *Client[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	if ttl < 1 {
+		ttl = cache.NoExpiration
+	}
+	_, err = c.client.Set(ctx, &cachepb.SetRequest{Key: key, Value: data, Ttl: durationpb.New(ttl)})
+	return err
+}
+
+// SetDefault stores the value with the default expiration of the server cache.
+func (c *Client[T]) SetDefault(ctx context.Context, key string, value T) error {
+	data, err := c.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Set(ctx, &cachepb.SetRequest{Key: key, Value: data})
+	return err
+}
+
+// Delete removes the value associated with the key, if any.
+func (c *Client[T]) Delete(ctx context.Context, key string) error {
+	_, err := c.client.Delete(ctx, &cachepb.DeleteRequest{Key: key})
+	return err
+}
+
+// Increment adds delta to the integer associated with the key, a missing one counting as zero,
+// and returns the result.
+func (c *Client[T]) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	resp, err := c.client.Increment(ctx, &cachepb.IncrementRequest{Key: key, Delta: delta})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetValue(), nil
+}
+
+// Event is a change to a key streamed by Watch.
+type Event[T any] struct {
+	Key string
+	// Value is the new value, or the removed value if the key was deleted.
+	Value T
+	// Deleted is set when the key was removed, Expired as well if it was because it expired.
+	Deleted bool
+	Expired bool
+}
+
+// Watcher receives the events of a Watch call.
+type Watcher[T any] struct {
+	stream cachepb.Cache_WatchClient
+	codec  cache.Codec[T]
+}
+
+// Watch streams the changes to the keys starting with the prefix, until ctx is done.
+// It returns once the server is watching, so that no later change is missed.
+func (c *Client[T]) Watch(ctx context.Context, prefix string) (*Watcher[T], error) {
+	stream, err := c.client.Watch(ctx, &cachepb.WatchRequest{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stream.Header(); err != nil {
+		return nil, err
+	}
+	return &Watcher[T]{stream: stream, codec: c.codec}, nil
+}
+
+// Recv waits for the next event. It returns io.EOF when the server ends the stream.
+func (w *Watcher[T]) Recv() (Event[T], error) {
+	resp, err := w.stream.Recv()
+	if err != nil {
+		return Event[T]{}, err
+	}
+	event := Event[T]{
+		Key:     resp.GetKey(),
+		Deleted: resp.GetType() != cachepb.WatchEvent_TYPE_SET,
+		Expired: resp.GetType() == cachepb.WatchEvent_TYPE_EXPIRE,
+	}
+	event.Value, err = w.codec.Decode(resp.GetValue())
+	return event, err
+}
@@ -0,0 +1,149 @@
+// Package server exposes a cache over gRPC, so that several processes can share a warm cache,
+// and provides a typed client for it.
+package server
+
+import (
+	"context"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/eatmoreapple/cache/server/cachepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server is a cachepb.CacheServer serving a cache, to be registered on a grpc.Server
+// with cachepb.RegisterCacheServer. Values are encoded with the codec of the server.
+type Server[T any] struct {
+	cachepb.UnimplementedCacheServer
+
+	cache *cache.GenericCache[T]
+	codec cache.Codec[T]
+}
+
+var _ cachepb.CacheServer = (*Server[any])(nil)
+
+// New returns a Server serving c, encoding its values with codec.
+func New[T any](c *cache.GenericCache[T], codec cache.Codec[T]) *Server[T] {
+	return &Server[T]{cache: c, codec: codec}
+}
+
+// Get returns the value associated with the key.
+func (s *Server[T]) Get(ctx context.Context, req *cachepb.GetRequest) (*cachepb.GetResponse, error) {
+	v, expiration, ok := s.cache.GetWithExpiration(req.GetKey())
+	if !ok {
+		return &cachepb.GetResponse{}, nil
+	}
+	data, err := s.codec.Encode(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode %q: %v", req.GetKey(), err)
+	}
+	resp := &cachepb.GetResponse{Found: true, Value: data}
+	if !expiration.IsZero() {
+		resp.Expiration = timestamppb.New(expiration)
+	}
+	return resp, nil
+}
+
+// Set stores the value associated with the key.
+func (s *Server[T]) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	v, err := s.codec.Decode(req.GetValue())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decode %q: %v", req.GetKey(), err)
+	}
+	ttl := cache.DefaultExpiration
+	if req.GetTtl() != nil {
+		ttl = req.GetTtl().AsDuration()
+		if ttl < 0 {
+			ttl = cache.NoExpiration
+		}
+	}
+	s.cache.SetWithExpireIn(req.GetKey(), v, ttl)
+	return &cachepb.SetResponse{}, nil
+}
+
+// Delete removes the item associated with the key, if any.
+func (s *Server[T]) Delete(ctx context.Context, req *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	s.cache.Delete(req.GetKey())
+	return &cachepb.DeleteResponse{}, nil
+}
+
+// Increment adds delta to the integer associated with the key, a missing one counting as zero.
+// A new item gets the default expiration, an existing one keeps its expiration.
+// It fails with codes.FailedPrecondition if the values of the cache are not integers.
+func (s *Server[T]) Increment(ctx context.Context, req *cachepb.IncrementRequest) (*cachepb.IncrementResponse, error) {
+	var (
+		result int64
+		ok     bool
+	)
+	v, _ := s.cache.Update(req.GetKey(), func(current T, _ bool) (T, bool) {
+		current, result, ok = add(current, req.GetDelta())
+		return current, ok
+	})
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot increment values of type %T", v)
+	}
+	return &cachepb.IncrementResponse{Value: result}, nil
+}
+
+// add adds delta to v if it is an integer, and returns the sum as a T and as an int64.
+func add[T any](v T, delta int64) (T, int64, bool) {
+	var sum any
+	var result int64
+	switch n := any(v).(type) {
+	case int:
+		sum, result = n+int(delta), int64(n+int(delta))
+	case int8:
+		sum, result = n+int8(delta), int64(n+int8(delta))
+	case int16:
+		sum, result = n+int16(delta), int64(n+int16(delta))
+	case int32:
+		sum, result = n+int32(delta), int64(n+int32(delta))
+	case int64:
+		sum, result = n+delta, n+delta
+	case uint:
+		sum, result = n+uint(delta), int64(n+uint(delta))
+	case uint8:
+		sum, result = n+uint8(delta), int64(n+uint8(delta))
+	case uint16:
+		sum, result = n+uint16(delta), int64(n+uint16(delta))
+	case uint32:
+		sum, result = n+uint32(delta), int64(n+uint32(delta))
+	case uint64:
+		sum, result = n+uint64(delta), int64(n+uint64(delta))
+	default:
+		return v, 0, false
+	}
+	return sum.(T), result, true
+}
+
+// Watch streams the changes to the keys starting with the prefix, see cache.GenericCache.Watch.
+// The headers are sent once the watch is registered. The stream is ended with codes.Unavailable
+// if the client falls behind or the cache is closed, after which it should read the keys again.
+func (s *Server[T]) Watch(req *cachepb.WatchRequest, stream cachepb.Cache_WatchServer) error {
+	ctx := stream.Context()
+	events := s.cache.Watch(ctx, req.GetPrefix())
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for event := range events {
+		data, err := s.codec.Encode(event.Value)
+		if err != nil {
+			return status.Errorf(codes.Internal, "encode %q: %v", event.Key, err)
+		}
+		if err := stream.Send(&cachepb.WatchEvent{Type: eventTypes[event.Type], Key: event.Key, Value: data}); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return status.Error(codes.Unavailable, "watch ended: the watcher fell behind or the cache was closed")
+}
+
+var eventTypes = map[cache.EventType]cachepb.WatchEvent_Type{
+	cache.EventSet:    cachepb.WatchEvent_TYPE_SET,
+	cache.EventDelete: cachepb.WatchEvent_TYPE_DELETE,
+	cache.EventExpire: cachepb.WatchEvent_TYPE_EXPIRE,
+}
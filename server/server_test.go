@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+	"github.com/eatmoreapple/cache/server/cachepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves srv in memory and returns a connection to it.
+func dial(t *testing.T, srv cachepb.CacheServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	cachepb.RegisterCacheServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	c := cache.New[string](time.Hour, 0)
+	client := NewClient[string](dial(t, New(c, cache.JSONCodec[string]{})), cache.JSONCodec[string]{})
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "foo", "bar", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := client.Get(ctx, "foo"); err != nil || !ok || v != "bar" {
+		t.Errorf("expected bar, got %v %v %v", v, ok, err)
+	}
+	if ttl, _ := c.TTL("foo"); ttl > time.Minute {
+		t.Errorf("expected the ttl to be set, got %v", ttl)
+	}
+	if err := client.Set(ctx, "baz", "qux", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, exp, _ := c.GetWithExpiration("baz"); !exp.IsZero() {
+		t.Errorf("expected a ttl less than one not to expire, got %v", exp)
+	}
+	if err := client.Delete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := client.Get(ctx, "foo"); err != nil || ok {
+		t.Errorf("expected foo to be deleted, got %v %v", ok, err)
+	}
+	_, err := client.Increment(ctx, "foo", 1)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected incrementing strings to fail, got %v", err)
+	}
+}
+
+func TestServerIncrement(t *testing.T) {
+	c := cache.New[int64](cache.NoExpiration, 0)
+	client := NewClient[int64](dial(t, New(c, cache.JSONCodec[int64]{})), cache.JSONCodec[int64]{})
+	ctx := context.Background()
+	client.Increment(ctx, "hits", 2)
+	if n, err := client.Increment(ctx, "hits", 3); err != nil || n != 5 {
+		t.Errorf("expected 5, got %v %v", n, err)
+	}
+	if v, _ := c.Get("hits"); v != 5 {
+		t.Errorf("expected the cache to hold 5, got %v", v)
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	c := cache.New[string](cache.NoExpiration, 0)
+	srv := New(c, cache.JSONCodec[string]{})
+	client := NewClient[string](dial(t, srv), cache.JSONCodec[string]{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := client.Watch(ctx, "user:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Set(ctx, "post:1", "ignored", 0)
+	client.Set(ctx, "user:1", "foo", 0)
+	client.Delete(ctx, "user:1")
+	// changes made directly to the cache are streamed too.
+	c.SetWithExpireIn("user:2", "bar", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	c.DeleteExpired()
+	if e, err := w.Recv(); err != nil || e.Key != "user:1" || e.Value != "foo" || e.Deleted {
+		t.Errorf("expected user:1 to be set to foo, got %+v %v", e, err)
+	}
+	if e, err := w.Recv(); err != nil || e.Key != "user:1" || !e.Deleted || e.Expired {
+		t.Errorf("expected user:1 to be deleted, got %+v %v", e, err)
+	}
+	if e, err := w.Recv(); err != nil || e.Key != "user:2" || e.Deleted {
+		t.Errorf("expected user:2 to be set, got %+v %v", e, err)
+	}
+	if e, err := w.Recv(); err != nil || e.Key != "user:2" || e.Value != "bar" || !e.Expired {
+		t.Errorf("expected user:2 to expire, got %+v %v", e, err)
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import "time"
+
+// SetCache is a cache of sets of members keyed by strings.
+// Its operations are atomic under the lock of the shard holding the key. Stored sets are never
+// modified, every change stores a copy, so the sets returned by Get can be read without locking
+// but must not be modified.
+type SetCache[T comparable] struct {
+	*GenericCache[map[T]struct{}]
+}
+
+// NewSetCache returns a new SetCache[T] with the given default expiration duration and cleanup interval.
+// A new set gets the default expiration, use Touch to change the expiration of a key.
+func NewSetCache[T comparable](defaultExpiration, cleanupInterval time.Duration, opts ...Option) *SetCache[T] {
+	return &SetCache[T]{GenericCache: New[map[T]struct{}](defaultExpiration, cleanupInterval, opts...)}
+}
+
+// SAdd adds the members to the set associated with the key, creating it if needed,
+// and returns how many were not in the set yet. An existing set keeps its expiration.
+func (s *SetCache[T]) SAdd(key string, members ...T) int {
+	var added int
+	s.Update(key, func(current map[T]struct{}, _ bool) (map[T]struct{}, bool) {
+		var set map[T]struct{}
+		for _, m := range members {
+			if _, ok := current[m]; ok {
+				continue
+			}
+			if set == nil {
+				set = copySet(current)
+			}
+			set[m] = struct{}{}
+		}
+		added = len(set) - len(current)
+		return set, set != nil
+	})
+	return added
+}
+
+// SRem removes the members from the set associated with the key and returns how many were removed.
+// The key is deleted once its set is empty.
+func (s *SetCache[T]) SRem(key string, members ...T) int {
+	var removed int
+	s.Update(key, func(current map[T]struct{}, _ bool) (map[T]struct{}, bool) {
+		var set map[T]struct{}
+		for _, m := range members {
+			if _, ok := current[m]; !ok {
+				continue
+			}
+			if set == nil {
+				set = copySet(current)
+			}
+			// members may be repeated.
+			if _, ok := set[m]; ok {
+				delete(set, m)
+				removed++
+			}
+		}
+		return set, set != nil
+	})
+	if removed > 0 {
+		s.CompareAndDeleteFunc(key, nil, func(current, _ map[T]struct{}) bool {
+			return len(current) == 0
+		})
+	}
+	return removed
+}
+
+// SMembers returns the members of the set associated with the key, in no particular order.
+func (s *SetCache[T]) SMembers(key string) []T {
+	set, _ := s.Get(key)
+	members := make([]T, 0, len(set))
+	for m := range set {
+		members = append(members, m)
+	}
+	return members
+}
+
+// SIsMember reports whether member is in the set associated with the key.
+func (s *SetCache[T]) SIsMember(key string, member T) bool {
+	set, _ := s.Get(key)
+	_, ok := set[member]
+	return ok
+}
+
+// SCard returns the number of members of the set associated with the key.
+func (s *SetCache[T]) SCard(key string) int {
+	set, _ := s.Get(key)
+	return len(set)
+}
+
+func copySet[T comparable](set map[T]struct{}) map[T]struct{} {
+	c := make(map[T]struct{}, len(set))
+	for m := range set {
+		c[m] = struct{}{}
+	}
+	return c
+}
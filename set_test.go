@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestSetCache(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := NewSetCache[int](time.Minute, 0, WithClock(clock))
+	if n := c.SAdd("foo", 1, 2, 2, 3); n != 3 {
+		t.Errorf("expected 3 members to be added, got %d", n)
+	}
+	if n := c.SAdd("foo", 3, 4); n != 1 {
+		t.Errorf("expected 1 member to be added, got %d", n)
+	}
+	members := c.SMembers("foo")
+	sort.Ints(members)
+	if len(members) != 4 || members[0] != 1 || members[3] != 4 {
+		t.Errorf("expected members [1 2 3 4], got %v", members)
+	}
+	if !c.SIsMember("foo", 2) || c.SIsMember("foo", 5) || c.SIsMember("bar", 1) {
+		t.Error("expected SIsMember to report the members of foo only")
+	}
+
+	held, _ := c.Get("foo")
+	if n := c.SRem("foo", 1, 1, 5); n != 1 {
+		t.Errorf("expected 1 member to be removed, got %d", n)
+	}
+	if _, ok := held[1]; !ok {
+		t.Error("expected a set returned by Get not to be modified")
+	}
+	if n := c.SCard("foo"); n != 3 {
+		t.Errorf("expected 3 members, got %d", n)
+	}
+	c.SRem("foo", 2, 3, 4)
+	if _, ok := c.Get("foo"); ok {
+		t.Error("expected an empty set to be deleted")
+	}
+
+	c.SAdd("foo", 1)
+	clock.Advance(time.Second * 30)
+	c.SAdd("foo", 2)
+	clock.Advance(time.Second * 30)
+	if c.SCard("foo") != 0 {
+		t.Error("expected adding members to keep the expiration of the set")
+	}
+}
+
+func TestSetCacheConcurrent(t *testing.T) {
+	c := NewSetCache[string](NoExpiration, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.SAdd("foo", strconv.Itoa(i))
+			c.SIsMember("foo", strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+	if n := c.SCard("foo"); n != 100 {
+		t.Errorf("expected 100 members, got %d", n)
+	}
+}
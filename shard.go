@@ -0,0 +1,398 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shardConfig is the configuration shared by the shards of a cache.
+type shardConfig[K comparable, V any] struct {
+	clock Clock
+	// epoch is the current epoch of the cache, read atomically, see NewEpoch.
+	epoch *uint64
+	// staleFor is how long expired items are kept before they are deleted.
+	staleFor time.Duration
+	// maxEntries and maxCost are the bounds of every shard, 0 if unbounded.
+	maxEntries int
+	maxCost    int64
+	// costOf is nil when costs are not tracked.
+	costOf func(key K, value V) int64
+	// newPolicy creates the eviction policy of bounded shards.
+	newPolicy func() Policy[K]
+	// newExpiry creates the expiration index of every shard.
+	newExpiry func() expiry[K, V]
+	// entries recycles the entries of the expired and evicted items, see release.
+	entries *sync.Pool
+	// stored is called with every value stored, under the lock of the shard, see assign.
+	stored func(key K, value V)
+	// forget removes a key from the overflow, under the lock of the shard, see unspilled.
+	// It is nil without WithOverflow.
+	forget func(key K)
+}
+
+// bounded reports whether shards have to evict items.
+func (c *shardConfig[K, V]) bounded() bool {
+	return c.maxEntries > 0 || c.maxCost > 0
+}
+
+// shard is an independently locked part of a cache.
+type shard[K comparable, V any] struct {
+	*shardConfig[K, V]
+
+	// mu protects the fields below.
+	mu    sync.RWMutex
+	items map[K]*entry[V]
+	// cost is the total cost of the items.
+	cost int64
+	// policy is nil when the shard is unbounded.
+	policy *priorities[K]
+	// pinned is the number of pinned items.
+	pinned int
+	// tags indexes the keys of tagged items by tag.
+	tags map[string]map[K]struct{}
+	// expiry indexes the items which expire.
+	expiry expiry[K, V]
+	// swept is the epoch whose outdated items were last removed, see sweep.
+	swept uint64
+	// spilled holds the keys of the items moved to the overflow, see spilling.
+	spilled map[K]struct{}
+
+	// janitor is nil when expired items are not deleted in the background, it is protected
+	// by the janitorMu of the cache.
+	janitor *janitor
+}
+
+func newShard[K comparable, V any](config *shardConfig[K, V]) *shard[K, V] {
+	s := &shard[K, V]{shardConfig: config, items: make(map[K]*entry[V]), expiry: config.newExpiry()}
+	if config.bounded() {
+		s.policy = newPriorities[K](s.newPolicy)
+	}
+	return s
+}
+
+// rlock locks the shard for a read, which runlock unlocks. Reading an item is recorded by
+// the eviction policy, so bounded shards are locked exclusively. They are two methods rather
+// than a function returning the unlock one, which would allocate on every read.
+func (s *shard[K, V]) rlock() {
+	if s.policy != nil {
+		s.mu.Lock()
+		return
+	}
+	s.mu.RLock()
+}
+
+// runlock unlocks the shard locked by rlock.
+func (s *shard[K, V]) runlock() {
+	if s.policy != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.RUnlock()
+}
+
+// get returns the live item associated with the key. s.mu must be held.
+func (s *shard[K, V]) get(key K, now int64) (*entry[V], bool) {
+	e, ok := s.items[key]
+	if !ok || s.expired(e, now) {
+		return nil, false
+	}
+	if s.policy != nil {
+		s.policy.Access(key)
+	}
+	return e, true
+}
+
+// set stores the item with priority 0 and appends the items it pushed out to evicted.
+// s.mu must be held.
+func (s *shard[K, V]) set(key K, value V, expiration int64, evicted []eviction[K, V]) []eviction[K, V] {
+	return s.setWithPriority(key, value, expiration, 0, evicted)
+}
+
+// setWithPriority is set storing the item with priority. s.mu must be held.
+func (s *shard[K, V]) setWithPriority(key K, value V, expiration int64, priority int, evicted []eviction[K, V]) []eviction[K, V] {
+	if s.policy != nil {
+		s.policy.prioritize(key, priority)
+	}
+	now := s.clock.Now().UnixNano()
+	epoch := atomic.LoadUint64(s.epoch)
+	if e, ok := s.items[key]; ok {
+		// an expired item is gone no matter it is cleaned up yet or not.
+		if s.expired(e, now) {
+			evicted = append(evicted, eviction[K, V]{key, e.value, EvictionReasonExpired, e.expiration})
+		}
+		s.untag(key, e)
+		s.assign(key, e, value)
+		e.expiration, e.stored, e.delta, e.epoch = expiration, now, 0, epoch
+		s.expiry.schedule(key, e)
+	} else {
+		e := s.entries.Get().(*entry[V])
+		e.expiration, e.created, e.stored, e.epoch, e.index = expiration, now, now, epoch, -1
+		s.assign(key, e, value)
+		s.items[key] = e
+		s.expiry.schedule(key, e)
+		return s.fit(key, true, now, evicted)
+	}
+	return s.fit(key, false, now, evicted)
+}
+
+// replace changes the value of an existing item, keeping everything else,
+// and appends the items it pushed out to evicted. s.mu must be held.
+func (s *shard[K, V]) replace(key K, e *entry[V], value V, evicted []eviction[K, V]) []eviction[K, V] {
+	s.assign(key, e, value)
+	return s.fit(key, false, s.clock.Now().UnixNano(), evicted)
+}
+
+// assign sets the value of e and accounts for its cost. s.mu must be held.
+func (s *shard[K, V]) assign(key K, e *entry[V], value V) {
+	e.value = value
+	s.stored(key, value)
+	s.unspilled(key)
+	if s.costOf == nil {
+		return
+	}
+	s.cost -= e.cost
+	e.cost = s.costOf(key, value)
+	s.cost += e.cost
+}
+
+// fit records that key was stored and evicts the items chosen by the eviction policy
+// until the shard is within its bounds, appending them to evicted. A new key is only
+// recorded once room was made for it, so that it is not chosen to make room for itself
+// unless it does not fit on its own. s.mu must be held.
+func (s *shard[K, V]) fit(key K, isNew bool, now int64, evicted []eviction[K, V]) []eviction[K, V] {
+	if s.policy == nil {
+		return evicted
+	}
+	if !isNew {
+		s.policy.Add(key)
+	}
+	for s.overflows() {
+		victim, ok := s.policy.Victim()
+		if !ok {
+			if s.items[key].pinned {
+				// only pinned items are left.
+				break
+			}
+			// only the new item is left.
+			victim = key
+		}
+		e := s.items[victim]
+		reason := EvictionReasonCapacity
+		if s.expired(e, now) {
+			reason = EvictionReasonExpired
+		}
+		evicted = s.evict(victim, e, reason, evicted)
+		if victim == key {
+			return evicted
+		}
+	}
+	if isNew {
+		s.policy.Add(key)
+	}
+	return evicted
+}
+
+// overflows reports whether the shard exceeds its bounds. s.mu must be held.
+func (s *shard[K, V]) overflows() bool {
+	n := len(s.items)
+	return n > 0 && (s.maxEntries > 0 && n > s.maxEntries || s.maxCost > 0 && s.cost > s.maxCost)
+}
+
+// delete removes the item from the shard. s.mu must be held.
+func (s *shard[K, V]) delete(key K) {
+	if e, ok := s.items[key]; ok {
+		s.untag(key, e)
+		s.expiry.unschedule(key, e)
+		s.cost -= e.cost
+		if e.pinned {
+			s.pinned--
+		}
+	}
+	delete(s.items, key)
+	if s.policy != nil {
+		s.policy.Remove(key)
+	}
+	s.unspilled(key)
+}
+
+// deleteExpired removes the expired items and appends them to evicted.
+// Items are kept for staleFor after they expired.
+func (s *shard[K, V]) deleteExpired(evicted []eviction[K, V]) []eviction[K, V] {
+	now := s.clock.Now().UnixNano() - int64(s.staleFor)
+	s.mu.Lock()
+	s.expiry.expire(now, func(key K, e *entry[V]) {
+		evicted = s.evict(key, e, EvictionReasonExpired, evicted)
+	})
+	outdated := s.sweep()
+	s.mu.Unlock()
+	// like flush, the outdated entries are reported and recycled once the shard is unlocked.
+	for key, e := range outdated {
+		evicted = append(evicted, eviction[K, V]{key, e.value, EvictionReasonExpired, e.expiration})
+		s.release(e)
+	}
+	return evicted
+}
+
+// sweep detaches the items outdated by NewEpoch from the shard, once per epoch, and returns
+// their entries by key. Items unpinned later join the current epoch, so they are never left
+// behind. s.mu must be held.
+func (s *shard[K, V]) sweep() map[K]*entry[V] {
+	epoch := atomic.LoadUint64(s.epoch)
+	if s.swept == epoch {
+		return nil
+	}
+	s.swept = epoch
+	var outdated map[K]*entry[V]
+	for key, e := range s.items {
+		if s.outdated(e) {
+			if outdated == nil {
+				outdated = make(map[K]*entry[V])
+			}
+			outdated[key] = e
+			s.delete(key)
+		}
+	}
+	return outdated
+}
+
+// evict removes the item associated with the key, whose entry is e, appends it to evicted for
+// reason and recycles e. s.mu must be held exclusively.
+func (s *shard[K, V]) evict(key K, e *entry[V], reason EvictionReason, evicted []eviction[K, V]) []eviction[K, V] {
+	s.delete(key)
+	if reason == EvictionReasonCapacity && s.forget != nil {
+		s.spilling(key)
+	}
+	evicted = append(evicted, eviction[K, V]{key, e.value, reason, e.expiration})
+	s.release(e)
+	return evicted
+}
+
+// release recycles the entry of a removed item for the next items stored. The values are copied
+// out of the entries, into the evictions and the results of the reads, so nothing refers to e
+// anymore. s.mu must be held exclusively, unless e was detached from the shard, like by flush.
+func (s *shard[K, V]) release(e *entry[V]) {
+	*e = entry[V]{}
+	s.entries.Put(e)
+}
+
+// flush removes all items, but the pinned ones if pinned is set, and appends them to evicted
+// if report is set.
+func (s *shard[K, V]) flush(evicted []eviction[K, V], report, pinned bool) []eviction[K, V] {
+	s.mu.Lock()
+	items := s.items
+	var kept map[K]int
+	if pinned && s.pinned > 0 {
+		kept = s.pinnedPriorities()
+	}
+	s.items = make(map[K]*entry[V])
+	s.cost = 0
+	s.tags = nil
+	s.pinned = 0
+	s.expiry.reset()
+	if s.policy != nil {
+		s.policy.Reset()
+	}
+	for key, priority := range kept {
+		s.keep(key, items[key], priority)
+		delete(items, key)
+	}
+	s.mu.Unlock()
+	for key, e := range items {
+		if report {
+			evicted = append(evicted, eviction[K, V]{key, e.value, EvictionReasonFlushed, e.expiration})
+		}
+		s.release(e)
+	}
+	return evicted
+}
+
+// pinnedPriorities returns the priorities of the pinned items by key. s.mu must be held.
+func (s *shard[K, V]) pinnedPriorities() map[K]int {
+	pinned := make(map[K]int, s.pinned)
+	for key, e := range s.items {
+		if e.pinned {
+			pinned[key] = s.priority(key)
+		}
+	}
+	return pinned
+}
+
+// keep stores the pinned entry e of a flushed shard again, with priority. s.mu must be held.
+func (s *shard[K, V]) keep(key K, e *entry[V], priority int) {
+	s.items[key] = e
+	s.cost += e.cost
+	tags := e.tags
+	e.tags, e.index, e.pinned = nil, -1, false
+	s.tag(key, e, tags)
+	s.expiry.schedule(key, e)
+	if s.policy != nil {
+		s.policy.prioritize(key, priority)
+	}
+	s.pin(key, e)
+}
+
+// tag attaches tags to the item associated with the key. s.mu must be held.
+func (s *shard[K, V]) tag(key K, e *entry[V], tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]map[K]struct{})
+	}
+	for _, tag := range tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	e.tags = append(e.tags, tags...)
+}
+
+// untag detaches all tags from the item associated with the key. s.mu must be held.
+func (s *shard[K, V]) untag(key K, e *entry[V]) {
+	for _, tag := range e.tags {
+		keys := s.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+	e.tags = nil
+}
+
+// snapshotItem is a live item copied out of a shard.
+type snapshotItem[K comparable, V any] struct {
+	key        K
+	value      V
+	expiration int64
+}
+
+// snapshot copies the items that are live at now.
+func (s *shard[K, V]) snapshot(now int64) []snapshotItem[K, V] {
+	s.mu.RLock()
+	items := make([]snapshotItem[K, V], 0, len(s.items))
+	for key, e := range s.items {
+		if !s.expired(e, now) {
+			items = append(items, snapshotItem[K, V]{key, e.value, e.expiration})
+		}
+	}
+	s.mu.RUnlock()
+	return items
+}
+
+// hashString is the 64-bit FNV-1a hash of key.
+func hashString(key string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	return h
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errLoaderPanicked is returned to the callers waiting on a load that panicked.
+var errLoaderPanicked = errors.New("cache: loader panicked")
+
+// call is an in-flight load.
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// group deduplicates concurrent loads of the same key.
+// The zero value is ready to use.
+type group[K comparable, T any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[T]
+	// async tracks the goroutines started by doAsync.
+	async sync.WaitGroup
+}
+
+// do calls fn and returns its results, making sure that only one call
+// for a given key is in-flight at a time. Duplicate callers wait for the
+// original one to complete and receive the same results.
+func (g *group[K, T]) do(key K, fn func() (T, error)) (T, error) {
+	return g.doCtx(context.Background(), key, fn)
+}
+
+// doCtx is like do, but duplicate callers stop waiting and return
+// the context error once ctx is done.
+func (g *group[K, T]) doCtx(ctx context.Context, key K, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+	c := g.start(key)
+	g.mu.Unlock()
+
+	defer g.finish(key, c)
+	c.val, c.err = fn()
+	return c.val, c.err
+}
+
+// doAsync calls fn in a new goroutine unless a call for key is already in-flight.
+func (g *group[K, T]) doAsync(key K, fn func() (T, error)) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[T])
+	}
+	if _, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return
+	}
+	c := g.start(key)
+	g.async.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer g.async.Done()
+		defer g.finish(key, c)
+		c.val, c.err = fn()
+	}()
+}
+
+// wait waits for the calls started by doAsync.
+func (g *group[K, T]) wait() {
+	g.async.Wait()
+}
+
+// start registers a new in-flight call for key. g.mu must be held.
+func (g *group[K, T]) start(key K) *call[T] {
+	c := &call[T]{done: make(chan struct{}), err: errLoaderPanicked}
+	g.calls[key] = c
+	return c
+}
+
+// finish unregisters the call for key and releases its waiters.
+func (g *group[K, T]) finish(key K, c *call[T]) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+}
@@ -0,0 +1,83 @@
+//go:build go1.21
+
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogLevels are the levels at which WithLogger logs the events of a cache.
+type LogLevels struct {
+	// Eviction is the level of the items removed from the cache, slog.LevelDebug by default.
+	Eviction slog.Level
+	// LoadError is the level of the loader errors, slog.LevelWarn by default.
+	LoadError slog.Level
+	// PersistError is the level of the errors of the snapshots, the log and the overflow,
+	// slog.LevelError by default.
+	PersistError slog.Level
+	// Cleanup is the level of the janitor runs, slog.LevelDebug by default.
+	Cleanup slog.Level
+}
+
+// WithLogger logs the items removed from the cache, the loader errors, including the ones of
+// background refreshes, the errors of the snapshots, the WithLog log and the WithOverflow tier,
+// and the janitor runs. See WithLogLevels for the levels.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		slogHookOf(o).logger = logger
+	}
+}
+
+// WithLogLevels sets the levels at which the WithLogger logger logs.
+func WithLogLevels(levels LogLevels) Option {
+	return func(o *options) {
+		slogHookOf(o).levels = levels
+	}
+}
+
+// slogHook is the logHook set by WithLogger.
+type slogHook struct {
+	logger *slog.Logger
+	levels LogLevels
+}
+
+// slogHookOf returns the slogHook of o, setting a new one if needed.
+func slogHookOf(o *options) *slogHook {
+	h, ok := o.logger.(*slogHook)
+	if !ok {
+		h = &slogHook{levels: LogLevels{
+			Eviction:     slog.LevelDebug,
+			LoadError:    slog.LevelWarn,
+			PersistError: slog.LevelError,
+			Cleanup:      slog.LevelDebug,
+		}}
+		o.logger = h
+	}
+	return h
+}
+
+func (h *slogHook) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	// WithLogLevels may be used without WithLogger.
+	if h.logger == nil {
+		return
+	}
+	h.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+func (h *slogHook) evicted(key any, reason EvictionReason) {
+	h.log(h.levels.Eviction, "cache: item removed", slog.Any("key", key), slog.String("reason", reason.String()))
+}
+
+func (h *slogHook) loadFailed(key any, err error) {
+	h.log(h.levels.LoadError, "cache: load failed", slog.Any("key", key), slog.Any("error", err))
+}
+
+func (h *slogHook) persistFailed(err error) {
+	h.log(h.levels.PersistError, "cache: persistence failed", slog.Any("error", err))
+}
+
+func (h *slogHook) cleaned(expired int, took time.Duration) {
+	h.log(h.levels.Cleanup, "cache: expired items deleted", slog.Int("expired", expired), slog.Duration("took", took))
+}
@@ -0,0 +1,49 @@
+//go:build go1.21
+
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "took" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](time.Minute, 0, WithClock(clock), WithLogger(logger),
+		WithLogLevels(LogLevels{Eviction: slog.LevelInfo, LoadError: slog.LevelError, Cleanup: slog.LevelDebug}))
+	c.Set("foo", "bar")
+	c.Delete("foo")
+	c.GetOrLoad("baz", func() (string, error) { return "", errors.New("boom") })
+	c.Set("qux", "quux")
+	clock.Advance(time.Minute)
+	c.cleanup(c.shards[0])
+	c.persistError(errors.New("disk full"))
+
+	want := []string{
+		`level=INFO msg="cache: item removed" key=foo reason=deleted`,
+		`level=ERROR msg="cache: load failed" key=baz error=boom`,
+		`level=DEBUG msg="cache: expired items deleted" expired=1`,
+		`level=INFO msg="cache: item removed" key=qux reason=expired`,
+		`level=INFO msg="cache: persistence failed" error="disk full"`,
+	}
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected logs\n%s\ngot\n%s", strings.Join(want, "\n"), buf.String())
+	}
+}
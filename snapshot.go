@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithSnapshot restores the cache from the file at path when it is created, and dumps it
+// to the file every interval. If the file does not exist or cannot be loaded, the cache
+// starts empty. The file is written with DumpTo to a temporary file which is then renamed,
+// so that it is never left half written.
+func WithSnapshot(path string, interval time.Duration) Option {
+	return func(o *options) {
+		o.snapshotPath = path
+		o.snapshotInterval = interval
+	}
+}
+
+// errNoSnapshot is returned by SaveSnapshot when the cache was created without WithSnapshot.
+var errNoSnapshot = errors.New("cache: no snapshot file, see WithSnapshot")
+
+// snapshotter periodically dumps a cache to a file.
+type snapshotter struct {
+	path    string
+	janitor *janitor
+}
+
+// SaveSnapshot dumps the cache to the WithSnapshot file right away.
+// The WithLog log is emptied once the snapshot is written.
+func (c *cache[K, V]) SaveSnapshot() error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.saveSnapshot()
+}
+
+// saveSnapshot is SaveSnapshot, also called by Close.
+func (c *cache[K, V]) saveSnapshot() error {
+	if c.snapshots == nil {
+		return errNoSnapshot
+	}
+	if c.wal == nil {
+		return c.snapshots.write(c.dumpTo)
+	}
+	return c.wal.compact(func() error {
+		return c.snapshots.write(c.dumpTo)
+	})
+}
+
+// OnSnapshotError sets the function called with the errors of the periodic snapshots
+// and of the WithLog log and the WithOverflow tier, which are dropped otherwise. Set to nil to disable.
+// f must not write or delete keys of the cache, see WithOverflow.
+func (c *cache[K, V]) OnSnapshotError(f func(err error)) {
+	c.mu.Lock()
+	c.onPersistError = f
+	c.mu.Unlock()
+}
+
+// persistError passes err to the OnSnapshotError function.
+func (c *cache[K, V]) persistError(err error) {
+	if c.log != nil {
+		c.log.persistFailed(err)
+	}
+	c.mu.RLock()
+	onError := c.onPersistError
+	c.mu.RUnlock()
+	if onError != nil {
+		onError(err)
+	}
+}
+
+// restoreSnapshot loads the snapshot file at path into c.
+func (c *cache[K, V]) restoreSnapshot(path string) {
+	if f, err := os.Open(path); err == nil {
+		// an unreadable snapshot is as good as a missing one, the cache warms up again.
+		_ = c.LoadFrom(f)
+		f.Close()
+	}
+}
+
+// startSnapshots starts dumping c to the file at path every interval.
+func (c *cache[K, V]) startSnapshots(path string, interval time.Duration) {
+	s := &snapshotter{path: path}
+	c.snapshots = s
+	if interval > 0 {
+		s.janitor = newJanitor(c.clock, interval)
+		go s.janitor.run(func() {
+			if err := c.saveSnapshot(); err != nil {
+				c.persistError(err)
+			}
+		})
+	}
+}
+
+// write atomically replaces the snapshot file with the output of dump.
+func (s *snapshotter) write(dump func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := dump(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *snapshotter) stop() {
+	if s.janitor != nil {
+		s.janitor.stop()
+	}
+}
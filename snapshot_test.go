@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	clock := cachetest.NewClock(time.Now())
+	c := New[string](NoExpiration, 0, WithClock(clock), WithSnapshot(path, time.Minute))
+	c.Set("foo", "bar")
+
+	// the snapshot goroutine may not be waiting on the clock yet, and it only writes
+	// when the clock is advanced, so that no write is left running when the test ends.
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Minute)
+		time.Sleep(time.Millisecond * 10)
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+	}
+	restored := New[string](NoExpiration, 0, WithSnapshot(path, 0))
+	if v, _ := restored.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to be restored from the periodic snapshot, got %v", v)
+	}
+
+	restored.Set("baz", "qux")
+	if err := restored.SaveSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	again := New[string](NoExpiration, 0, WithSnapshot(path, 0))
+	if v, _ := again.Get("baz"); v != "qux" {
+		t.Errorf("expected baz to be restored, got %v", v)
+	}
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) > 0 {
+		t.Errorf("expected no temporary file to be left, got %v", matches)
+	}
+
+	if err := New[string](NoExpiration, 0).SaveSnapshot(); err == nil {
+		t.Error("expected SaveSnapshot to fail without WithSnapshot")
+	}
+}
@@ -0,0 +1,92 @@
+// Package sqlcache caches the results of read queries, tagged with the tables they read
+// so that the statements writing a table invalidate them.
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// Cached returns the result cached under the key, or runs query and caches its result for ttl,
+// tagged with the tables it reads. T is typically the scanned rows, such as []User.
+// Errors are returned without being cached.
+//
+// Unlike GetOrLoadCtx, concurrent misses each run the query, which keeps the tags of the result.
+func Cached[T any](ctx context.Context, c *cache.GenericCache[T], key string, ttl time.Duration, query func(context.Context) (T, error), tables ...string) (T, error) {
+	v, ok, err := c.GetCtx(ctx, key)
+	if err != nil || ok {
+		return v, err
+	}
+	v, err = query(ctx)
+	if err != nil {
+		return v, err
+	}
+	c.SetWithTags(key, v, ttl, tables...)
+	return v, nil
+}
+
+// Tagged is a cache whose items can be removed by tag, such as a cache.GenericCache.
+type Tagged interface {
+	InvalidateTag(tag string) int
+}
+
+// Execer executes statements, it is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Invalidator removes the results reading the tables written by statements from several caches.
+// The zero value is ready to use, and it is safe for concurrent use.
+type Invalidator struct {
+	// mu protects caches and hooks.
+	mu     sync.RWMutex
+	caches []Tagged
+	hooks  []func(table string)
+}
+
+// Register adds c to the caches invalidated by Invalidate.
+func (inv *Invalidator) Register(c Tagged) {
+	inv.mu.Lock()
+	inv.caches = append(inv.caches, c)
+	inv.mu.Unlock()
+}
+
+// OnInvalidate adds a function called with every table invalidated, after the caches,
+// to tell other processes for instance.
+func (inv *Invalidator) OnInvalidate(hook func(table string)) {
+	inv.mu.Lock()
+	inv.hooks = append(inv.hooks, hook)
+	inv.mu.Unlock()
+}
+
+// Invalidate removes the results reading the tables from the registered caches,
+// and calls the OnInvalidate hooks.
+func (inv *Invalidator) Invalidate(tables ...string) {
+	inv.mu.RLock()
+	caches, hooks := inv.caches, inv.hooks
+	inv.mu.RUnlock()
+	for _, table := range tables {
+		for _, c := range caches {
+			c.InvalidateTag(table)
+		}
+		for _, hook := range hooks {
+			hook(table)
+		}
+	}
+}
+
+// ExecContext executes the statement writing the tables, and invalidates them if it succeeds.
+// Within a transaction, the results read by other connections may be cached again before
+// it is committed, so call Invalidate after Commit instead.
+func (inv *Invalidator) ExecContext(ctx context.Context, db Execer, tables []string, query string, args ...any) (sql.Result, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	inv.Invalidate(tables...)
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+// execer records the statements it executes.
+type execer struct {
+	statements []string
+}
+
+func (e *execer) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	if query == "" {
+		return nil, errors.New("empty statement")
+	}
+	e.statements = append(e.statements, query)
+	return nil, nil
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	users := cache.New[[]user](cache.NoExpiration, 0)
+	queries := 0
+	query := func(context.Context) ([]user, error) {
+		queries++
+		return []user{{1, "foo"}}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := Cached(ctx, users, "users:all", time.Minute, query, "users"); err != nil || len(v) != 1 || v[0].Name != "foo" {
+			t.Errorf("expected the users, got %v, %v", v, err)
+		}
+	}
+	if queries != 1 {
+		t.Errorf("expected the query to run once, got %d", queries)
+	}
+	if _, err := Cached(ctx, users, "users:none", time.Minute, func(context.Context) ([]user, error) {
+		return nil, sql.ErrNoRows
+	}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the query error, got %v", err)
+	}
+
+	var inv Invalidator
+	inv.Register(users)
+	var invalidated []string
+	inv.OnInvalidate(func(table string) { invalidated = append(invalidated, table) })
+	db := &execer{}
+	if _, err := inv.ExecContext(ctx, db, []string{"orders"}, "UPDATE orders SET paid = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := users.Get("users:all"); !ok {
+		t.Error("expected the users to be kept when orders are written")
+	}
+	if _, err := inv.ExecContext(ctx, db, []string{"users"}, ""); err == nil {
+		t.Error("expected the statement error")
+	}
+	if _, ok := users.Get("users:all"); !ok {
+		t.Error("expected the users to be kept when the statement fails")
+	}
+	inv.ExecContext(ctx, db, []string{"users"}, "DELETE FROM users WHERE id = ?", 1)
+	if _, ok := users.Get("users:all"); ok {
+		t.Error("expected the users to be invalidated")
+	}
+	if len(invalidated) != 2 || invalidated[0] != "orders" || invalidated[1] != "users" {
+		t.Errorf("expected the hooks to be called with orders and users, got %v", invalidated)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cache counters.
+type Stats struct {
+	// Hits is the number of reads that found an item.
+	Hits uint64
+	// Misses is the number of reads that found no item.
+	Misses uint64
+	// Sets is the number of items written.
+	Sets uint64
+	// Deletes is the number of items removed by Delete.
+	Deletes uint64
+	// Evictions is the number of items evicted to make room for others.
+	Evictions uint64
+	// Expired is the number of expired items removed.
+	Expired uint64
+}
+
+// HitRatio returns the share of reads that found an item, or 0 if there were no reads.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// counters are updated atomically so that reading them never blocks the cache.
+type counters struct {
+	hits      uint64
+	misses    uint64
+	sets      uint64
+	deletes   uint64
+	evictions uint64
+	expired   uint64
+}
+
+func (c *counters) hit(ok bool) {
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+func (c *counters) evicted(reason EvictionReason) {
+	switch reason {
+	case EvictionReasonDeleted:
+		atomic.AddUint64(&c.deletes, 1)
+	case EvictionReasonCapacity:
+		atomic.AddUint64(&c.evictions, 1)
+	case EvictionReasonExpired:
+		atomic.AddUint64(&c.expired, 1)
+	}
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Sets:      atomic.LoadUint64(&c.sets),
+		Deletes:   atomic.LoadUint64(&c.deletes),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Expired:   atomic.LoadUint64(&c.expired),
+	}
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *cache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestStats(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	c := New[int](NoExpiration, 0, WithMaxEntries(2), WithClock(clock))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("c")
+	c.Set("c", 3)
+	c.Delete("c")
+	c.SetWithExpireIn("d", 4, time.Millisecond)
+	clock.Advance(time.Millisecond * 2)
+	c.DeleteExpired()
+	expected := Stats{Hits: 1, Misses: 1, Sets: 4, Deletes: 1, Evictions: 1, Expired: 1}
+	if stats := c.Stats(); stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+	if ratio := c.Stats().HitRatio(); ratio != 0.5 {
+		t.Errorf("expected hit ratio 0.5, got %v", ratio)
+	}
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the system of record behind a cache, such as a database.
+// Implementations must be safe for concurrent use.
+type Store[T any] interface {
+	// Load returns the value associated with the key, or an error matching ErrNotFound if there is none.
+	Load(ctx context.Context, key string) (T, error)
+	// Save stores the value for the key.
+	Save(ctx context.Context, key string, value T) error
+	// Delete removes the value associated with the key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// WriteThrough is a cache whose writes are saved to a Store before they are applied to the cache.
+type WriteThrough[T any] struct {
+	cache *GenericCache[T]
+	store Store[T]
+}
+
+// NewWriteThrough returns a WriteThrough saving the writes to cache in store.
+func NewWriteThrough[T any](cache *GenericCache[T], store Store[T]) *WriteThrough[T] {
+	return &WriteThrough[T]{cache: cache, store: store}
+}
+
+// Get returns the value of the item associated with the key from the cache.
+func (w *WriteThrough[T]) Get(key string) (T, bool) {
+	return w.cache.Get(key)
+}
+
+// Set saves the value in the store, then adds it to the cache with the default expiration.
+// The cache is left untouched if the store fails.
+func (w *WriteThrough[T]) Set(ctx context.Context, key string, value T) error {
+	return w.SetWithExpireIn(ctx, key, value, DefaultExpiration)
+}
+
+// SetWithExpireIn is like Set, but adds the value to the cache with the given expiration.
+func (w *WriteThrough[T]) SetWithExpireIn(ctx context.Context, key string, value T, expireIn time.Duration) error {
+	if err := w.store.Save(ctx, key, value); err != nil {
+		return err
+	}
+	w.cache.SetWithExpireIn(key, value, expireIn)
+	return nil
+}
+
+// Delete removes the value from the store, then from the cache.
+func (w *WriteThrough[T]) Delete(ctx context.Context, key string) error {
+	if err := w.store.Delete(ctx, key); err != nil {
+		return err
+	}
+	w.cache.Delete(key)
+	return nil
+}
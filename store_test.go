@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapStore is a Store keeping its values in a map, failing the first fails saves.
+type mapStore struct {
+	mu     sync.Mutex
+	values map[string]string
+	saves  int
+	fails  int
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{values: make(map[string]string)}
+}
+
+func (s *mapStore) Load(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *mapStore) Save(_ context.Context, key string, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("database unavailable")
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *mapStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func (s *mapStore) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func TestWriteThrough(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	w := NewWriteThrough[string](New[string](time.Minute, 0), store)
+
+	if err := w.Set(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := store.get("foo"); v != "bar" {
+		t.Errorf("expected foo to be saved, got %v", v)
+	}
+	if v, _ := w.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to be cached, got %v", v)
+	}
+
+	store.fails = 1
+	if err := w.Set(ctx, "baz", "qux"); err == nil {
+		t.Error("expected the store error")
+	}
+	if _, ok := w.Get("baz"); ok {
+		t.Error("expected baz not to be cached when it could not be saved")
+	}
+
+	if err := w.Delete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.get("foo"); ok {
+		t.Error("expected foo to be deleted from the store")
+	}
+}
+
+func TestWriteBehind(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	w := NewWriteBehind[string](New[string](time.Minute, 0), store, WithFlushInterval(time.Hour), WithBatchSize(10))
+
+	for _, v := range []string{"bar", "baz", "qux"} {
+		if err := w.Set(ctx, "foo", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, _ := w.Get("foo"); v != "qux" {
+		t.Errorf("expected foo to be cached right away, got %v", v)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := store.get("foo"); v != "qux" || store.saves != 1 {
+		t.Errorf("expected the writes to foo to be coalesced, got %v after %d saves", v, store.saves)
+	}
+
+	w.Delete(ctx, "foo")
+	w.Set(ctx, "bar", "baz")
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.get("foo"); ok {
+		t.Error("expected foo to be deleted on close")
+	}
+	if v, _ := store.get("bar"); v != "baz" {
+		t.Errorf("expected bar to be saved on close, got %v", v)
+	}
+	if err := w.Set(ctx, "bar", "qux"); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("expected ErrWriterClosed, got %v", err)
+	}
+}
+
+func TestWriteBehindRetry(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	w := NewWriteBehind[string](New[string](time.Minute, 0), store, WithRetry(3, time.Millisecond))
+	var failed []string
+	w.OnError(func(key string, err error) {
+		failed = append(failed, key)
+	})
+
+	store.fails = 2
+	w.Set(ctx, "foo", "bar")
+	w.Flush(ctx)
+	if v, _ := store.get("foo"); v != "bar" || store.saves != 3 {
+		t.Errorf("expected foo to be saved on the third attempt, got %v after %d saves", v, store.saves)
+	}
+
+	store.fails = 3
+	w.Set(ctx, "baz", "qux")
+	w.Close(ctx)
+	if len(failed) != 1 || failed[0] != "baz" {
+		t.Errorf("expected baz to be reported after 3 failed attempts, got %v", failed)
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	store := newMapStore()
+	store.values["foo"] = "bar"
+	store.values["session"] = "baz"
+	c := New[string](time.Minute, 0)
+	r := NewReadThrough[string](c, store, WithTTLFunc(func(key string, _ string) time.Duration {
+		if key == "session" {
+			return time.Second * 10
+		}
+		return DefaultExpiration
+	}))
+
+	if v, ok, err := r.Get(ctx, "foo"); err != nil || !ok || v != "bar" {
+		t.Errorf("expected foo to be loaded, got %v, %v, %v", v, ok, err)
+	}
+	delete(store.values, "foo")
+	if v, ok, _ := r.Get(ctx, "foo"); !ok || v != "bar" {
+		t.Errorf("expected foo to be cached, got %v, %v", v, ok)
+	}
+	if _, ok, err := r.Get(ctx, "baz"); err != nil || ok {
+		t.Errorf("expected baz to be missing, got %v, %v", ok, err)
+	}
+
+	r.Get(ctx, "session")
+	if ttl, _ := c.TTL("session"); ttl > time.Second*10 {
+		t.Errorf("expected the ttl of session to come from the ttl function, got %v", ttl)
+	}
+	if ttl, _ := c.TTL("foo"); ttl <= time.Second*10 {
+		t.Errorf("expected foo to have the default expiration, got %v", ttl)
+	}
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SetWithTags adds an item to the cache like SetWithExpireIn, and attaches the given tags to it.
+// All items carrying a tag can be removed at once with InvalidateTag.
+// Tags are detached when the item is replaced or removed.
+func (c *cache[K, V]) SetWithTags(key K, value V, expireIn time.Duration, tags ...string) {
+	key = c.normalize(key)
+	expiration := c.expirationOf(value, expireIn)
+	s := c.shard(key)
+	s.mu.Lock()
+	evicted := s.set(key, value, expiration, nil)
+	// the item may have been evicted right away if the shard is bounded to zero entries.
+	if e, ok := s.items[key]; ok {
+		s.tag(key, e, tags)
+	}
+	s.mu.Unlock()
+	atomic.AddUint64(&c.stats.sets, 1)
+	c.report(evicted)
+	c.changed(key)
+}
+
+// InvalidateTag removes all items carrying tag and returns how many were removed.
+func (c *cache[K, V]) InvalidateTag(tag string) int {
+	var evicted []eviction[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key := range s.tags[tag] {
+			evicted = s.evict(key, s.items[key], EvictionReasonDeleted, evicted)
+		}
+		s.mu.Unlock()
+	}
+	c.report(evicted)
+	c.changedEvicted(evicted)
+	return len(evicted)
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	c.SetWithTags("a", 1, DefaultExpiration, "tenant:1", "users")
+	c.SetWithTags("b", 2, DefaultExpiration, "tenant:1")
+	c.SetWithTags("c", 3, DefaultExpiration, "tenant:2", "users")
+	// replacing an item drops its tags.
+	c.Set("b", 20)
+	if n := c.InvalidateTag("tenant:1"); n != 1 {
+		t.Errorf("expected 1 item to be invalidated, got %d", n)
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Errorf("expected b to be kept")
+	}
+	if n := c.InvalidateTag("users"); n != 1 {
+		t.Errorf("expected 1 item to be invalidated, got %d", n)
+	}
+	if n := c.InvalidateTag("users"); n != 0 {
+		t.Errorf("expected no item to be invalidated, got %d", n)
+	}
+}
+
+func TestInvalidateTagConcurrent(t *testing.T) {
+	c := NewSharded[int](4, NoExpiration, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.SetWithTags(string(rune('a'+j%26)), j, DefaultExpiration, "tag")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.InvalidateTag("tag")
+			}
+		}()
+	}
+	wg.Wait()
+	c.InvalidateTag("tag")
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected all items to be invalidated, got %d", n)
+	}
+}
@@ -0,0 +1,90 @@
+// Package tiered combines an in-process cache with a RemoteStore shared by several processes.
+package tiered
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+// Option configures a Cache.
+type Option func(*options)
+
+type options struct {
+	expiration time.Duration
+}
+
+// WithRemoteExpiration sets the time to live of the values stored in the remote store
+// by Set. It defaults to NoExpiration.
+func WithRemoteExpiration(d time.Duration) Option {
+	return func(o *options) {
+		o.expiration = d
+	}
+}
+
+// Cache is a two-tier cache. Reads are served by the in-process L1 cache if possible, and
+// fall through to the remote L2 store otherwise, storing the values found there in L1.
+// Writes go to L2 first, then to L1.
+type Cache[T any] struct {
+	l1         *cache.GenericCache[T]
+	l2         cache.RemoteStore[T]
+	expiration time.Duration
+}
+
+// NewTiered returns a Cache reading from l1 first and from l2 on misses.
+// Values read from l2 are stored in l1 with its default expiration.
+func NewTiered[T any](l1 *cache.GenericCache[T], l2 cache.RemoteStore[T], opts ...Option) *Cache[T] {
+	o := options{expiration: cache.NoExpiration}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache[T]{l1: l1, l2: l2, expiration: o.expiration}
+}
+
+// Get returns the value associated with the key, looking it up in L2 on L1 misses.
+// Concurrent misses for the same key share a single L2 lookup.
+func (c *Cache[T]) Get(ctx context.Context, key string) (result T, exists bool, err error) {
+	result, err = c.l1.GetOrLoadCtx(ctx, key, func(ctx context.Context) (T, error) {
+		v, ok, err := c.l2.Get(ctx, key)
+		if err == nil && !ok {
+			err = cache.ErrNotFound
+		}
+		return v, err
+	})
+	if errors.Is(err, cache.ErrNotFound) {
+		return result, false, nil
+	}
+	return result, err == nil, err
+}
+
+// Set stores the value in both tiers, using the WithRemoteExpiration time to live in L2
+// and the default expiration of L1.
+func (c *Cache[T]) Set(ctx context.Context, key string, value T) error {
+	return c.set(ctx, key, value, c.expiration, cache.DefaultExpiration)
+}
+
+// SetWithExpireIn stores the value in both tiers with the given expiration.
+func (c *Cache[T]) SetWithExpireIn(ctx context.Context, key string, value T, expireIn time.Duration) error {
+	return c.set(ctx, key, value, expireIn, expireIn)
+}
+
+func (c *Cache[T]) set(ctx context.Context, key string, value T, remote, local time.Duration) error {
+	// L1 is left untouched if L2 could not be written, so that it does not get ahead of it.
+	if err := c.l2.Set(ctx, key, value, remote); err != nil {
+		return err
+	}
+	c.l1.SetWithExpireIn(key, value, local)
+	return nil
+}
+
+// Delete removes the value associated with the key from both tiers.
+// Other processes keep their L1 copy until it expires.
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	if err := c.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+	c.l1.Delete(key)
+	return nil
+}
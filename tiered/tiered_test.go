@@ -0,0 +1,106 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+type remoteStore struct {
+	mu    sync.Mutex
+	items map[string]string
+	ttls  map[string]time.Duration
+	gets  int
+	err   error
+}
+
+func newRemoteStore() *remoteStore {
+	return &remoteStore{items: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+func (s *remoteStore) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	v, ok := s.items[key]
+	return v, ok, s.err
+}
+
+func (s *remoteStore) Set(_ context.Context, key string, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.items[key], s.ttls[key] = value, ttl
+	return nil
+}
+
+func (s *remoteStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return s.err
+}
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+	l1 := cache.New[string](time.Minute, 0)
+	l2 := newRemoteStore()
+	c := NewTiered[string](l1, l2, WithRemoteExpiration(time.Hour))
+
+	l2.items["foo"] = "bar"
+	for i := 0; i < 2; i++ {
+		if v, ok, err := c.Get(ctx, "foo"); err != nil || !ok || v != "bar" {
+			t.Errorf("expected foo to be bar, got %v, %v, %v", v, ok, err)
+		}
+	}
+	if l2.gets != 1 {
+		t.Errorf("expected foo to be read from L2 once, got %d", l2.gets)
+	}
+	if _, ok, err := c.Get(ctx, "baz"); err != nil || ok {
+		t.Errorf("expected baz to be missing, got %v, %v", ok, err)
+	}
+
+	if err := c.Set(ctx, "qux", "quux"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := l1.Get("qux"); v != "quux" {
+		t.Errorf("expected qux to be stored in L1, got %v", v)
+	}
+	if l2.items["qux"] != "quux" || l2.ttls["qux"] != time.Hour {
+		t.Errorf("expected qux to be stored in L2 for an hour, got %v for %v", l2.items["qux"], l2.ttls["qux"])
+	}
+
+	if err := c.Delete(ctx, "qux"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l1.Get("qux"); ok {
+		t.Error("expected qux to be deleted from L1")
+	}
+	if _, ok := l2.items["qux"]; ok {
+		t.Error("expected qux to be deleted from L2")
+	}
+}
+
+func TestTieredRemoteError(t *testing.T) {
+	ctx := context.Background()
+	l1 := cache.New[string](time.Minute, 0)
+	l2 := newRemoteStore()
+	l2.err = errors.New("connection refused")
+	c := NewTiered[string](l1, l2)
+
+	if _, _, err := c.Get(ctx, "foo"); !errors.Is(err, l2.err) {
+		t.Errorf("expected the L2 error, got %v", err)
+	}
+	if err := c.Set(ctx, "foo", "bar"); !errors.Is(err, l2.err) {
+		t.Errorf("expected the L2 error, got %v", err)
+	}
+	if _, ok := l1.Get("foo"); ok {
+		t.Error("expected foo not to be stored in L1 when L2 failed")
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+// WithKeyTransform sets a function applied to the keys passed to the cache, to normalize them in
+// one place, by lowercasing them or hashing long ones for instance. The items are stored under
+// the transformed keys, which are the ones returned by Keys, Items and Range and passed to the
+// eviction callbacks and the watchers, and the prefixes of DeletePrefix, Watch and the namespaces
+// are matched against. The cache must be keyed by strings, or the constructor panics.
+func WithKeyTransform(transform func(key string) string) Option {
+	return func(o *options) {
+		o.keyTransform = transform
+	}
+}
+
+// normalize returns the key the item associated with key is stored under.
+// It is applied once by the methods receiving the keys of the callers.
+func (c *cache[K, V]) normalize(key K) K {
+	if c.transform == nil {
+		return key
+	}
+	return c.transform(key)
+}
+
+// normalizeAll returns the keys the items associated with keys are stored under, keys itself
+// without WithKeyTransform.
+func (c *cache[K, V]) normalizeAll(keys []K) []K {
+	if c.transform == nil {
+		return keys
+	}
+	normalized := make([]K, len(keys))
+	for i, key := range keys {
+		normalized[i] = c.transform(key)
+	}
+	return normalized
+}
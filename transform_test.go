@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestKeyTransform(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithKeyTransform(strings.ToLower))
+	c.Set("Foo", 1)
+	if v, ok := c.Get("FOO"); !ok || v != 1 {
+		t.Errorf("expected FOO to be 1, got %v", v)
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "foo" {
+		t.Errorf("expected the key to be stored as foo, got %v", keys)
+	}
+	if c.Add("fOO", 2) {
+		t.Error("expected Add to find the transformed key")
+	}
+	c.SetMany(map[string]int{"BAR": 2, "Baz": 3}, DefaultExpiration)
+	if got := c.GetMany([]string{"bar", "BAZ", "qux"}); len(got) != 2 || got["bar"] != 2 || got["BAZ"] != 3 {
+		t.Errorf("expected the values under the keys as passed, got %v", got)
+	}
+	got, err := c.GetOrLoadMany([]string{"BAR", "Qux"}, func(missing []string) (map[string]int, error) {
+		if len(missing) != 1 || missing[0] != "Qux" {
+			t.Errorf("expected the loader to get the keys as passed, got %v", missing)
+		}
+		return map[string]int{"Qux": 4}, nil
+	})
+	if err != nil || got["BAR"] != 2 || got["Qux"] != 4 {
+		t.Errorf("expected BAR and Qux to be 2 and 4, got %v, %v", got, err)
+	}
+	if v, _ := c.Get("qux"); v != 4 {
+		t.Errorf("expected the loaded value under the transformed key, got %v", v)
+	}
+	c.DeleteMany([]string{"BAR", "QUX"})
+	c.Delete("FOO")
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "baz" {
+		t.Errorf("expected only baz to be left, got %v", keys)
+	}
+}
+
+func TestKeyTransformAppliedOnce(t *testing.T) {
+	hash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	c := NewNumericCache[int](NoExpiration, 0, WithKeyTransform(hash))
+	c.Set("foo", 1)
+	c.Increment("foo", 1)
+	if v, err := c.GetOrLoad("foo", func() (int, error) { return 0, nil }); err != nil || v != 2 {
+		t.Errorf("expected foo to be 2, got %v, %v", v, err)
+	}
+	if v, err := c.GetE("foo"); err != nil || v != 2 {
+		t.Errorf("expected foo to be 2, got %v, %v", v, err)
+	}
+	if _, ok, _ := c.GetCtx(context.Background(), "foo"); !ok {
+		t.Error("expected GetCtx to find foo")
+	}
+	if _, ok := c.TTL("foo"); !ok {
+		t.Error("expected TTL to find foo")
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != hash("foo") {
+		t.Errorf("expected the key to be hashed once, got %v", keys)
+	}
+}
+
+func TestKeyTransformNotString(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a cache not keyed by strings")
+		}
+	}()
+	NewKeyed[int, int](NoExpiration, 0, WithKeyTransform(strings.ToLower))
+}
+
+func TestKeyTransformNamespace(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithKeyTransform(strings.ToLower))
+	users := c.Namespace("Users")
+	users.Set("Alice", 1)
+	if keys := users.Keys(); len(keys) != 1 || keys[0] != "alice" {
+		t.Errorf("expected the transformed key of the namespace, got %v", keys)
+	}
+	users.Flush()
+	if n := c.ItemCount(); n != 0 {
+		t.Errorf("expected the namespace to be flushed, got %d items", n)
+	}
+}
@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WithLog appends every write to the cache to the file at path, and replays the file when
+// the cache is created, on top of the WithSnapshot file if any. This keeps the writes made
+// between two snapshots, at the cost of a file write for every write to the cache. The log is
+// emptied whenever a snapshot is written, without WithSnapshot it grows until CompactLog is called.
+// The expiration set by Touch, the tags and the pins of the items are logged with them.
+// Values stored by GetOrLoad and items removed because they expired or to make room are not
+// logged, nor anything once the cache is closed. The constructor panics if the file cannot be opened.
+func WithLog(path string) Option {
+	return func(o *options) {
+		o.logPath = path
+	}
+}
+
+// walOp is the kind of a log record.
+type walOp uint8
+
+const (
+	walSet walOp = iota + 1
+	walDelete
+	walFlush
+)
+
+// walRecord is a log record. Records are written as their uvarint length
+// followed by their gob encoding.
+type walRecord[K comparable, V any] struct {
+	Op         walOp
+	Key        K
+	Value      V
+	Expiration int64
+	Tags       []string
+	Pinned     bool
+}
+
+// wal is the append-only log of a cache.
+type wal[K comparable, V any] struct {
+	// mu serializes the records, it is held while reading the state they record.
+	mu sync.Mutex
+	// f is nil once the log is closed.
+	f     *os.File
+	codec GobCodec[walRecord[K, V]]
+	buf   []byte
+}
+
+// openLog replays the log at path into c and opens it for appending.
+func (c *cache[K, V]) openLog(path string) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		panic(fmt.Sprintf("cache: WithLog: %v", err))
+	}
+	w := &wal[K, V]{f: f}
+	// a record torn by a crash ends the log, the file is truncated after the last complete one.
+	end := c.replay(bufio.NewReader(f), w.codec)
+	if err := f.Truncate(end); err != nil {
+		panic(fmt.Sprintf("cache: WithLog: %v", err))
+	}
+	if _, err := f.Seek(end, io.SeekStart); err != nil {
+		panic(fmt.Sprintf("cache: WithLog: %v", err))
+	}
+	c.wal = w
+}
+
+// replay applies the records read from r to c and returns the offset of the end of the last complete one.
+func (c *cache[K, V]) replay(r *bufio.Reader, codec GobCodec[walRecord[K, V]]) (end int64) {
+	now := c.now()
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return end
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return end
+		}
+		record, err := codec.Decode(data)
+		if err != nil {
+			return end
+		}
+		end += int64(uvarintLen(n)) + int64(n)
+		switch record.Op {
+		case walSet:
+			s := c.shard(record.Key)
+			if record.Expiration > 0 && now >= record.Expiration {
+				s.mu.Lock()
+				s.delete(record.Key)
+				s.mu.Unlock()
+				continue
+			}
+			s.mu.Lock()
+			s.set(record.Key, record.Value, record.Expiration, nil)
+			if e, ok := s.items[record.Key]; ok {
+				s.tag(record.Key, e, record.Tags)
+				if record.Pinned {
+					s.pin(record.Key, e)
+				}
+			}
+			s.mu.Unlock()
+		case walDelete:
+			s := c.shard(record.Key)
+			s.mu.Lock()
+			s.delete(record.Key)
+			s.mu.Unlock()
+		case walFlush:
+			for _, s := range c.shards {
+				s.flush(nil, false, false)
+			}
+		}
+	}
+}
+
+func uvarintLen(x uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], x)
+}
+
+// CompactLog empties the WithLog log, saving a snapshot first if the cache has WithSnapshot.
+// Without WithSnapshot, the writes logged so far are lost on restart.
+func (c *cache[K, V]) CompactLog() error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	if c.wal == nil {
+		return nil
+	}
+	if c.snapshots != nil {
+		return c.SaveSnapshot()
+	}
+	return c.wal.compact(func() error { return nil })
+}
+
+// log appends the current state of the keys of c to the log.
+func (w *wal[K, V]) log(c *cache[K, V], keys []K) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return
+	}
+	now := c.now()
+	for _, key := range keys {
+		record := walRecord[K, V]{Op: walDelete, Key: key}
+		s := c.shard(key)
+		s.mu.RLock()
+		if e, ok := s.items[key]; ok && !s.expired(e, now) {
+			record = walRecord[K, V]{Op: walSet, Key: key, Value: e.value, Expiration: e.expiration, Tags: e.tags, Pinned: e.pinned}
+		}
+		s.mu.RUnlock()
+		if err := w.append(record); err != nil {
+			c.persistError(err)
+		}
+	}
+}
+
+// logItem appends the state of the item associated with the key to the log, if c has one,
+// after a change which is not a write, like the one of its expiration or of its pin.
+func (c *cache[K, V]) logItem(key K) {
+	if c.wal != nil {
+		c.wal.log(c, []K{key})
+	}
+}
+
+// logFlush calls flush and appends a flush record to the log.
+func (w *wal[K, V]) logFlush(c *cache[K, V], flush func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	flush()
+	if w.f == nil {
+		return
+	}
+	if err := w.append(walRecord[K, V]{Op: walFlush}); err != nil {
+		c.persistError(err)
+	}
+}
+
+// append writes record to the log. w.mu must be held.
+func (w *wal[K, V]) append(record walRecord[K, V]) error {
+	data, err := w.codec.Encode(record)
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf[:0], size[:binary.PutUvarint(size[:], uint64(len(data)))]...)
+	w.buf = append(w.buf, data...)
+	_, err = w.f.Write(w.buf)
+	return err
+}
+
+// compact empties the log once save succeeded, without logging writes in the meantime.
+func (w *wal[K, V]) compact(save func() error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := save(); err != nil {
+		return err
+	}
+	if w.f == nil {
+		return nil
+	}
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := w.f.Seek(0, io.SeekStart)
+	return err
+}
+
+// close closes the log, which is no longer written to afterwards.
+func (w *wal[K, V]) close() {
+	w.mu.Lock()
+	w.f.Close()
+	w.f = nil
+	w.mu.Unlock()
+}
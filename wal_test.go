@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.log")
+	c := New[string](NoExpiration, 0, WithLog(path))
+	c.Set("foo", "bar")
+	c.Set("baz", "qux")
+	c.SetWithExpireIn("expired", "soon", time.Nanosecond)
+	c.Delete("baz")
+	c.SetMany(map[string]string{"a": "1", "b": "2"}, NoExpiration)
+	c.Update("a", func(string, bool) (string, bool) { return "3", true })
+
+	replayed := New[string](NoExpiration, 0, WithLog(path))
+	if v, _ := replayed.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to be replayed, got %v", v)
+	}
+	if _, ok := replayed.Get("baz"); ok {
+		t.Error("expected baz to be deleted")
+	}
+	if _, ok := replayed.Get("expired"); ok {
+		t.Error("expected expired items not to be replayed")
+	}
+	if v, _ := replayed.Get("a"); v != "3" {
+		t.Errorf("expected the last write to a to win, got %v", v)
+	}
+
+	replayed.Flush()
+	replayed.Set("c", "4")
+	again := New[string](NoExpiration, 0, WithLog(path))
+	if n := again.ItemCount(); n != 1 {
+		t.Errorf("expected only c to be left after the flush, got %d items", n)
+	}
+
+	// a record torn by a crash is dropped.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.Write([]byte{100, 1, 2})
+	f.Close()
+	torn := New[string](NoExpiration, 0, WithLog(path))
+	if v, _ := torn.Get("c"); v != "4" {
+		t.Errorf("expected c to be replayed before the torn record, got %v", v)
+	}
+}
+
+func TestLogCompaction(t *testing.T) {
+	dir := t.TempDir()
+	snapshot, log := filepath.Join(dir, "cache.snapshot"), filepath.Join(dir, "cache.log")
+	c := New[string](NoExpiration, 0, WithSnapshot(snapshot, 0), WithLog(log))
+	c.Set("foo", "bar")
+	if err := c.SaveSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(log); info.Size() != 0 {
+		t.Errorf("expected the log to be emptied by the snapshot, got %d bytes", info.Size())
+	}
+	c.Set("baz", "qux")
+	c.Delete("foo")
+
+	restored := New[string](NoExpiration, 0, WithSnapshot(snapshot, 0), WithLog(log))
+	if _, ok := restored.Get("foo"); ok {
+		t.Error("expected the logged delete to apply on top of the snapshot")
+	}
+	if v, _ := restored.Get("baz"); v != "qux" {
+		t.Errorf("expected baz to be replayed, got %v", v)
+	}
+}
+
+func TestLogItemState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+	clock := cachetest.NewClock(time.Unix(1e9, 0))
+	c := New[string](NoExpiration, 0, WithClock(clock), WithLog(path))
+	c.SetWithExpireIn("foo", "bar", time.Minute)
+	c.Touch("foo", time.Hour)
+	c.SetWithTags("tagged", "1", NoExpiration, "group")
+	c.Set("pinned", "2")
+	c.Pin("pinned")
+	c.Close()
+	info, _ := os.Stat(path)
+	c.Set("closed", "3")
+	if after, _ := os.Stat(path); after.Size() != info.Size() {
+		t.Errorf("expected nothing to be logged once closed, got %d more bytes", after.Size()-info.Size())
+	}
+
+	clock.Advance(time.Minute * 2)
+	replayed := New[string](NoExpiration, 0, WithClock(clock), WithLog(path))
+	if _, ok := replayed.Get("foo"); !ok {
+		t.Error("expected the expiration set by Touch to be replayed")
+	}
+	if n := replayed.InvalidateTag("group"); n != 1 {
+		t.Errorf("expected the tags to be replayed, got %d tagged items", n)
+	}
+	replayed.Flush()
+	if _, ok := replayed.Get("pinned"); !ok {
+		t.Error("expected the pin to be replayed")
+	}
+}
+
+func TestLogLoaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+	c := New[string](NoExpiration, 0, WithLog(path))
+	c.GetOrLoadMany([]string{"a"}, func(missing []string) (map[string]string, error) {
+		return map[string]string{"a": "1"}, nil
+	})
+	c.Warm(context.Background(), []string{"b"}, func(_ context.Context, keys []string) (map[string]string, error) {
+		return map[string]string{"b": "2"}, nil
+	}, 1)
+
+	replayed := New[string](NoExpiration, 0, WithLog(path))
+	if n := replayed.ItemCount(); n != 2 {
+		t.Errorf("expected the values loaded in batches to be replayed, got %d items", n)
+	}
+}
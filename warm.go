@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchLoader returns the values of the keys, leaving out the keys without a value.
+type BatchLoader[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// WarmOption configures Warm.
+type WarmOption func(*warmOptions)
+
+type warmOptions struct {
+	batchSize int
+	progress  func(done, total int)
+}
+
+// WithWarmBatchSize sets how many keys are passed to the loader at once, 100 by default.
+func WithWarmBatchSize(n int) WarmOption {
+	return func(o *warmOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithWarmProgress sets a function called after every batch with the number of keys
+// loaded so far and the number of keys to load. Its calls are not concurrent.
+func WithWarmProgress(progress func(done, total int)) WarmOption {
+	return func(o *warmOptions) {
+		o.progress = progress
+	}
+}
+
+// Warm loads the keys which do not have an item yet in batches, running up to parallelism
+// loaders at once, and stores their values with the default expiration, to fill the cache
+// before serving. It stops at the first loader error, which it returns, or once ctx is done,
+// returning ctx.Err(). The batches loaded until then are kept.
+func (c *cache[K, V]) Warm(ctx context.Context, keys []K, loader BatchLoader[K, V], parallelism int, opts ...WarmOption) error {
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	o := warmOptions{batchSize: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize < 1 {
+		o.batchSize = 1
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	missing := c.missing(keys)
+	batches := make(chan []K)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg sync.WaitGroup
+		// mu protects err, done and the calls to progress.
+		mu   sync.Mutex
+		err  error
+		done int
+	)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				loaded, loadErr := loader(ctx, batch)
+				mu.Lock()
+				if loadErr != nil {
+					if err == nil {
+						err = loadErr
+					}
+					mu.Unlock()
+					cancel()
+					continue
+				}
+				items := make(map[K]V, len(loaded))
+				for _, key := range batch {
+					if v, ok := loaded[key]; ok {
+						items[c.normalize(key)] = v
+					}
+				}
+				c.setMany(items, DefaultExpiration)
+				c.changedMany(items)
+				done += len(batch)
+				if o.progress != nil {
+					o.progress(done, len(missing))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+send:
+	for start := 0; start < len(missing); start += o.batchSize {
+		end := start + o.batchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		select {
+		case batches <- missing[start:end]:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(batches)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	// ctx is only canceled by a loader error before Warm returns.
+	return ctx.Err()
+}
+
+// missing returns the keys without an item, once each, as they were passed.
+func (c *cache[K, V]) missing(keys []K) []K {
+	now := c.now()
+	var missing []K
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		normalized := c.normalize(key)
+		s := c.shard(normalized)
+		s.mu.RLock()
+		e, ok := s.items[normalized]
+		ok = ok && !s.expired(e, now)
+		s.mu.RUnlock()
+		if !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarm(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	c.Set("0", -1)
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	var running, most int32
+	loader := func(ctx context.Context, keys []string) (map[string]int, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		values := make(map[string]int, len(keys))
+		for _, key := range keys {
+			if key != "1" {
+				values[key], _ = strconv.Atoi(key)
+			}
+		}
+		return values, nil
+	}
+	var progress []int
+	err := c.Warm(context.Background(), keys, loader, 4, WithWarmBatchSize(10), WithWarmProgress(func(done, total int) {
+		if total != 99 {
+			t.Errorf("expected 99 keys to load, got %d", total)
+		}
+		progress = append(progress, done)
+	}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(progress) != 10 || progress[9] != 99 {
+		t.Errorf("expected progress to be reported after each batch, got %v", progress)
+	}
+	if n := atomic.LoadInt32(&most); n > 4 {
+		t.Errorf("expected at most 4 loaders at once, got %d", n)
+	}
+	if v, _ := c.Get("0"); v != -1 {
+		t.Errorf("expected the stored items to be kept, got %d", v)
+	}
+	if _, ok := c.Get("1"); ok {
+		t.Error("expected the keys left out by the loader not to be stored")
+	}
+	if v, _ := c.Get("99"); v != 99 {
+		t.Errorf("expected 99 to be loaded, got %d", v)
+	}
+}
+
+func TestWarmError(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	errLoad := errors.New("load failed")
+	var calls int32
+	err := c.Warm(context.Background(), []string{"a", "b", "c"}, func(ctx context.Context, keys []string) (map[string]int, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errLoad
+	}, 1, WithWarmBatchSize(1))
+	if err != errLoad {
+		t.Errorf("expected %v, got %v", errLoad, err)
+	}
+	if n := atomic.LoadInt32(&calls); n > 2 {
+		t.Errorf("expected the loading to stop at the first error, got %d calls", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Warm(ctx, []string{"a"}, func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, nil
+	}, 1); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
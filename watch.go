@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EventType is the kind of change reported by Watch.
+type EventType int
+
+const (
+	// EventSet means the item was stored, by a write or a load.
+	EventSet EventType = iota + 1
+	// EventDelete means the item was removed by Delete, Flush or to make room for another one.
+	EventDelete
+	// EventExpire means the expired item was removed by the janitor or DeleteExpired.
+	EventExpire
+)
+
+// String implements fmt.Stringer.
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change to an item, see Watch.
+type Event[T any] struct {
+	Type EventType
+	Key  string
+	// Value is the value stored by an EventSet, or the value removed otherwise.
+	Value T
+}
+
+// WithWatchBuffer sets how many events a watcher may lag behind before its channel is closed,
+// 64 by default. See Watch.
+func WithWatchBuffer(n int) Option {
+	return func(o *options) {
+		o.watchBuffer = n
+	}
+}
+
+// Watch returns a channel receiving the changes to the items whose keys start with prefix,
+// or to all of them if prefix is empty, until ctx is done or the cache is closed.
+//
+// Events are sent without blocking the writes: the channel is closed as well if the receiver
+// falls behind by more than the WithWatchBuffer size, in which case ctx.Err() is nil and the
+// receiver has missed changes, so it should read the items again before watching anew.
+// Expired items are only reported once they are removed, see EventExpire.
+func (g *GenericCache[T]) Watch(ctx context.Context, prefix string) <-chan Event[T] {
+	return watch(g.cache, ctx, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// watchers dispatches the changes of a cache to its watchers.
+type watchers[K comparable, V any] struct {
+	size int
+	// n is the number of watchers, read atomically to skip the dispatch when there are none.
+	n int32
+	// listening registers the eviction listener and the closer of the watchers, once.
+	listening sync.Once
+	// name returns the Key of the events of key. It is set by watch, which only string keys
+	// can be watched with, before the first watcher is registered.
+	name func(key K) string
+
+	// mu protects list and closed.
+	mu     sync.Mutex
+	list   map[*watcher[V]]func(K) bool
+	closed bool
+}
+
+// watcher is a channel returned by Watch.
+type watcher[V any] struct {
+	events chan Event[V]
+	// done is closed along with events, to stop the goroutine waiting for the context.
+	done chan struct{}
+}
+
+func newWatchers[K comparable, V any](size int) *watchers[K, V] {
+	if size < 1 {
+		size = 64
+	}
+	return &watchers[K, V]{size: size, list: make(map[*watcher[V]]func(K) bool)}
+}
+
+// watch registers a watcher of the keys of c matching match.
+func watch[V any](c *cache[string, V], ctx context.Context, match func(string) bool) <-chan Event[V] {
+	ws := c.watchers
+	ws.listening.Do(func() {
+		ws.name = func(key string) string { return key }
+		c.listen(func(key string, value V, reason EvictionReason) {
+			typ := EventDelete
+			if reason == EvictionReasonExpired {
+				typ = EventExpire
+			}
+			ws.dispatch(typ, key, value)
+		})
+		c.onClose(func() error {
+			ws.closeAll()
+			return nil
+		})
+	})
+	w := &watcher[V]{events: make(chan Event[V], ws.size), done: make(chan struct{})}
+	ws.mu.Lock()
+	if ws.closed || ctx.Err() != nil || c.isClosed() != nil {
+		ws.mu.Unlock()
+		close(w.events)
+		return w.events
+	}
+	ws.list[w] = match
+	atomic.AddInt32(&ws.n, 1)
+	ws.mu.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.mu.Lock()
+			ws.remove(w)
+			ws.mu.Unlock()
+		case <-w.done:
+		}
+	}()
+	return w.events
+}
+
+// stored sends an EventSet with the value stored for the key. The shards call it with the key
+// locked, so that the events of a key are sent in the order of its writes, with their own value.
+func (c *cache[K, V]) stored(key K, value V) {
+	if atomic.LoadInt32(&c.watchers.n) == 0 {
+		return
+	}
+	c.watchers.dispatch(EventSet, key, c.clone(value))
+}
+
+// dispatch sends the event of the given type to the watchers of key, closing the channels which
+// are full.
+func (ws *watchers[K, V]) dispatch(typ EventType, key K, value V) {
+	if atomic.LoadInt32(&ws.n) == 0 {
+		return
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	event := Event[V]{Type: typ, Key: ws.name(key), Value: value}
+	for w, match := range ws.list {
+		if !match(key) {
+			continue
+		}
+		select {
+		case w.events <- event:
+		default:
+			ws.remove(w)
+		}
+	}
+}
+
+// closeAll closes the channels of all the watchers, and of the ones registered afterwards.
+func (ws *watchers[K, V]) closeAll() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.closed = true
+	for w := range ws.list {
+		ws.remove(w)
+	}
+}
+
+// remove closes the channel of w, if it is still registered. ws.mu must be held.
+func (ws *watchers[K, V]) remove(w *watcher[V]) {
+	if _, ok := ws.list[w]; !ok {
+		return
+	}
+	delete(ws.list, w)
+	atomic.AddInt32(&ws.n, -1)
+	close(w.events)
+	close(w.done)
+}
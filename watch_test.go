@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestWatch(t *testing.T) {
+	clock := cachetest.NewClock(time.Unix(0, 0))
+	c := New[string](NoExpiration, 0, WithClock(clock))
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.Watch(ctx, "config:")
+
+	c.Set("config:a", "1")
+	c.Set("other", "2")
+	c.SetWithExpireIn("config:b", "3", time.Second)
+	c.Delete("config:a")
+	clock.Advance(time.Second)
+	c.DeleteExpired()
+	c.GetOrLoad("config:c", func() (string, error) { return "4", nil })
+
+	want := []Event[string]{
+		{EventSet, "config:a", "1"},
+		{EventSet, "config:b", "3"},
+		{EventDelete, "config:a", "1"},
+		{EventExpire, "config:b", "3"},
+		{EventSet, "config:c", "4"},
+	}
+	for _, w := range want {
+		if got := <-events; got != w {
+			t.Errorf("expected %v, got %v", w, got)
+		}
+	}
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed with the context")
+	}
+}
+
+func TestWatchLagging(t *testing.T) {
+	c := New[int](NoExpiration, 0, WithWatchBuffer(2))
+	events := c.Watch(context.Background(), "")
+	for i := 0; i < 3; i++ {
+		c.Set("foo", i)
+	}
+	n := 0
+	for range events {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("expected the channel to be closed after 2 events, got %d", n)
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	c := New[int](NoExpiration, 0)
+	events := c.Watch(context.Background(), "")
+	c.Close()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed with the cache")
+	}
+	if _, ok := <-c.Watch(context.Background(), ""); ok {
+		t.Error("expected the channel of a closed cache to be closed")
+	}
+}
+
+func TestWatchConcurrentSets(t *testing.T) {
+	const writers, writes = 8, 100
+	c := New[int](NoExpiration, 0, WithWatchBuffer(writers*writes))
+	events := c.Watch(context.Background(), "")
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				c.Set("foo", i*writes+j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for i := 0; i < writers*writes; i++ {
+		e := <-events
+		if e.Type != EventSet || seen[e.Value] {
+			t.Fatalf("expected every write to be reported once with its value, got %v again", e)
+		}
+		seen[e.Value] = true
+	}
+	if v, _ := c.Get("foo"); !seen[v] {
+		t.Errorf("expected the last value to be reported, got %d", v)
+	}
+}
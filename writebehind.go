@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrWriterClosed is returned by the writes to a closed WriteBehind.
+var ErrWriterClosed = errors.New("cache: write-behind writer closed")
+
+// WriteBehindOption configures a WriteBehind.
+type WriteBehindOption func(*writeBehindOptions)
+
+type writeBehindOptions struct {
+	queueSize     int
+	batchSize     int
+	flushInterval time.Duration
+	attempts      int
+	backoff       time.Duration
+}
+
+// WithQueueSize bounds the number of writes waiting to be saved, 1024 by default.
+// Writes block while the queue is full.
+func WithQueueSize(n int) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.queueSize = n
+	}
+}
+
+// WithBatchSize sets how many distinct keys are saved at once, 100 by default.
+func WithBatchSize(n int) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithFlushInterval sets how long a write waits at most before it is saved, one second by default.
+func WithFlushInterval(d time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.flushInterval = d
+	}
+}
+
+// WithRetry makes failed saves be attempted up to attempts times, waiting backoff after the first
+// failure and twice as long after every other. Saves are attempted once by default.
+func WithRetry(attempts int, backoff time.Duration) WriteBehindOption {
+	return func(o *writeBehindOptions) {
+		o.attempts = attempts
+		o.backoff = backoff
+	}
+}
+
+// WriteBehind is a cache whose writes are applied to the cache right away, and saved to a Store
+// in the background. Writes to the same key waiting to be saved are coalesced, only the last one
+// is saved. Saves which still fail after the WithRetry attempts are reported to the OnError function.
+type WriteBehind[T any] struct {
+	cache *GenericCache[T]
+	store Store[T]
+	clock Clock
+	o     writeBehindOptions
+
+	writes chan write[T]
+	// mu protects closed, writes are sent holding a read lock so that Close does not race with them.
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	errMu   sync.RWMutex
+	onError func(key string, err error)
+}
+
+// write is a pending save or delete, or a request to flush the pending writes if flushed is set.
+type write[T any] struct {
+	key     string
+	value   T
+	delete  bool
+	flushed chan struct{}
+}
+
+// NewWriteBehind returns a WriteBehind saving the writes to cache in store in the background.
+// Close, or the Close method of cache, must be called to save the pending writes and stop
+// the background goroutine.
+func NewWriteBehind[T any](cache *GenericCache[T], store Store[T], opts ...WriteBehindOption) *WriteBehind[T] {
+	o := writeBehindOptions{queueSize: 1024, batchSize: 100, flushInterval: time.Second, attempts: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	w := &WriteBehind[T]{
+		cache:  cache,
+		store:  store,
+		clock:  cache.clock,
+		o:      o,
+		writes: make(chan write[T], o.queueSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	cache.onClose(func() error {
+		// the writer may have been closed already.
+		return w.Close(context.Background())
+	})
+	return w
+}
+
+// OnError sets the function called with the writes which could not be saved.
+// Set to nil to disable.
+func (w *WriteBehind[T]) OnError(f func(key string, err error)) {
+	w.errMu.Lock()
+	w.onError = f
+	w.errMu.Unlock()
+}
+
+// Get returns the value of the item associated with the key from the cache.
+func (w *WriteBehind[T]) Get(key string) (T, bool) {
+	return w.cache.Get(key)
+}
+
+// Set adds the value to the cache with the default expiration, and queues it to be saved.
+func (w *WriteBehind[T]) Set(ctx context.Context, key string, value T) error {
+	return w.SetWithExpireIn(ctx, key, value, DefaultExpiration)
+}
+
+// SetWithExpireIn is like Set, but adds the value to the cache with the given expiration.
+// If the queue is full, it waits for room until ctx is done.
+func (w *WriteBehind[T]) SetWithExpireIn(ctx context.Context, key string, value T, expireIn time.Duration) error {
+	w.cache.SetWithExpireIn(key, value, expireIn)
+	return w.send(ctx, write[T]{key: key, value: value})
+}
+
+// Delete removes the value from the cache, and queues its removal from the store.
+func (w *WriteBehind[T]) Delete(ctx context.Context, key string) error {
+	w.cache.Delete(key)
+	return w.send(ctx, write[T]{key: key, delete: true})
+}
+
+// Flush saves the pending writes and waits for them to be saved until ctx is done.
+func (w *WriteBehind[T]) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	if err := w.send(ctx, write[T]{flushed: flushed}); err != nil {
+		return err
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close saves the pending writes and stops the background goroutine,
+// waiting for it until ctx is done. Writes after Close return ErrWriterClosed.
+func (w *WriteBehind[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.writes)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *WriteBehind[T]) send(ctx context.Context, wr write[T]) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	select {
+	case w.writes <- wr:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run saves the queued writes in batches until the queue is closed.
+func (w *WriteBehind[T]) run() {
+	defer close(w.done)
+	pending := make(map[string]write[T])
+	var order []string
+	// tick is set while writes are pending.
+	var tick <-chan time.Time
+	flush := func() {
+		for _, key := range order {
+			w.save(pending[key])
+			delete(pending, key)
+		}
+		order, tick = order[:0], nil
+	}
+	for {
+		select {
+		case wr, ok := <-w.writes:
+			if !ok {
+				flush()
+				return
+			}
+			if wr.flushed != nil {
+				flush()
+				close(wr.flushed)
+				continue
+			}
+			if _, ok := pending[wr.key]; !ok {
+				order = append(order, wr.key)
+			}
+			pending[wr.key] = wr
+			if tick == nil {
+				tick = w.clock.After(w.o.flushInterval)
+			}
+			if len(order) >= w.o.batchSize {
+				flush()
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
+
+// save applies wr to the store, retrying as configured.
+func (w *WriteBehind[T]) save(wr write[T]) {
+	ctx := context.Background()
+	backoff := w.o.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if wr.delete {
+			err = w.store.Delete(ctx, wr.key)
+		} else {
+			err = w.store.Save(ctx, wr.key, wr.value)
+		}
+		if err == nil || attempt >= w.o.attempts {
+			break
+		}
+		<-w.clock.After(backoff)
+		backoff *= 2
+	}
+	if err == nil {
+		return
+	}
+	w.errMu.RLock()
+	onError := w.onError
+	w.errMu.RUnlock()
+	if onError != nil {
+		onError(wr.key, err)
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"math"
+	"math/rand"
+)
+
+// WithEarlyExpiration makes GetOrLoad load items again shortly before they expire, so that
+// frequently read items are not loaded by many callers at once when they expire. Every read
+// loads the item early with a probability growing as its expiration approaches, and with
+// the time the previous load took (XFetch). beta scales how early items are loaded,
+// 1 is a good default and greater values favor earlier loads.
+// Items stored without GetOrLoad are never loaded early.
+func WithEarlyExpiration(beta float64) Option {
+	return func(o *options) {
+		o.beta = beta
+	}
+}
+
+// expiresEarly reports whether the item read at now has to be loaded early.
+func (c *cache[K, V]) expiresEarly(e *entry[V], now int64) bool {
+	if c.beta <= 0 || e.delta == 0 || e.expiration == 0 {
+		return false
+	}
+	return float64(now)-float64(e.delta)*c.beta*math.Log(rand.Float64()) >= float64(e.expiration)
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache/cachetest"
+)
+
+func TestEarlyExpiration(t *testing.T) {
+	for _, tt := range []struct {
+		beta  float64
+		early bool
+	}{
+		{beta: 1e9, early: true},
+		{beta: 1e-9, early: false},
+	} {
+		clock := cachetest.NewClock(time.Now())
+		c := New[int](time.Minute, 0, WithClock(clock), WithEarlyExpiration(tt.beta))
+		var calls int
+		loader := func() (int, error) {
+			calls++
+			clock.Advance(time.Second)
+			return calls, nil
+		}
+		c.GetOrLoad("foo", loader)
+		c.Set("bar", 0)
+		clock.Advance(time.Second * 30)
+
+		v, err := c.GetOrLoad("foo", loader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded := v == 2; loaded != tt.early {
+			t.Errorf("beta %v: expected early load to be %v, got value %v", tt.beta, tt.early, v)
+		}
+		c.GetOrLoad("bar", loader)
+		if v, _ := c.Get("bar"); v != 0 {
+			t.Errorf("beta %v: expected bar, stored without GetOrLoad, not to be loaded early, got %v", tt.beta, v)
+		}
+	}
+}
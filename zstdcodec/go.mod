@@ -0,0 +1,10 @@
+module github.com/eatmoreapple/cache/zstdcodec
+
+go 1.25.0
+
+require (
+	github.com/eatmoreapple/cache v0.0.0
+	github.com/klauspost/compress v1.20.1
+)
+
+replace github.com/eatmoreapple/cache => ../
@@ -0,0 +1,39 @@
+// Package zstdcodec implements a cache.Codec compressing with Zstandard.
+package zstdcodec
+
+import (
+	"github.com/eatmoreapple/cache"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The encoder and decoder are safe for concurrent use when only EncodeAll and DecodeAll are called.
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// Codec compresses the encoding of Codec with Zstandard, which is faster than gzip
+// for a similar ratio.
+type Codec[T any] struct {
+	Codec cache.Codec[T]
+}
+
+var _ cache.Codec[any] = Codec[any]{}
+
+// Encode returns the compressed encoding of value.
+func (c Codec[T]) Encode(value T) ([]byte, error) {
+	data, err := c.Codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.EncodeAll(data, nil), nil
+}
+
+// Decode decompresses and parses data.
+func (c Codec[T]) Decode(data []byte) (value T, err error) {
+	data, err = decoder.DecodeAll(data, nil)
+	if err != nil {
+		return value, err
+	}
+	return c.Codec.Decode(data)
+}
@@ -0,0 +1,33 @@
+package zstdcodec
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/eatmoreapple/cache"
+)
+
+func TestCodec(t *testing.T) {
+	codec := Codec[[]cache.DumpItem[string, string]]{Codec: cache.GobCodec[[]cache.DumpItem[string, string]]{}}
+	c := cache.New[string](cache.NoExpiration, 0)
+	c.Set("foo", "bar")
+	c.SetWithExpireIn("baz", "qux", time.Minute)
+	var buf bytes.Buffer
+	if err := c.DumpWith(&buf, codec); err != nil {
+		t.Fatal(err)
+	}
+	loaded := cache.New[string](cache.NoExpiration, 0)
+	if err := loaded.LoadWith(&buf, codec); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := loaded.Get("foo"); v != "bar" {
+		t.Errorf("expected foo to be bar, got %v", v)
+	}
+	if ttl, _ := loaded.TTL("baz"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("expected the expiration of baz to be kept, got %v", ttl)
+	}
+	if _, err := codec.Decode([]byte("not zstd")); err == nil {
+		t.Error("expected invalid data to fail")
+	}
+}